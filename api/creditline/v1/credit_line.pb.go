@@ -0,0 +1,469 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: api/creditline/v1/credit_line.proto
+
+package creditlinev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CreditStatus status of a credit request
+type CreditStatus int32
+
+const (
+	CreditStatus_CREDIT_STATUS_UNSPECIFIED    CreditStatus = 0
+	CreditStatus_CREDIT_STATUS_APPROVED       CreditStatus = 1
+	CreditStatus_CREDIT_STATUS_DECLINED       CreditStatus = 2
+	CreditStatus_CREDIT_STATUS_PENDING_REVIEW CreditStatus = 3
+)
+
+// Enum value maps for CreditStatus.
+var (
+	CreditStatus_name = map[int32]string{
+		0: "CREDIT_STATUS_UNSPECIFIED",
+		1: "CREDIT_STATUS_APPROVED",
+		2: "CREDIT_STATUS_DECLINED",
+		3: "CREDIT_STATUS_PENDING_REVIEW",
+	}
+	CreditStatus_value = map[string]int32{
+		"CREDIT_STATUS_UNSPECIFIED":    0,
+		"CREDIT_STATUS_APPROVED":       1,
+		"CREDIT_STATUS_DECLINED":       2,
+		"CREDIT_STATUS_PENDING_REVIEW": 3,
+	}
+)
+
+func (x CreditStatus) Enum() *CreditStatus {
+	p := new(CreditStatus)
+	*p = x
+	return p
+}
+
+func (x CreditStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CreditStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_creditline_v1_credit_line_proto_enumTypes[0].Descriptor()
+}
+
+func (CreditStatus) Type() protoreflect.EnumType {
+	return &file_api_creditline_v1_credit_line_proto_enumTypes[0]
+}
+
+func (x CreditStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CreditStatus.Descriptor instead.
+func (CreditStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_creditline_v1_credit_line_proto_rawDescGZIP(), []int{0}
+}
+
+// DetermineCreditLimitRequest message that represents the credit line request
+type DetermineCreditLimitRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	FoundingType        string                 `protobuf:"bytes,1,opt,name=founding_type,json=foundingType,proto3" json:"founding_type,omitempty"`
+	CashBalance         float64                `protobuf:"fixed64,2,opt,name=cash_balance,json=cashBalance,proto3" json:"cash_balance,omitempty"`
+	MonthlyRevenue      float64                `protobuf:"fixed64,3,opt,name=monthly_revenue,json=monthlyRevenue,proto3" json:"monthly_revenue,omitempty"`
+	RequestedCreditLine float64                `protobuf:"fixed64,4,opt,name=requested_credit_line,json=requestedCreditLine,proto3" json:"requested_credit_line,omitempty"`
+	RequestedDate       string                 `protobuf:"bytes,5,opt,name=requested_date,json=requestedDate,proto3" json:"requested_date,omitempty"`
+	// contact optional contact data sent to the sales team when the decline retries are exhausted
+	Contact *Contact `protobuf:"bytes,6,opt,name=contact,proto3" json:"contact,omitempty"`
+	// applicant optional business identifiers, the decline retries of an identified applicant are counted by applicant
+	Applicant     *Applicant `protobuf:"bytes,7,opt,name=applicant,proto3" json:"applicant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetermineCreditLimitRequest) Reset() {
+	*x = DetermineCreditLimitRequest{}
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetermineCreditLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetermineCreditLimitRequest) ProtoMessage() {}
+
+func (x *DetermineCreditLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetermineCreditLimitRequest.ProtoReflect.Descriptor instead.
+func (*DetermineCreditLimitRequest) Descriptor() ([]byte, []int) {
+	return file_api_creditline_v1_credit_line_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DetermineCreditLimitRequest) GetFoundingType() string {
+	if x != nil {
+		return x.FoundingType
+	}
+	return ""
+}
+
+func (x *DetermineCreditLimitRequest) GetCashBalance() float64 {
+	if x != nil {
+		return x.CashBalance
+	}
+	return 0
+}
+
+func (x *DetermineCreditLimitRequest) GetMonthlyRevenue() float64 {
+	if x != nil {
+		return x.MonthlyRevenue
+	}
+	return 0
+}
+
+func (x *DetermineCreditLimitRequest) GetRequestedCreditLine() float64 {
+	if x != nil {
+		return x.RequestedCreditLine
+	}
+	return 0
+}
+
+func (x *DetermineCreditLimitRequest) GetRequestedDate() string {
+	if x != nil {
+		return x.RequestedDate
+	}
+	return ""
+}
+
+func (x *DetermineCreditLimitRequest) GetContact() *Contact {
+	if x != nil {
+		return x.Contact
+	}
+	return nil
+}
+
+func (x *DetermineCreditLimitRequest) GetApplicant() *Applicant {
+	if x != nil {
+		return x.Applicant
+	}
+	return nil
+}
+
+// Contact message that represents the contact data of the applicant
+type Contact struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Phone         string                 `protobuf:"bytes,3,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Contact) Reset() {
+	*x = Contact{}
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Contact) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contact) ProtoMessage() {}
+
+func (x *Contact) ProtoReflect() protoreflect.Message {
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contact.ProtoReflect.Descriptor instead.
+func (*Contact) Descriptor() ([]byte, []int) {
+	return file_api_creditline_v1_credit_line_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Contact) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Contact) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Contact) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+// Applicant message that represents the business identifiers of the applicant
+type Applicant struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tax_id RFC of the company or the person
+	TaxId         string `protobuf:"bytes,1,opt,name=tax_id,json=taxId,proto3" json:"tax_id,omitempty"`
+	LegalName     string `protobuf:"bytes,2,opt,name=legal_name,json=legalName,proto3" json:"legal_name,omitempty"`
+	Email         string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Applicant) Reset() {
+	*x = Applicant{}
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Applicant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Applicant) ProtoMessage() {}
+
+func (x *Applicant) ProtoReflect() protoreflect.Message {
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Applicant.ProtoReflect.Descriptor instead.
+func (*Applicant) Descriptor() ([]byte, []int) {
+	return file_api_creditline_v1_credit_line_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Applicant) GetTaxId() string {
+	if x != nil {
+		return x.TaxId
+	}
+	return ""
+}
+
+func (x *Applicant) GetLegalName() string {
+	if x != nil {
+		return x.LegalName
+	}
+	return ""
+}
+
+func (x *Applicant) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+// DetermineCreditLimitResponse message that represents the credit line response
+type DetermineCreditLimitResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	CreditStatus         CreditStatus           `protobuf:"varint,1,opt,name=credit_status,json=creditStatus,proto3,enum=creditline.v1.CreditStatus" json:"credit_status,omitempty"`
+	CreditLineAuthorized string                 `protobuf:"bytes,2,opt,name=credit_line_authorized,json=creditLineAuthorized,proto3" json:"credit_line_authorized,omitempty"`
+	// lead_id identifier of the lead handed off to the sales team, empty when no lead was created
+	LeadId string `protobuf:"bytes,3,opt,name=lead_id,json=leadId,proto3" json:"lead_id,omitempty"`
+	// decision_id identifier of the decision in the audit log
+	DecisionId string `protobuf:"bytes,4,opt,name=decision_id,json=decisionId,proto3" json:"decision_id,omitempty"`
+	// review_id identifier of the manual review of a request pending review, empty when it was not routed to review
+	ReviewId      string `protobuf:"bytes,5,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetermineCreditLimitResponse) Reset() {
+	*x = DetermineCreditLimitResponse{}
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetermineCreditLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetermineCreditLimitResponse) ProtoMessage() {}
+
+func (x *DetermineCreditLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_creditline_v1_credit_line_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetermineCreditLimitResponse.ProtoReflect.Descriptor instead.
+func (*DetermineCreditLimitResponse) Descriptor() ([]byte, []int) {
+	return file_api_creditline_v1_credit_line_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DetermineCreditLimitResponse) GetCreditStatus() CreditStatus {
+	if x != nil {
+		return x.CreditStatus
+	}
+	return CreditStatus_CREDIT_STATUS_UNSPECIFIED
+}
+
+func (x *DetermineCreditLimitResponse) GetCreditLineAuthorized() string {
+	if x != nil {
+		return x.CreditLineAuthorized
+	}
+	return ""
+}
+
+func (x *DetermineCreditLimitResponse) GetLeadId() string {
+	if x != nil {
+		return x.LeadId
+	}
+	return ""
+}
+
+func (x *DetermineCreditLimitResponse) GetDecisionId() string {
+	if x != nil {
+		return x.DecisionId
+	}
+	return ""
+}
+
+func (x *DetermineCreditLimitResponse) GetReviewId() string {
+	if x != nil {
+		return x.ReviewId
+	}
+	return ""
+}
+
+var File_api_creditline_v1_credit_line_proto protoreflect.FileDescriptor
+
+const file_api_creditline_v1_credit_line_proto_rawDesc = "" +
+	"\n" +
+	"#api/creditline/v1/credit_line.proto\x12\rcreditline.v1\"\xd3\x02\n" +
+	"\x1bDetermineCreditLimitRequest\x12#\n" +
+	"\rfounding_type\x18\x01 \x01(\tR\ffoundingType\x12!\n" +
+	"\fcash_balance\x18\x02 \x01(\x01R\vcashBalance\x12'\n" +
+	"\x0fmonthly_revenue\x18\x03 \x01(\x01R\x0emonthlyRevenue\x122\n" +
+	"\x15requested_credit_line\x18\x04 \x01(\x01R\x13requestedCreditLine\x12%\n" +
+	"\x0erequested_date\x18\x05 \x01(\tR\rrequestedDate\x120\n" +
+	"\acontact\x18\x06 \x01(\v2\x16.creditline.v1.ContactR\acontact\x126\n" +
+	"\tapplicant\x18\a \x01(\v2\x18.creditline.v1.ApplicantR\tapplicant\"I\n" +
+	"\aContact\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x03 \x01(\tR\x05phone\"W\n" +
+	"\tApplicant\x12\x15\n" +
+	"\x06tax_id\x18\x01 \x01(\tR\x05taxId\x12\x1d\n" +
+	"\n" +
+	"legal_name\x18\x02 \x01(\tR\tlegalName\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\"\xed\x01\n" +
+	"\x1cDetermineCreditLimitResponse\x12@\n" +
+	"\rcredit_status\x18\x01 \x01(\x0e2\x1b.creditline.v1.CreditStatusR\fcreditStatus\x124\n" +
+	"\x16credit_line_authorized\x18\x02 \x01(\tR\x14creditLineAuthorized\x12\x17\n" +
+	"\alead_id\x18\x03 \x01(\tR\x06leadId\x12\x1f\n" +
+	"\vdecision_id\x18\x04 \x01(\tR\n" +
+	"decisionId\x12\x1b\n" +
+	"\treview_id\x18\x05 \x01(\tR\breviewId*\x87\x01\n" +
+	"\fCreditStatus\x12\x1d\n" +
+	"\x19CREDIT_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16CREDIT_STATUS_APPROVED\x10\x01\x12\x1a\n" +
+	"\x16CREDIT_STATUS_DECLINED\x10\x02\x12 \n" +
+	"\x1cCREDIT_STATUS_PENDING_REVIEW\x10\x032\x84\x01\n" +
+	"\x11CreditLineService\x12o\n" +
+	"\x14DetermineCreditLimit\x12*.creditline.v1.DetermineCreditLimitRequest\x1a+.creditline.v1.DetermineCreditLimitResponseB,Z*credit-line/api/creditline/v1;creditlinev1b\x06proto3"
+
+var (
+	file_api_creditline_v1_credit_line_proto_rawDescOnce sync.Once
+	file_api_creditline_v1_credit_line_proto_rawDescData []byte
+)
+
+func file_api_creditline_v1_credit_line_proto_rawDescGZIP() []byte {
+	file_api_creditline_v1_credit_line_proto_rawDescOnce.Do(func() {
+		file_api_creditline_v1_credit_line_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_creditline_v1_credit_line_proto_rawDesc), len(file_api_creditline_v1_credit_line_proto_rawDesc)))
+	})
+	return file_api_creditline_v1_credit_line_proto_rawDescData
+}
+
+var file_api_creditline_v1_credit_line_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_creditline_v1_credit_line_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_api_creditline_v1_credit_line_proto_goTypes = []any{
+	(CreditStatus)(0),                    // 0: creditline.v1.CreditStatus
+	(*DetermineCreditLimitRequest)(nil),  // 1: creditline.v1.DetermineCreditLimitRequest
+	(*Contact)(nil),                      // 2: creditline.v1.Contact
+	(*Applicant)(nil),                    // 3: creditline.v1.Applicant
+	(*DetermineCreditLimitResponse)(nil), // 4: creditline.v1.DetermineCreditLimitResponse
+}
+var file_api_creditline_v1_credit_line_proto_depIdxs = []int32{
+	2, // 0: creditline.v1.DetermineCreditLimitRequest.contact:type_name -> creditline.v1.Contact
+	3, // 1: creditline.v1.DetermineCreditLimitRequest.applicant:type_name -> creditline.v1.Applicant
+	0, // 2: creditline.v1.DetermineCreditLimitResponse.credit_status:type_name -> creditline.v1.CreditStatus
+	1, // 3: creditline.v1.CreditLineService.DetermineCreditLimit:input_type -> creditline.v1.DetermineCreditLimitRequest
+	4, // 4: creditline.v1.CreditLineService.DetermineCreditLimit:output_type -> creditline.v1.DetermineCreditLimitResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_api_creditline_v1_credit_line_proto_init() }
+func file_api_creditline_v1_credit_line_proto_init() {
+	if File_api_creditline_v1_credit_line_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_creditline_v1_credit_line_proto_rawDesc), len(file_api_creditline_v1_credit_line_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_creditline_v1_credit_line_proto_goTypes,
+		DependencyIndexes: file_api_creditline_v1_credit_line_proto_depIdxs,
+		EnumInfos:         file_api_creditline_v1_credit_line_proto_enumTypes,
+		MessageInfos:      file_api_creditline_v1_credit_line_proto_msgTypes,
+	}.Build()
+	File_api_creditline_v1_credit_line_proto = out.File
+	file_api_creditline_v1_credit_line_proto_goTypes = nil
+	file_api_creditline_v1_credit_line_proto_depIdxs = nil
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/creditline/v1/credit_line.proto
+
+package creditlinev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CreditLineService_DetermineCreditLimit_FullMethodName = "/creditline.v1.CreditLineService/DetermineCreditLimit"
+)
+
+// CreditLineServiceClient is the client API for CreditLineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CreditLineService service to determine the credit line of a founding type
+type CreditLineServiceClient interface {
+	// DetermineCreditLimit calculates the credit line and determines if the requested credit line is approved
+	DetermineCreditLimit(ctx context.Context, in *DetermineCreditLimitRequest, opts ...grpc.CallOption) (*DetermineCreditLimitResponse, error)
+}
+
+type creditLineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCreditLineServiceClient(cc grpc.ClientConnInterface) CreditLineServiceClient {
+	return &creditLineServiceClient{cc}
+}
+
+func (c *creditLineServiceClient) DetermineCreditLimit(ctx context.Context, in *DetermineCreditLimitRequest, opts ...grpc.CallOption) (*DetermineCreditLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DetermineCreditLimitResponse)
+	err := c.cc.Invoke(ctx, CreditLineService_DetermineCreditLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreditLineServiceServer is the server API for CreditLineService service.
+// All implementations must embed UnimplementedCreditLineServiceServer
+// for forward compatibility.
+//
+// CreditLineService service to determine the credit line of a founding type
+type CreditLineServiceServer interface {
+	// DetermineCreditLimit calculates the credit line and determines if the requested credit line is approved
+	DetermineCreditLimit(context.Context, *DetermineCreditLimitRequest) (*DetermineCreditLimitResponse, error)
+	mustEmbedUnimplementedCreditLineServiceServer()
+}
+
+// UnimplementedCreditLineServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCreditLineServiceServer struct{}
+
+func (UnimplementedCreditLineServiceServer) DetermineCreditLimit(context.Context, *DetermineCreditLimitRequest) (*DetermineCreditLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DetermineCreditLimit not implemented")
+}
+func (UnimplementedCreditLineServiceServer) mustEmbedUnimplementedCreditLineServiceServer() {}
+func (UnimplementedCreditLineServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeCreditLineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CreditLineServiceServer will
+// result in compilation errors.
+type UnsafeCreditLineServiceServer interface {
+	mustEmbedUnimplementedCreditLineServiceServer()
+}
+
+func RegisterCreditLineServiceServer(s grpc.ServiceRegistrar, srv CreditLineServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCreditLineServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CreditLineService_ServiceDesc, srv)
+}
+
+func _CreditLineService_DetermineCreditLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetermineCreditLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CreditLineServiceServer).DetermineCreditLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CreditLineService_DetermineCreditLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CreditLineServiceServer).DetermineCreditLimit(ctx, req.(*DetermineCreditLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CreditLineService_ServiceDesc is the grpc.ServiceDesc for CreditLineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CreditLineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "creditline.v1.CreditLineService",
+	HandlerType: (*CreditLineServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DetermineCreditLimit",
+			Handler:    _CreditLineService_DetermineCreditLimit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/creditline/v1/credit_line.proto",
+}
@@ -0,0 +1,105 @@
+package bootstrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/pkg/access"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/middleware"
+)
+
+func Test_Access_List_Behind_Trusted_Proxies(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access.yaml")
+	if err := os.WriteFile(file, []byte("block:\n  - 2001:db8::/32\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	list := access.RetrieveList()
+	if err := list.Replace(access.ConfigSource, []access.Entry{
+		{CIDR: "203.0.113.0/24", Action: access.BlockAction},
+		{CIDR: "198.51.100.7", Action: access.AllowAction},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := access.NewFileLoader(file, list).Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := access.RetrieveTrustedProxies().Replace([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = list.Replace(access.ConfigSource, nil)
+		_ = list.Replace(access.FileSource, nil)
+		_ = access.RetrieveTrustedProxies().Replace(nil)
+	}()
+
+	e := echo.New()
+	e.IPExtractor = middleware.ExtractIP
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	e.GET("/ip", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.RealIP())
+	}, middleware.IpAccessList())
+
+	testCases := map[string]struct {
+		remoteAddr         string
+		forwardedFor       string
+		expectedStatusCode int
+		expectedIP         string
+	}{
+		"blocked_client": {
+			remoteAddr:         "203.0.113.5:4000",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"blocked_client_spoofing_forwarded_for": {
+			remoteAddr:         "203.0.113.5:4000",
+			forwardedFor:       "198.51.100.7",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"blocked_client_behind_trusted_proxy": {
+			remoteAddr:         "10.0.0.2:4000",
+			forwardedFor:       "203.0.113.5",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"blocked_ipv6_client_of_the_list_file": {
+			remoteAddr:         "[2001:db8::1]:4000",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		"forwarded_for_value_added_by_the_client_is_ignored": {
+			remoteAddr:         "10.0.0.2:4000",
+			forwardedFor:       "203.0.113.5, 192.0.2.44",
+			expectedStatusCode: http.StatusOK,
+			expectedIP:         "192.0.2.44",
+		},
+		"forwarded_for_of_untrusted_peer_is_ignored": {
+			remoteAddr:         "192.0.2.44:4000",
+			forwardedFor:       "198.51.100.7",
+			expectedStatusCode: http.StatusOK,
+			expectedIP:         "192.0.2.44",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			r.RemoteAddr = tc.remoteAddr
+			if tc.forwardedFor != "" {
+				r.Header.Set(echo.HeaderXForwardedFor, tc.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, r)
+
+			if w.Code != tc.expectedStatusCode {
+				t.Fatalf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if tc.expectedIP != "" && w.Body.String() != tc.expectedIP {
+				t.Fatalf("unexpected client ip, got: %v, expected: %v", w.Body.String(), tc.expectedIP)
+			}
+		})
+	}
+}
@@ -1,29 +1,410 @@
 package bootstrap
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	pv "github.com/go-playground/validator/v10"
 
 	"credit-line/internal/calculator"
 	"credit-line/internal/controller"
+	"credit-line/internal/ledger"
+	"credit-line/internal/model"
 	"credit-line/internal/service"
+	"credit-line/pkg/access"
+	"credit-line/pkg/account"
+	"credit-line/pkg/applicant"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/certificate"
 	"credit-line/pkg/env"
+	"credit-line/pkg/health"
+	"credit-line/pkg/i18n"
+	"credit-line/pkg/lead"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/middleware"
+	"credit-line/pkg/outbox"
+	"credit-line/pkg/pii"
+	"credit-line/pkg/review"
+	"credit-line/pkg/risk"
+	"credit-line/pkg/tracing"
+	"credit-line/pkg/validator"
+	"credit-line/pkg/webhook"
 )
 
-// Run retrieves the environment, init the database, builds the server router and starts the server
-func Run() error {
-	conf := env.LoadEnvironment()
+// Run retrieves the environment, init the database, builds the server router and starts the server,
+// it refuses to start when the config is invalid
+func Run(args []string) error {
+	conf, err := env.LoadEnvironment(args)
+	if err != nil {
+		return err
+	}
+	l, err := logger.New(conf.Logger, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("failed to init logger, %v", err)
+	}
+	slog.SetDefault(l)
+
+	if err := i18n.RegisterMessage(i18n.English, i18n.RetriesExhaustedKey, conf.Middlewares.DeclineRetriesMessage); err != nil {
+		return fmt.Errorf("failed to register the decline retries message, %v", err)
+	}
+
+	holder := env.NewHolder(env.RetrieveConfigFile(args...), conf, args...)
+	holder.Subscribe(func(conf *env.Environment) {
+		if err := i18n.RegisterMessage(i18n.English, i18n.RetriesExhaustedKey, conf.Middlewares.DeclineRetriesMessage); err != nil {
+			l.Error("failed to register the decline retries message", "error", err)
+		}
+	})
+	middleware.ConfigurePolicies(holder)
+	if err := middleware.ConfigureAccess(holder, l); err != nil {
+		return fmt.Errorf("failed to init the access lists, %v", err)
+	}
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go holder.Watch(watchCtx, l, time.Second*time.Duration(conf.Config.WatchInterval))
+
+	if conf.Access.ListFile != "" {
+		accessFile := access.NewFileLoader(conf.Access.ListFile, access.RetrieveList())
+		if _, err := accessFile.Load(); err != nil {
+			return fmt.Errorf("failed to init the access list file, %v", err)
+		}
+		go accessFile.Watch(watchCtx, l, time.Second*time.Duration(conf.Config.WatchInterval))
+	}
+
+	shutdownTracing, err := tracing.Setup(conf.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing, %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(conf.Server.ShutdownTimeOut))
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			l.Error("failed to flush the traces", "error", err)
+		}
+	}()
+
+	leadSink, err := lead.NewSink(conf.Leads)
+	if err != nil {
+		return fmt.Errorf("failed to init the leads sink, %v", err)
+	}
+	leadStore := lead.NewStore()
+	leadService := service.NewLeads(leadStore, leadSink, conf.Leads, middleware.DeclineExhaustsRetries)
+	defer func() {
+		// the leads waiting for a retry are aborted when they are not sent before the shutdown timeout
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(conf.Server.ShutdownTimeOut))
+		defer cancel()
+		leadService.Shutdown(ctx)
+	}()
+	webhookService := service.NewWebhooks(webhook.NewStore(conf.Webhooks.DeliveryLog), conf.Webhooks)
+	defer func() {
+		// the deliveries waiting for a retry are aborted when they are not sent before the shutdown timeout
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(conf.Server.ShutdownTimeOut))
+		defer cancel()
+		webhookService.Shutdown(ctx)
+	}()
+
+	keyring, err := loadKeyring(conf.Pii)
+	if err != nil {
+		return fmt.Errorf("failed to init the keyring, %v", err)
+	}
+	decisionStore, err := outbox.NewStore(conf.Outbox, keyring)
+	if err != nil {
+		return fmt.Errorf("failed to init the decision store, %v", err)
+	}
+	defer decisionStore.Close()
+	auditLog, err := audit.Open(conf.Audit.FilePath, keyring)
+	if err != nil {
+		return fmt.Errorf("failed to init the audit log, %v", err)
+	}
+	defer auditLog.Close()
+	policy := func() model.Policy {
+		conf := holder.Environment()
+		return model.NewPolicy(conf.Ratio.CashBalance, conf.Ratio.MonthlyRevenue, conf.Middlewares.DeclineRetriesAllowed)
+	}
+	decisionService := service.NewDecisions(decisionStore, auditLog, policy, middleware.DeclineExhaustsRetries)
+	stopRelay, err := startRelay(conf.Outbox, decisionStore, webhookService, l)
+	if err != nil {
+		return fmt.Errorf("failed to init the outbox relay, %v", err)
+	}
+	defer stopRelay()
+	retentionService := service.NewRetention(decisionStore, auditLog, leadStore, conf.Retention)
+	defer startRetention(retentionService.Run, conf.Retention, l)()
 
-	creditLimitCalculator := calculator.NewCreditLine(conf.Ratio)
-	creditLimitService := service.NewCreditLine(creditLimitCalculator)
+	creditLimitCalculator := calculator.NewCreditLine(holder)
+	if conf.Applicants.HashKey == "" {
+		// the identifiers of the requests are ignored without a hash key, so the applicants are only limited by ip
+		l.Warn("APPLICANTS_HASH_KEY is not set, the applicant identifiers of the requests are ignored")
+	}
+	applicantService := service.NewApplicants(applicant.NewStore(), conf.Applicants, middleware.RetriesExhausted)
+	riskService := service.NewRisks(risk.NewStore(), conf.Risk)
+	reviewService := service.NewReviews(review.NewStore(), conf.Reviews, decisionService)
+	go reviewService.Watch(watchCtx, l, time.Second*time.Duration(conf.Reviews.SLAInterval))
+	creditLimitService := service.NewCreditLine(creditLimitCalculator, applicantService, riskService, reviewService,
+		leadService, decisionService)
 	creditLimitRouter := controller.NewCreditLineHandler(creditLimitService)
+	throttlingRouter := controller.NewThrottlingHandler(service.NewThrottling(access.RetrieveList()))
+	leadRouter := controller.NewLeadHandler(leadService)
+	webhookRouter := controller.NewWebhookHandler(webhookService)
+	auditRouter := controller.NewAuditHandler(service.NewAudit(auditLog))
+	retentionRouter := controller.NewRetentionHandler(retentionService)
+	reviewRouter := controller.NewReviewHandler(reviewService)
+	accountStore, accountLedger, err := openAccounts(conf.Outbox, conf.Accounts)
+	if err != nil {
+		return fmt.Errorf("failed to init the accounts, %v", err)
+	}
+	defer accountStore.Close()
+	accountService := service.NewAccounts(accountStore, decisionStore, accountLedger, conf.Accounts)
+	go accountService.Watch(watchCtx, l, time.Second*time.Duration(conf.Accounts.CheckInterval))
+	accountRouter := controller.NewAccountHandler(accountService)
+	openAPIRouter := controller.NewOpenAPIHandler(controller.NewOpenAPIDocument())
 
-	router := newEchoRouter(creditLimitRouter)
+	healthChecks := newHealth(holder, decisionStore)
 
-	srv := newServer(router, conf.Server)
-	err := srv.up()
+	var tlsConfig *tls.Config
+	if conf.Server.TLSEnabled() {
+		certificates, err := certificate.NewReloader(conf.Server)
+		if err != nil {
+			return fmt.Errorf("failed to init TLS, %v", err)
+		}
+		tlsConfig = certificates.TLSConfig()
+		go certificates.Watch(watchCtx, l, time.Second*time.Duration(conf.Config.WatchInterval))
+	}
+
+	adminToken := func() string { return holder.Environment().Admin.Token }
+	reviewers := func() map[string]string { return holder.Environment().Reviews.ReviewerTokens() }
+	router := newEchoRouter(l, conf.Server, adminToken, reviewers, creditLimitRouter, throttlingRouter, leadRouter,
+		webhookRouter, auditRouter, retentionRouter, reviewRouter, accountRouter, openAPIRouter, healthChecks)
+
+	if conf.Server.GRPCEnabled {
+		creditLimitGRPCHandler := controller.NewCreditLineGRPCHandler(creditLimitService, validator.New(pv.New()))
+		grpcSrv := newGRPCServer(creditLimitGRPCHandler, conf.Server, tlsConfig, l)
+		if err := grpcSrv.start(); err != nil {
+			return fmt.Errorf("failed to init gRPC server, %v", err)
+		}
+		defer grpcSrv.stop()
+	}
+
+	srv := newServer(router, conf.Server, healthChecks, tlsConfig, l)
+	err = srv.up()
 	if err != nil {
 		return fmt.Errorf("failed to init server, %v", err)
 	}
 
 	return nil
 }
+
+// newHealth builds the readiness checks of the current config, the config file that is reloaded, the limiter
+// stores and the decision store
+func newHealth(holder *env.Holder, decisions outbox.Store) *health.Health {
+	h := health.New(
+		health.NewChecker("config", func(ctx context.Context) error { return env.CheckEnvironment(holder.Environment()) }),
+		health.NewChecker("limiter_store", middleware.CheckLimiterStores),
+	)
+	h.Register(health.NewChecker("config_file", func(ctx context.Context) error { return holder.Check() }))
+	h.Register(health.NewChecker("decision_store", decisions.Check))
+	return h
+}
+
+// openAccounts opens the store of the accounts next to the decision store, so the accounts are kept in a file
+// when the decisions are, and rebuilds the ledger posting the transactions of the stored movements
+func openAccounts(outboxCfg *env.Outbox, cfg *env.Accounts) (*account.Store, ledger.Ledger, error) {
+	store := account.NewStore()
+	if outboxCfg.Store == outbox.FileStore {
+		var err error
+		if store, err = account.NewFileStore(cfg.FilePath); err != nil {
+			return nil, nil, err
+		}
+	}
+	accountLedger := ledger.NewLedger()
+	for _, transaction := range store.Transactions() {
+		if err := accountLedger.Post(context.Background(), transaction); err != nil {
+			_ = store.Close()
+			return nil, nil, fmt.Errorf("failed to rebuild the ledger, %w", err)
+		}
+	}
+	return store, accountLedger, nil
+}
+
+// startRelay starts the relay of the decision events to the configured broker, when there is one, and to the
+// webhooks, the returned function stops the relay after flushing the pending events and closes the brokers
+func startRelay(cfg *env.Outbox, store outbox.Store, webhooks outbox.Broker, l *slog.Logger) (func(), error) {
+	configured, err := outbox.NewBroker(cfg)
+	if err != nil {
+		return func() {}, err
+	}
+	// the webhooks are the last broker, they always acknowledge the events once their deliveries are stored
+	broker := outbox.NewFanoutBroker(configured, webhooks)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	relay := outbox.NewRelay(store, broker, cfg.Subject, int(cfg.RelayBatch))
+	go func() {
+		defer close(done)
+		relay.Run(ctx, l, time.Second*time.Duration(cfg.RelayInterval))
+	}()
+	return func() {
+		cancel()
+		<-done
+		if err := broker.Close(); err != nil {
+			l.Error("failed to close the outbox broker", "error", err)
+		}
+	}, nil
+}
+
+// startRetention starts the retention scheduler when the decisions or the idle cache entries expire, the returned
+// function stops the scheduler after the running purge
+func startRetention(run func(context.Context, *slog.Logger, time.Duration), cfg *env.Retention, l *slog.Logger) func() {
+	if cfg.Days == 0 && cfg.CacheIdle == 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx, l, time.Minute*time.Duration(cfg.Interval))
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// loadKeyring loads the keyring that encrypts the stored applicant data, nil when the keyring file is not set
+func loadKeyring(cfg *env.Pii) (*pii.Keyring, error) {
+	if cfg.KeyringFile == "" {
+		return nil, nil
+	}
+	return pii.LoadKeyring(cfg.KeyringFile)
+}
+
+// RotateKeys adds a new primary key to the keyring file and encrypts again with it the data keys of the decision
+// store file and the audit log, the application must be stopped
+func RotateKeys(args []string, w io.Writer) error {
+	conf, err := env.LoadEnvironment(args)
+	if err != nil {
+		return err
+	}
+	if conf.Pii.KeyringFile == "" {
+		return errors.New("the PII_KEYRING_FILE variable is not set")
+	}
+	keyring, err := pii.RotateKeyring(conf.Pii.KeyringFile)
+	if err != nil {
+		return err
+	}
+
+	var records, entries int
+	if conf.Outbox.Store == outbox.FileStore {
+		if records, err = rewrapFile(conf.Outbox.FilePath, keyring, outbox.RewrapFile); err != nil {
+			return err
+		}
+	}
+	if entries, err = rewrapFile(conf.Audit.FilePath, keyring, audit.RewrapFile); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "keyring %s rotated, primary key %s: %d decision records and %d audit entries encrypted again\n",
+		conf.Pii.KeyringFile, keyring.Primary(), records, entries)
+	return err
+}
+
+// rewrapFile encrypts again the data keys of a file with rewrap, a file that does not exist has nothing to encrypt
+func rewrapFile(path string, keyring *pii.Keyring, rewrap func(string, *pii.Keyring) (int, error)) (int, error) {
+	n, err := rewrap(path, keyring)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt again %s, %w", path, err)
+	}
+	return n, nil
+}
+
+// VerifyAudit verifies the hash chain of the audit log file, the file of the environment is verified when no path
+// is given
+func VerifyAudit(args []string, w io.Writer) error {
+	path := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+	if path == "" {
+		conf, err := env.LoadEnvironment(args)
+		if err != nil {
+			return err
+		}
+		path = conf.Audit.FilePath
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s, %w", path, err)
+	}
+	defer f.Close()
+	report, err := audit.Verify(f)
+	if err != nil {
+		return fmt.Errorf("audit log %s not verified, %w", path, err)
+	}
+	_, err = fmt.Fprintf(w, "audit log %s verified: %d entries, head hash %s, root hash %s\n", path, report.Entries,
+		report.HeadHash, report.RootHash)
+	return err
+}
+
+// PurgeRetention purges or anonymizes the decisions and the audit entries older than the retention days and writes
+// the retention report, nothing is changed with the --dry-run flag; the application must be stopped for a purge
+func PurgeRetention(args []string, w io.Writer) error {
+	dryRun := false
+	flags := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			dryRun = true
+			continue
+		}
+		flags = append(flags, arg)
+	}
+	conf, err := env.LoadEnvironment(flags)
+	if err != nil {
+		return err
+	}
+	if conf.Retention.Days == 0 {
+		return errors.New("the RETENTION_DAYS variable is not set")
+	}
+
+	keyring, err := loadKeyring(conf.Pii)
+	if err != nil {
+		return fmt.Errorf("failed to load the keyring, %w", err)
+	}
+	decisionStore, err := outbox.NewStore(conf.Outbox, keyring)
+	if err != nil {
+		return fmt.Errorf("failed to open the decision store, %w", err)
+	}
+	defer decisionStore.Close()
+	auditLog, err := audit.Open(conf.Audit.FilePath, keyring)
+	if err != nil {
+		return fmt.Errorf("failed to open the audit log, %w", err)
+	}
+	defer auditLog.Close()
+
+	report, err := service.NewRetention(decisionStore, auditLog, lead.NewStore(), conf.Retention).Purge(context.Background(), dryRun)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// PrintConfig writes the effective config with the secrets redacted
+func PrintConfig(args []string, w io.Writer) error {
+	conf, err := env.LoadEnvironment(args)
+	if err != nil {
+		return err
+	}
+	return env.PrintEnvironment(conf, w)
+}
@@ -0,0 +1,303 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"credit-line/internal/controller"
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/account"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/health"
+	"credit-line/pkg/outbox"
+)
+
+func Test_Run_Refuses_Invalid_Config(t *testing.T) {
+	testCases := map[string]struct {
+		args           []string
+		expectedErrors []string
+	}{
+		"zero_ratio": {
+			args:           []string{"--ratio.cashBalance=0"},
+			expectedErrors: []string{"CASH_BALANCE_RATIO: must be greater than 0"},
+		},
+		"malformed_values": {
+			args: []string{"--ratio.monthlyRevenue=abc", "--middlewares.declineRetriesAllowed=0", "--logger.format=xml"},
+			expectedErrors: []string{
+				`MONTHLY_REVENUE_RATIO: invalid value "abc", expected float64`,
+				"DECLINE_RETRIES_ALLOWED: must be greater than or equal to 1",
+				"LOG_FORMAT: must be one of [json text]",
+			},
+		},
+		"invalid_access_list": {
+			args:           []string{"--access.blockList=203.0.113.0/24,10.0.0.0/33"},
+			expectedErrors: []string{"ACCESS_BLOCK_LIST: must be a comma-separated list of ips or CIDRs"},
+		},
+		"unknown_flag": {
+			args:           []string{"--ratio.unknown=1"},
+			expectedErrors: []string{"flag provided but not defined: -ratio.unknown"},
+		},
+		"missing_config_file": {
+			args:           []string{"--config.file=missing.yaml"},
+			expectedErrors: []string{"failed to read config file missing.yaml"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := Run(tc.args)
+			if err == nil {
+				t.Fatalf("got nil error expecting: %v", tc.expectedErrors)
+			}
+			for _, expected := range tc.expectedErrors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Fatalf("unexpected error got: %v expected to contain: %v", err, expected)
+				}
+			}
+		})
+	}
+}
+
+func Test_Run_Flags_Do_Not_Change_The_Process_Environment(t *testing.T) {
+	if err := Run([]string{"--ratio.cashBalance=0"}); err == nil {
+		t.Fatalf("got nil error expecting the invalid ratio error")
+	}
+	if value, ok := os.LookupEnv("CASH_BALANCE_RATIO"); ok {
+		t.Fatalf("unexpected CASH_BALANCE_RATIO variable set by the flags: %q", value)
+	}
+	if _, err := env.LoadEnvironment(nil); err != nil {
+		t.Fatalf("unexpected error loading the environment without flags: %v", err)
+	}
+}
+
+// newHealthRouter builds the router with the readiness checks of a config file and a file decision store in a
+// temporary directory
+func newHealthRouter(t *testing.T) (http.Handler, *health.Health, string, outbox.Store) {
+	t.Helper()
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("ratio:\n  cashBalance: 3\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args := []string{"--config.file=" + configFile}
+	conf, err := env.LoadEnvironment(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := outbox.NewFileStore(filepath.Join(dir, "decisions.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	h := newHealth(env.NewHolder(configFile, conf, args...), store)
+	router := newEchoRouter(slog.Default(), &env.Server{BodyLimit: 1024, HandlerTimeout: 1}, func() string { return "" },
+		func() map[string]string { return nil }, controller.NewCreditLineHandler(nil), controller.NewThrottlingHandler(nil),
+		controller.NewLeadHandler(nil), controller.NewWebhookHandler(nil), controller.NewAuditHandler(nil),
+		controller.NewRetentionHandler(nil), controller.NewReviewHandler(nil), controller.NewAccountHandler(nil),
+		controller.NewOpenAPIHandler(controller.NewOpenAPIDocument()), h)
+	return router, h, configFile, store
+}
+
+func Test_Health_Routes(t *testing.T) {
+	testCases := map[string]struct {
+		path               string
+		notReady           bool
+		invalidConfigFile  bool
+		closedStore        bool
+		expectedStatusCode int
+		expectedStatus     string
+		expectedFailed     string
+	}{
+		"liveness": {
+			path:               livenessPath,
+			notReady:           true,
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     "ok",
+		},
+		"readiness": {
+			path:               readinessPath,
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     "ok",
+		},
+		"readiness_shutting_down": {
+			path:               readinessPath,
+			notReady:           true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     "shutting_down",
+		},
+		"readiness_invalid_config_file": {
+			path:               readinessPath,
+			invalidConfigFile:  true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     "fail",
+			expectedFailed:     "config_file",
+		},
+		"readiness_closed_decision_store": {
+			path:               readinessPath,
+			closedStore:        true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     "fail",
+			expectedFailed:     "decision_store",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			router, h, configFile, store := newHealthRouter(t)
+			h.SetReady(!tc.notReady)
+			if tc.invalidConfigFile {
+				if err := os.WriteFile(configFile, []byte("ratio:\n  cashBalance: 0\n"), 0o600); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if tc.closedStore {
+				store.Close()
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if w.Code != tc.expectedStatusCode {
+				t.Fatalf("unexpected status code, got: %v, expected: %v, body: %s", w.Code, tc.expectedStatusCode, w.Body.String())
+			}
+			var response health.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("unexpected unmarshall error, got: %v", err)
+			}
+			if response.Status != tc.expectedStatus {
+				t.Errorf("unexpected status, got: %v, expected: %v", response.Status, tc.expectedStatus)
+			}
+			for check, result := range response.Checks {
+				if failed := result.Status != "ok"; failed != (check == tc.expectedFailed) {
+					t.Errorf("unexpected check %s, got: %+v", check, result)
+				}
+			}
+		})
+	}
+}
+
+func Test_Server_Fails_Readiness_Before_Draining(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	router, h, _, _ := newHealthRouter(t)
+	srv := newServer(router, &env.Server{Port: uint16(port), ShutdownTimeOut: 1, ShutdownDelay: 1}, h, nil, slog.Default())
+	stopped := make(chan error, 1)
+	go func() { stopped <- srv.up() }()
+
+	readiness := func() int {
+		response, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, readinessPath))
+		if err != nil {
+			return 0
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+	waitFor := func(expected int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for readiness() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("the readiness did not respond %v", expected)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// the readiness fails while the server still serves during the shutdown delay
+	waitFor(http.StatusOK)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitFor(http.StatusServiceUnavailable)
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the server did not stop")
+	}
+}
+
+func Test_Accounts_Are_Kept_After_A_Restart(t *testing.T) {
+	dir := t.TempDir()
+	outboxCfg := &env.Outbox{Store: outbox.FileStore, FilePath: filepath.Join(dir, "decisions.jsonl")}
+	accountsCfg := &env.Accounts{CheckInterval: 300, FilePath: filepath.Join(dir, "accounts.jsonl")}
+	start := func() (outbox.Store, *account.Store, service.AccountService) {
+		t.Helper()
+		decisions, err := outbox.NewStore(outboxCfg, nil)
+		if err != nil {
+			t.Fatalf("unexpected error opening the decision store: %v", err)
+		}
+		store, accountLedger, err := openAccounts(outboxCfg, accountsCfg)
+		if err != nil {
+			t.Fatalf("unexpected error opening the accounts: %v", err)
+		}
+		return decisions, store, service.NewAccounts(store, decisions, accountLedger, accountsCfg)
+	}
+	ctx := context.Background()
+
+	decisions, store, accounts := start()
+	decision := &model.Decision{ID: "approved", IP: "192.0.2.10", CreditStatus: model.Approved,
+		CreditLineAuthorized: "145.10", DeterminedAt: time.Now().UTC()}
+	if err := decisions.Save(decision, nil); err != nil {
+		t.Fatalf("unexpected error saving the decision: %v", err)
+	}
+	opened, err := accounts.Open(ctx, "192.0.2.10", "approved")
+	if err != nil {
+		t.Fatalf("unexpected error opening the account: %v", err)
+	}
+	if _, _, err := accounts.Move(ctx, opened.ID, model.Drawdown, 5000, "first"); err != nil {
+		t.Fatalf("unexpected error drawing down: %v", err)
+	}
+	if _, _, err := accounts.Move(ctx, opened.ID, model.Repayment, 1000, ""); err != nil {
+		t.Fatalf("unexpected error repaying: %v", err)
+	}
+	_ = decisions.Close()
+	_ = store.Close()
+
+	decisions, store, accounts = start()
+	defer decisions.Close()
+	defer store.Close()
+	if _, err := accounts.Open(ctx, "192.0.2.10", "approved"); !errors.Is(err, pkgerrors.ErrAccountExists) {
+		t.Errorf("unexpected error accepting the decision again, got: %v", err)
+	}
+	restored, err := accounts.Account(ctx, opened.ID)
+	if err != nil {
+		t.Fatalf("unexpected error retrieving the account: %v", err)
+	}
+	if restored.Outstanding != 4000 || restored.Available != 10510 || restored.Limit != 14510 || restored.Token != "" {
+		t.Errorf("unexpected account after the restart, got: %+v", restored)
+	}
+	if !accounts.Authorize(ctx, opened.ID, opened.Token) {
+		t.Errorf("expected the token of the account to be authorized after the restart")
+	}
+	if movements, _ := accounts.Movements(ctx, opened.ID); len(movements) != 2 {
+		t.Errorf("unexpected movements after the restart, got: %d", len(movements))
+	}
+	if _, posted, err := accounts.Move(ctx, opened.ID, model.Drawdown, 5000, "first"); err != nil || posted {
+		t.Errorf("unexpected retry of the movement after the restart, posted: %v, error: %v", posted, err)
+	}
+	if check := accounts.Check(ctx); !check.Consistent || check.Transactions != 2 || check.Accounts != 1 {
+		t.Errorf("unexpected ledger check after the restart, got: %+v", check)
+	}
+}
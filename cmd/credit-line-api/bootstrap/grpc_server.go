@@ -0,0 +1,82 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	creditlinev1 "credit-line/api/creditline/v1"
+	"credit-line/internal/controller"
+	"credit-line/pkg/certificate"
+	"credit-line/pkg/env"
+	"credit-line/pkg/middleware"
+	"credit-line/pkg/tracing"
+)
+
+// grpcServer represents the gRPC server of the application
+type grpcServer struct {
+	handler *controller.CreditLineGRPCHandler
+	Srv     *env.Server
+	srv     *grpc.Server
+	tls     *tls.Config
+	logger  *slog.Logger
+}
+
+// newGRPCServer create a new pointer of the grpcServer struct
+func newGRPCServer(handler *controller.CreditLineGRPCHandler, srv *env.Server, tlsConfig *tls.Config, logger *slog.Logger) *grpcServer {
+	return &grpcServer{
+		handler: handler,
+		Srv:     srv,
+		tls:     tlsConfig,
+		logger:  logger,
+	}
+}
+
+// start listens on the gRPC port and serves the requests in background
+func (gs *grpcServer) start() error {
+	srvPort := gs.Srv.GRPCPort
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%v", srvPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %v: %w", srvPort, err)
+	}
+
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(
+		tracing.UnaryServerTracing(),
+		middleware.UnaryRequestID(gs.logger),
+		middleware.UnaryRequestTimeout(time.Second*time.Duration(gs.Srv.HandlerTimeout)),
+		certificate.UnaryClientCertificate(),
+		middleware.UnaryIpAccessList(),
+		middleware.UnaryValidateRetries(),
+		middleware.UnaryIpRateLimitByTime(),
+		middleware.UnaryIpRateLimitByFail(),
+	), grpc.MaxRecvMsgSize(int(gs.Srv.BodyLimit))}
+	if gs.tls != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(gs.tls)))
+	}
+
+	gs.srv = grpc.NewServer(opts...)
+	creditlinev1.RegisterCreditLineServiceServer(gs.srv, gs.handler)
+
+	go func() {
+		gs.logger.Info("gRPC server online", "port", srvPort, "tls", gs.tls != nil)
+		if err := gs.srv.Serve(lis); err != nil {
+			gs.logger.Error("gRPC server error", "error", err)
+		}
+	}()
+	return nil
+}
+
+// stop stops the gRPC server gracefully
+func (gs *grpcServer) stop() {
+	if gs.srv == nil {
+		return
+	}
+	gs.logger.Info("gRPC server shutdown...")
+	gs.srv.GracefulStop()
+}
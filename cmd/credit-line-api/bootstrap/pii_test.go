@@ -0,0 +1,138 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/middleware"
+	"credit-line/pkg/outbox"
+	"credit-line/pkg/pii"
+)
+
+func Test_Log_Redaction(t *testing.T) {
+	testCases := map[string]struct {
+		redact           bool
+		expectedValues   []string
+		unexpectedValues []string
+	}{
+		"redacted": {
+			redact:           true,
+			expectedValues:   []string{`"ip":"192.0.2.0/24"`, pii.Redacted},
+			unexpectedValues: []string{"192.0.2.10", "owner@example.com"},
+		},
+		"not_redacted": {
+			expectedValues: []string{`"ip":"192.0.2.10"`, "owner@example.com"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			l, err := logger.New(&env.Logger{Level: "info", Format: "json", RedactPII: tc.redact}, &logs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			e := echo.New()
+			e.Use(middleware.RequestID(l))
+			e.Use(logger.HTTPAccessLog())
+			e.HTTPErrorHandler = errors.HTTPErrorHandler
+			e.GET("/fail", func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusInternalServerError).
+					SetInternal(fmt.Errorf("lead of owner@example.com from 192.0.2.10 not delivered"))
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			r.RemoteAddr = "192.0.2.10:4000"
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, r)
+
+			for _, expected := range tc.expectedValues {
+				if !strings.Contains(logs.String(), expected) {
+					t.Errorf("unexpected logs, got: %v, expected to contain: %v", logs.String(), expected)
+				}
+			}
+			for _, unexpected := range tc.unexpectedValues {
+				if strings.Contains(logs.String(), unexpected) {
+					t.Errorf("unexpected logs, got: %v, expected to not contain: %v", logs.String(), unexpected)
+				}
+			}
+		})
+	}
+}
+
+func Test_Rotate_Keys(t *testing.T) {
+	dir := t.TempDir()
+	args := []string{
+		"--config.file=" + filepath.Join(dir, "missing.env"),
+		"--pii.keyringFile=" + filepath.Join(dir, "keyring.json"),
+		"--outbox.store=file",
+		"--outbox.filePath=" + filepath.Join(dir, "decisions.jsonl"),
+		"--audit.filePath=" + filepath.Join(dir, "audit.jsonl"),
+	}
+	if err := RotateKeys(args, &bytes.Buffer{}); err == nil {
+		t.Fatalf("got nil error expecting the missing config file error")
+	}
+	args = args[1:]
+
+	var out bytes.Buffer
+	if err := RotateKeys(args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "0 decision records and 0 audit entries") {
+		t.Fatalf("unexpected output: %v", out.String())
+	}
+
+	keyring, err := pii.LoadKeyring(filepath.Join(dir, "keyring.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := outbox.NewFileStore(filepath.Join(dir, "decisions.jsonl"), keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auditLog, err := audit.Open(filepath.Join(dir, "audit.jsonl"), keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := func() model.Policy { return model.NewPolicy(3, 5, 3) }
+	decisions := service.NewDecisions(store, auditLog, policy, func(ip string) bool { return false })
+	for i := 0; i < 2; i++ {
+		creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+		if err := decisions.Record(context.Background(), "192.0.2.10", creditLine, model.NewCreditLineResponse(model.Approved, "1305.90")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	store.Close()
+	auditLog.Close()
+
+	out.Reset()
+	if err := RotateKeys(args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "2 decision records and 2 audit entries") {
+		t.Fatalf("unexpected output: %v", out.String())
+	}
+	out.Reset()
+	if err := VerifyAudit([]string{filepath.Join(dir, "audit.jsonl")}, &out); err != nil || !strings.Contains(out.String(), "2 entries") {
+		t.Fatalf("unexpected verification: %v %v", out.String(), err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "keyring.json")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected keyring file: %v %v", info, err)
+	}
+}
@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/outbox"
+)
+
+func Test_Purge_Retention(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "decisions.jsonl")
+	auditPath := filepath.Join(dir, "audit.jsonl")
+
+	store, err := outbox.NewFileStore(storePath, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auditLog, err := audit.Open(auditPath, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := func() model.Policy { return model.NewPolicy(3, 5, 3) }
+	decisions := service.NewDecisions(store, auditLog, policy, func(ip string) bool { return false })
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+	if err := decisions.Record(context.Background(), "192.0.2.10", creditLine, model.NewCreditLineResponse(model.Approved, "1305.90")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Close()
+	auditLog.Close()
+
+	args := []string{
+		"--outbox.store=file",
+		"--outbox.filePath=" + storePath,
+		"--audit.filePath=" + auditPath,
+	}
+	if err := PurgeRetention(append(args, "--dry-run"), &bytes.Buffer{}); err == nil {
+		t.Fatalf("got nil error expecting the missing retention days error")
+	}
+
+	// the decisions of today are retained
+	var out bytes.Buffer
+	args = append(args, "--retention.days=1")
+	if err := PurgeRetention(append(args, "--dry-run"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report model.RetentionReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("unexpected report: %v %v", out.String(), err)
+	}
+	if !report.DryRun || report.Cutoff == nil || report.Decisions.Expired != 0 || report.AuditEntries.Expired != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	before, _ := os.ReadFile(storePath)
+	if err := PurgeRetention(args, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if after, _ := os.ReadFile(storePath); !bytes.Equal(before, after) {
+		t.Errorf("unexpected decision store change, got: %s, expected: %s", after, before)
+	}
+}
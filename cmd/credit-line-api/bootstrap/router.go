@@ -1,29 +1,104 @@
 package bootstrap
 
 import (
-	"net/http"
+	"log/slog"
+	"time"
 
 	pv "github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	mw "github.com/labstack/echo/v4/middleware"
 
 	"credit-line/internal/controller"
+	"credit-line/pkg/certificate"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/health"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
 	"credit-line/pkg/middleware"
+	"credit-line/pkg/tracing"
 	"credit-line/pkg/validator"
 )
 
-// newEchoRouter builds an instance of the echo router
-func newEchoRouter(clh *controller.CreditLineHandler) http.Handler {
+const (
+	// metricsPath path of the endpoint that exposes the prometheus metrics
+	metricsPath = "/metrics"
+	// livenessPath path of the endpoint that reports if the process is alive
+	livenessPath = "/healthz"
+	// readinessPath path of the endpoint that reports if the application can receive traffic
+	readinessPath = "/readyz"
+)
+
+// newEchoRouter builds an instance of the echo router, the admin routes are authenticated with the token
+// retrieved by adminToken and the back-office routes with the reviewer tokens retrieved by reviewers
+func newEchoRouter(l *slog.Logger, srv *env.Server, adminToken func() string, reviewers func() map[string]string,
+	clh *controller.CreditLineHandler, th *controller.ThrottlingHandler, lh *controller.LeadHandler,
+	wh *controller.WebhookHandler, ah *controller.AuditHandler, rh *controller.RetentionHandler,
+	vh *controller.ReviewHandler, ach *controller.AccountHandler, oh *controller.OpenAPIHandler, h *health.Health) *echo.Echo {
 	e := echo.New()
-	e.Use(mw.LoggerWithConfig(mw.LoggerConfig{
-		Format:           "${time_custom} ip=${remote_ip} method=${method}, uri=${uri}, status=${status} latency=${latency_human} \n",
-		CustomTimeFormat: "2006/01/02 15:04:05",
-	}))
+	e.HideBanner = true
+	e.IPExtractor = middleware.ExtractIP
+	e.Use(middleware.RequestID(l))
+	e.Use(logger.HTTPAccessLog())
+	e.Use(certificate.HTTPClientCertificate())
+	e.Use(mw.Recover())
+	e.Use(metrics.HTTPMetrics())
+	e.Use(tracing.HTTPTracing())
+	e.Use(middleware.BodyLimit(srv.BodyLimit))
+	e.Use(middleware.RequestTimeout(time.Second * time.Duration(srv.HandlerTimeout)))
 	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+
+	e.GET(metricsPath, metrics.Handler())
+	e.GET(livenessPath, h.Liveness)
+	e.GET(readinessPath, h.Readiness)
+	e.GET(controller.OpenAPIPath, oh.OpenAPI)
+	e.GET(controller.SwaggerUIPath, oh.SwaggerUI)
 
 	products := e.Group("/api/v1/credits")
-	products.POST("/calculate/limit",
-		clh.CreditLine, middleware.ValidateRetries(), middleware.IpRateLimitByTime(), middleware.IpRateLimitByFail())
+	products.POST("/calculate/limit", clh.CreditLine, middleware.IpAccessList(),
+		middleware.ValidateRetries(), middleware.IpRateLimitByTime(), middleware.IpRateLimitByFail())
+	products.GET("/leads/:id", lh.LeadStatus, middleware.IpAccessList())
+	// an account is opened by the ip of its decision and its requests are authenticated with the account token
+	accountAuth := middleware.AccountAuth(ach.Authorize)
+	products.POST("/accounts", ach.OpenAccount, middleware.IpAccessList())
+	products.GET("/accounts/:id", ach.Account, middleware.IpAccessList(), accountAuth)
+	products.GET("/accounts/:id/movements", ach.Movements, middleware.IpAccessList(), accountAuth)
+	products.POST("/accounts/:id/drawdowns", ach.Drawdown, middleware.IpAccessList(), accountAuth)
+	products.POST("/accounts/:id/repayments", ach.Repayment, middleware.IpAccessList(), accountAuth)
+
+	// the admin middleware is added by route, a group middleware would also register catch-all routes
+	adminAuth := middleware.AdminAuth(adminToken)
+	admin := e.Group("/admin")
+	admin.GET("/clients", th.Clients, adminAuth)
+	admin.GET("/clients/:ip", th.Client, adminAuth)
+	admin.DELETE("/clients/:ip", th.ResetClient, adminAuth)
+	admin.POST("/clients/:ip/allowance", th.GrantAllowance, adminAuth)
+	admin.GET("/access-list", th.AccessList, adminAuth)
+	admin.POST("/access-list", th.AddAccessEntry, adminAuth)
+	admin.DELETE("/access-list", th.RemoveAccessEntry, adminAuth)
+	admin.GET("/leads", lh.Leads, adminAuth)
+	admin.GET("/leads/:id", lh.Lead, adminAuth)
+	admin.PATCH("/leads/:id", lh.UpdateLeadStatus, adminAuth)
+	admin.GET("/webhooks", wh.Webhooks, adminAuth)
+	admin.POST("/webhooks", wh.CreateWebhook, adminAuth)
+	admin.DELETE("/webhooks/:id", wh.DeleteWebhook, adminAuth)
+	admin.GET("/webhooks/:id/deliveries", wh.Deliveries, adminAuth)
+	admin.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", wh.Redeliver, adminAuth)
+	admin.GET("/audit/decisions/:id/proof", ah.DecisionProof, adminAuth)
+	admin.GET("/retention/report", rh.RetentionReport, adminAuth)
+	admin.POST("/retention/purge", rh.PurgeRetention, adminAuth)
+	admin.GET("/accounts", ach.Accounts, adminAuth)
+	admin.POST("/accounts/:id/close", ach.CloseAccount, adminAuth)
+	admin.GET("/ledger/check", ach.LedgerCheck, adminAuth)
+
+	reviewerAuth := middleware.ReviewerAuth(reviewers)
+	backoffice := e.Group("/backoffice")
+	backoffice.GET("/reviews", vh.Reviews, reviewerAuth)
+	backoffice.GET("/reviews/:id", vh.Review, reviewerAuth)
+	backoffice.POST("/reviews/:id/assign", vh.AssignReview, reviewerAuth)
+	backoffice.POST("/reviews/:id/notes", vh.AddReviewNote, reviewerAuth)
+	backoffice.POST("/reviews/:id/decision", vh.ResolveReview, reviewerAuth)
 
 	return e
 }
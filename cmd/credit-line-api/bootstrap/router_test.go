@@ -0,0 +1,119 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"credit-line/internal/controller"
+	"credit-line/internal/ledger"
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/account"
+	"credit-line/pkg/env"
+	"credit-line/pkg/health"
+	"credit-line/pkg/outbox"
+)
+
+// echoParam expression of the echo path params, documented as {param}
+var echoParam = regexp.MustCompile(`:(\w+)`)
+
+func Test_Routes_Are_Documented(t *testing.T) {
+	doc := controller.NewOpenAPIDocument()
+	router := newEchoRouter(slog.Default(), &env.Server{BodyLimit: 1024, HandlerTimeout: 1}, func() string { return "" },
+		func() map[string]string { return nil }, controller.NewCreditLineHandler(nil), controller.NewThrottlingHandler(nil),
+		controller.NewLeadHandler(nil), controller.NewWebhookHandler(nil), controller.NewAuditHandler(nil),
+		controller.NewRetentionHandler(nil), controller.NewReviewHandler(nil), controller.NewAccountHandler(nil),
+		controller.NewOpenAPIHandler(doc), health.New())
+
+	undocumented := map[string]bool{
+		controller.OpenAPIPath:   true,
+		controller.SwaggerUIPath: true,
+		metricsPath:              true,
+		livenessPath:             true,
+		readinessPath:            true,
+	}
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if undocumented[route.Path] {
+			continue
+		}
+		path := echoParam.ReplaceAllString(route.Path, "{$1}")
+		registered[route.Method+" "+path] = true
+
+		pathItem, ok := doc.Paths[path]
+		if !ok || pathItem.Operation(route.Method) == nil {
+			t.Errorf("the route %s %s is not documented", route.Method, route.Path)
+		}
+	}
+
+	for path, pathItem := range doc.Paths {
+		for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+			if pathItem.Operation(method) != nil && !registered[method+" "+path] {
+				t.Errorf("the documented operation %s %s is not registered", method, path)
+			}
+		}
+	}
+}
+
+func Test_Account_Routes_Are_Authenticated(t *testing.T) {
+	decisions := outbox.NewMemoryStore()
+	_ = decisions.Save(&model.Decision{ID: "d1", IP: "192.0.2.1", CreditStatus: model.Approved,
+		CreditLineAuthorized: "145.10", DeterminedAt: time.Now()}, nil)
+	accounts := service.NewAccounts(account.NewStore(), decisions, ledger.NewLedger(), &env.Accounts{AcceptanceWindow: 30})
+	router := newEchoRouter(slog.Default(), &env.Server{BodyLimit: 1024, HandlerTimeout: 1}, func() string { return "" },
+		func() map[string]string { return nil }, controller.NewCreditLineHandler(nil), controller.NewThrottlingHandler(nil),
+		controller.NewLeadHandler(nil), controller.NewWebhookHandler(nil), controller.NewAuditHandler(nil),
+		controller.NewRetentionHandler(nil), controller.NewReviewHandler(nil), controller.NewAccountHandler(accounts),
+		controller.NewOpenAPIHandler(controller.NewOpenAPIDocument()), health.New())
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/api/v1/credits/accounts", "", `{"decisionId": "d1"}`)
+	var opened struct {
+		ID    string `json:"id"`
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &opened); w.Code != http.StatusCreated || err != nil || opened.Token == "" {
+		t.Fatalf("unexpected opened account, got: %v %s", w.Code, w.Body.String())
+	}
+
+	testCases := map[string]struct {
+		token              string
+		expectedStatusCode int
+	}{
+		"without_token":    {expectedStatusCode: http.StatusUnauthorized},
+		"token_of_other":   {token: "another-token", expectedStatusCode: http.StatusUnauthorized},
+		"token_of_account": {token: opened.Token, expectedStatusCode: http.StatusCreated},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if w := serve(http.MethodPost, "/api/v1/credits/accounts/"+opened.ID+"/drawdowns", tc.token,
+				`{"amount": 10}`); w.Code != tc.expectedStatusCode {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			for _, path := range []string{"/api/v1/credits/accounts/" + opened.ID, "/api/v1/credits/accounts/" + opened.ID + "/movements"} {
+				expected := tc.expectedStatusCode
+				if expected == http.StatusCreated {
+					expected = http.StatusOK
+				}
+				if w := serve(http.MethodGet, path, tc.token, ""); w.Code != expected {
+					t.Errorf("unexpected status code of %s, got: %v, expected: %v", path, w.Code, expected)
+				}
+			}
+		})
+	}
+}
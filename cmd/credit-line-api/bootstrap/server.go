@@ -2,8 +2,9 @@ package bootstrap
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,66 +12,90 @@ import (
 	"time"
 
 	"credit-line/pkg/env"
+	"credit-line/pkg/health"
 )
 
 // server represents the server of the application
 type server struct {
 	router http.Handler
 	Srv    *env.Server
+	health *health.Health
+	tls    *tls.Config
+	logger *slog.Logger
 }
 
 // newServer create a new pointer of the server struct
-func newServer(router http.Handler, srv *env.Server) *server {
+func newServer(router http.Handler, srv *env.Server, health *health.Health, tlsConfig *tls.Config, logger *slog.Logger) *server {
 	return &server{
 		router: router,
 		Srv:    srv,
+		health: health,
+		tls:    tlsConfig,
+		logger: logger,
 	}
 }
 
-// up starts the HTTP server
+// up starts the HTTP server, the server serves TLS when the TLS config is set
 func (s *server) up() error {
 
 	srvPort := s.Srv.Port
 	srvShutdownTimeOut := s.Srv.ShutdownTimeOut
+	srvShutdownDelay := s.Srv.ShutdownDelay
 
 	srvErr := make(chan error, 1)
 	srvShutdown := make(chan os.Signal, 1)
 
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%v", srvPort),
-		Handler: s.router,
+		Addr:              fmt.Sprintf(":%v", srvPort),
+		Handler:           s.router,
+		ReadHeaderTimeout: time.Second * time.Duration(s.Srv.ReadHeaderTimeout),
+		ReadTimeout:       time.Second * time.Duration(s.Srv.ReadTimeout),
+		WriteTimeout:      time.Second * time.Duration(s.Srv.WriteTimeout),
+		IdleTimeout:       time.Second * time.Duration(s.Srv.IdleTimeout),
+		MaxHeaderBytes:    s.Srv.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
+		TLSConfig:         s.tls,
 	}
 
 	go func() {
-		log.Printf("Server online on port: %v", srvPort)
+		s.logger.Info("server online", "port", srvPort, "tls", s.tls != nil)
+		if s.tls != nil {
+			srvErr <- srv.ListenAndServeTLS("", "")
+			return
+		}
 		srvErr <- srv.ListenAndServe()
 	}()
 
 	signal.Notify(srvShutdown, os.Interrupt, syscall.SIGTERM)
+	s.health.SetReady(true)
 
 	select {
 	case err := <-srvErr:
 		return fmt.Errorf("server error: %w", err)
 	case shutdownSignal := <-srvShutdown:
-		log.Println("starting shutdown...")
+		s.logger.Info("starting shutdown...", "signal", shutdownSignal.String())
+
+		// readiness fails before draining so the load balancer stops sending traffic
+		s.health.SetReady(false)
+		time.Sleep(time.Second * time.Duration(srvShutdownDelay))
 
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(srvShutdownTimeOut))
 		defer cancel()
 
 		err := srv.Shutdown(ctx)
 		if err != nil {
-			log.Println("gracefully shutdown failed")
+			s.logger.Warn("gracefully shutdown failed", "error", err)
 			err = srv.Close()
 		}
 
 		switch {
 		case shutdownSignal == syscall.SIGINT:
-			log.Println("the stop signal caused shutdown")
+			s.logger.Info("the stop signal caused shutdown")
 		case err != nil:
-			log.Println("could not stop server gracefully: %w", err)
+			s.logger.Error("could not stop server gracefully", "error", err)
 		}
 
-		log.Println("server shutdown...")
+		s.logger.Info("server shutdown...")
 	}
 	return nil
 }
@@ -0,0 +1,188 @@
+package bootstrap
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/pkg/certificate"
+	"credit-line/pkg/env"
+)
+
+func Test_TLS_Client_Certificate(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCertificate(t, "test-ca", nil, nil)
+	writeTestCertificate(t, filepath.Join(dir, "ca.pem"), ca, nil)
+	server, serverKey := newTestCertificate(t, "localhost", ca, caKey)
+	writeTestCertificate(t, filepath.Join(dir, "server.pem"), server, serverKey)
+	client, clientKey := newTestCertificate(t, "partner", ca, caKey)
+
+	cfg := &env.Server{
+		TLSCertFile:     filepath.Join(dir, "server.pem"),
+		TLSKeyFile:      filepath.Join(dir, "server.key"),
+		TLSClientCAFile: filepath.Join(dir, "ca.pem"),
+		TLSMinVersion:   "1.2",
+		TLSClientAuth:   "require-and-verify",
+	}
+	reloader, err := certificate.NewReloader(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	e := echo.New()
+	e.Use(certificate.HTTPClientCertificate())
+	e.GET("/identity", func(c echo.Context) error {
+		identity := certificate.IdentityFromContext(c.Request().Context())
+		if identity == nil {
+			return c.String(http.StatusOK, "anonymous")
+		}
+		return c.String(http.StatusOK, identity.Key())
+	})
+	srv := httptest.NewUnstartedServer(e)
+	srv.TLS = reloader.TLSConfig()
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	testCases := map[string]struct {
+		clientCertificates []tls.Certificate
+		expectedBody       string
+		expectedError      bool
+	}{
+		"client_without_certificate": {
+			expectedError: true,
+		},
+		"client_with_verified_certificate": {
+			clientCertificates: []tls.Certificate{{Certificate: [][]byte{client.Raw}, PrivateKey: clientKey}},
+			expectedBody:       "cn:partner",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				ServerName:   "localhost",
+				Certificates: tc.clientCertificates,
+			}}}
+			res, err := httpClient.Get(srv.URL + "/identity")
+			if tc.expectedError && err == nil {
+				t.Fatalf("got nil error expecting a handshake error")
+			}
+			if tc.expectedError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			if string(body) != tc.expectedBody {
+				t.Fatalf("unexpected body, got: %v, expected: %v", string(body), tc.expectedBody)
+			}
+		})
+	}
+
+	t.Run("server_certificate_is_reloaded", func(t *testing.T) {
+		rotated, rotatedKey := newTestCertificate(t, "localhost", ca, caKey)
+		writeTestCertificate(t, filepath.Join(dir, "server.pem"), rotated, rotatedKey)
+		if err := reloader.Reload(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: []tls.Certificate{{Certificate: [][]byte{client.Raw}, PrivateKey: clientKey}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer conn.Close()
+
+		got := conn.ConnectionState().PeerCertificates[0].SerialNumber
+		if got.Cmp(rotated.SerialNumber) != 0 {
+			t.Fatalf("unexpected server certificate, got serial: %v, expected: %v", got, rotated.SerialNumber)
+		}
+	})
+}
+
+// newTestCertificate creates a certificate signed by the parent, a self-signed CA when the parent is nil
+func newTestCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cert, key
+}
+
+// writeTestCertificate writes the certificate in a PEM file and the key next to it with the .key extension
+func writeTestCertificate(t *testing.T, file string, cert *x509.Certificate, key *ecdsa.PrivateKey) {
+	t.Helper()
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := os.WriteFile(file, content, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key == nil {
+		return
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keyContent := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	keyFile := file[:len(file)-len(filepath.Ext(file))] + ".key"
+	if err := os.WriteFile(keyFile, keyContent, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -2,12 +2,28 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"credit-line/cmd/credit-line-api/bootstrap"
 )
 
 func main() {
-	if err := bootstrap.Run(); err != nil {
+	args := os.Args[1:]
+
+	var err error
+	switch {
+	case len(args) >= 2 && args[0] == "config" && args[1] == "print":
+		err = bootstrap.PrintConfig(args[2:], os.Stdout)
+	case len(args) >= 2 && args[0] == "audit" && args[1] == "verify":
+		err = bootstrap.VerifyAudit(args[2:], os.Stdout)
+	case len(args) >= 2 && args[0] == "keys" && args[1] == "rotate":
+		err = bootstrap.RotateKeys(args[2:], os.Stdout)
+	case len(args) >= 2 && args[0] == "retention" && args[1] == "purge":
+		err = bootstrap.PurgeRetention(args[2:], os.Stdout)
+	default:
+		err = bootstrap.Run(args)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -2,8 +2,13 @@ package calculator
 
 import (
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
 	"credit-line/pkg/env"
 	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/tracing"
 )
 
 const (
@@ -18,13 +23,18 @@ type CreditLineCalculator interface {
 	CalculateCreditLine(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error)
 }
 
+// RatiosProvider contract that retrieves the current ratios, the ratios can change between calculations
+type RatiosProvider interface {
+	Ratios() *env.Ratios
+}
+
 // creditLine struct that implement the CreditLineCalculator interface
 type creditLine struct {
-	ratios *env.Ratios
+	ratios RatiosProvider
 }
 
 // NewCreditLine creates a new pointer of CreditLine struct
-func NewCreditLine(ratios *env.Ratios) *creditLine {
+func NewCreditLine(ratios RatiosProvider) *creditLine {
 	return &creditLine{
 		ratios: ratios,
 	}
@@ -32,13 +42,23 @@ func NewCreditLine(ratios *env.Ratios) *creditLine {
 
 // CalculateCreditLine implement the interface CreditLineCalculator.CalculateCreditLine
 func (cl *creditLine) CalculateCreditLine(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+	ctx, span := tracing.Start(ctx, "calculator.CalculateCreditLine",
+		attribute.String("credit.founding_type", foundingType))
+	defer span.End()
+
+	logger.FromContext(ctx).DebugContext(ctx, "calculating credit line", "founding_type", foundingType)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	ratios := cl.ratios.Ratios()
 	switch foundingType {
 	case SME_FOUNDING_TYPE:
-		amount := cashBalance / cl.ratios.CashBalance
+		amount := cashBalance / ratios.CashBalance
 		return amount, nil
 	case STARTUP_FOUNDING_TYPE:
-		amountCb := cashBalance / cl.ratios.CashBalance
-		amountMr := monthlyRevenue / cl.ratios.MonthlyRevenue
+		amountCb := cashBalance / ratios.CashBalance
+		amountMr := monthlyRevenue / ratios.MonthlyRevenue
 		if amountCb > amountMr {
 			return amountCb, nil
 		}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"credit-line/pkg/env"
 	"credit-line/pkg/errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -88,7 +90,7 @@ func Test_Calculate_Credit_Line_Calculator(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			calculator := NewCreditLine(ratios)
+			calculator := NewCreditLine(env.NewHolder("", &env.Environment{Ratio: ratios}))
 			got, err := calculator.CalculateCreditLine(tc.params.ctx, tc.params.foundingType,
 				tc.params.cashBalance, tc.params.monthlyRevenue)
 
@@ -110,3 +112,58 @@ func Test_Calculate_Credit_Line_Calculator(t *testing.T) {
 		})
 	}
 }
+
+func Test_Calculate_Credit_Line_Calculator_Reloaded_Ratios(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(file, []byte("CASH_BALANCE_RATIO=4\nMONTHLY_REVENUE_RATIO=5\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	holder := env.NewHolder(file, &env.Environment{Ratio: ratios})
+	calculator := NewCreditLine(holder)
+
+	testCases := map[string]struct {
+		config               string
+		expectedLineOfCredit float64
+		expectedError        bool
+	}{
+		"new_ratio_is_applied": {
+			config:               "CASH_BALANCE_RATIO=4\nMONTHLY_REVENUE_RATIO=5\n",
+			expectedLineOfCredit: 100,
+		},
+		"zero_ratio_is_rejected": {
+			config:               "CASH_BALANCE_RATIO=0\nMONTHLY_REVENUE_RATIO=5\n",
+			expectedLineOfCredit: 100,
+			expectedError:        true,
+		},
+		"invalid_ratio_is_rejected": {
+			config:               "CASH_BALANCE_RATIO=abc\nMONTHLY_REVENUE_RATIO=5\n",
+			expectedLineOfCredit: 100,
+			expectedError:        true,
+		},
+	}
+
+	for _, name := range []string{"new_ratio_is_applied", "zero_ratio_is_rejected", "invalid_ratio_is_rejected"} {
+		tc := testCases[name]
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(file, []byte(tc.config), 0o600); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err := holder.Reload()
+			if tc.expectedError && err == nil {
+				t.Fatalf("got nil error expecting a config error")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := calculator.CalculateCreditLine(context.Background(), SME_FOUNDING_TYPE, 400, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectedLineOfCredit, got) {
+				t.Fatalf("unexpected result, got: %v, expected: %v", got, tc.expectedLineOfCredit)
+			}
+		})
+	}
+}
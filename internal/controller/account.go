@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+)
+
+// AccountHandler struct that contains the service for the credit line accounts
+type AccountHandler struct {
+	service service.AccountService
+}
+
+// AccountRequest struct that represents the request to accept an approved credit line and open its account
+type AccountRequest struct {
+	DecisionID string `json:"decisionId" validate:"required,max=64"`
+}
+
+// MovementRequest struct that represents the request of a drawdown or a repayment, the retries of a request with
+// the same reference do not post the movement again
+type MovementRequest struct {
+	Amount    float64 `json:"amount" validate:"required,gte=0.01"`
+	Reference string  `json:"reference" validate:"omitempty,max=64"`
+}
+
+// NewAccountHandler creates a new pointer of AccountHandler struct
+func NewAccountHandler(service service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		service: service,
+	}
+}
+
+// OpenAccount invokes the echo handler to accept an approved credit line of the client ip and open its account
+func (ah *AccountHandler) OpenAccount(c echo.Context) error {
+	var request AccountRequest
+	if err := ah.bind(c, &request); err != nil {
+		return err
+	}
+
+	opened, err := ah.service.Open(c.Request().Context(), c.RealIP(), request.DecisionID)
+	if err != nil {
+		return ah.domainError(c, err)
+	}
+	return c.JSON(http.StatusCreated, opened)
+}
+
+// Authorize reports if the token authenticates the requests of the account of the id
+func (ah *AccountHandler) Authorize(c echo.Context, id, token string) bool {
+	return ah.service.Authorize(c.Request().Context(), id, token)
+}
+
+// Account invokes the echo handler to retrieve an account with its limit, outstanding and available balances
+func (ah *AccountHandler) Account(c echo.Context) error {
+	opened, err := ah.service.Account(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return ah.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, opened)
+}
+
+// Accounts invokes the echo handler to list the accounts, newest first
+func (ah *AccountHandler) Accounts(c echo.Context) error {
+	return c.JSON(http.StatusOK, ah.service.Accounts(c.Request().Context()))
+}
+
+// Drawdown invokes the echo handler to draw an amount of the available balance of an account
+func (ah *AccountHandler) Drawdown(c echo.Context) error {
+	return ah.move(c, model.Drawdown)
+}
+
+// Repayment invokes the echo handler to repay an amount of the outstanding balance of an account
+func (ah *AccountHandler) Repayment(c echo.Context) error {
+	return ah.move(c, model.Repayment)
+}
+
+// Movements invokes the echo handler to list the movements of an account, from the oldest to the newest
+func (ah *AccountHandler) Movements(c echo.Context) error {
+	movements, err := ah.service.Movements(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return ah.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, movements)
+}
+
+// CloseAccount invokes the echo handler to close an account without outstanding balance
+func (ah *AccountHandler) CloseAccount(c echo.Context) error {
+	closed, err := ah.service.Close(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return ah.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, closed)
+}
+
+// LedgerCheck invokes the echo handler to check the consistency of the ledger and the accounts
+func (ah *AccountHandler) LedgerCheck(c echo.Context) error {
+	return c.JSON(http.StatusOK, ah.service.Check(c.Request().Context()))
+}
+
+// move binds a movement request and applies it to an account, a retried movement responds 200 instead of 201
+func (ah *AccountHandler) move(c echo.Context, movementType model.MovementType) error {
+	var request MovementRequest
+	if err := ah.bind(c, &request); err != nil {
+		return err
+	}
+
+	movement, created, err := ah.service.Move(c.Request().Context(), c.Param("id"), movementType,
+		model.NewCents(request.Amount), request.Reference)
+	if err != nil {
+		return ah.domainError(c, err)
+	}
+	if !created {
+		return c.JSON(http.StatusOK, movement)
+	}
+	return c.JSON(http.StatusCreated, movement)
+}
+
+// bind binds and validates a request to an echo error
+func (ah *AccountHandler) bind(c echo.Context, request any) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+
+	if err := c.Bind(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+	return nil
+}
+
+// domainError maps an error of the account service to an echo error
+func (ah *AccountHandler) domainError(c echo.Context, err error) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+	errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+	return echo.NewHTTPError(code, errResponse).SetInternal(err)
+}
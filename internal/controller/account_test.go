@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pv "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/validator"
+)
+
+type mockAccountService struct {
+	open    func(ctx context.Context, ip, decisionID string) (*model.Account, error)
+	account func(ctx context.Context, id string) (*model.Account, error)
+	move    func(ctx context.Context, id string, movementType model.MovementType, amount model.Cents, reference string) (*model.Movement, bool, error)
+	close   func(ctx context.Context, id string) (*model.Account, error)
+}
+
+func (mas *mockAccountService) Open(ctx context.Context, ip, decisionID string) (*model.Account, error) {
+	return mas.open(ctx, ip, decisionID)
+}
+
+func (mas *mockAccountService) Authorize(ctx context.Context, id, token string) bool {
+	return token == "t0k3n"
+}
+
+func (mas *mockAccountService) Account(ctx context.Context, id string) (*model.Account, error) {
+	return mas.account(ctx, id)
+}
+
+func (mas *mockAccountService) Accounts(ctx context.Context) []*model.Account {
+	return nil
+}
+
+func (mas *mockAccountService) Move(ctx context.Context, id string, movementType model.MovementType, amount model.Cents,
+	reference string) (*model.Movement, bool, error) {
+	return mas.move(ctx, id, movementType, amount, reference)
+}
+
+func (mas *mockAccountService) Movements(ctx context.Context, id string) ([]*model.Movement, error) {
+	return nil, nil
+}
+
+func (mas *mockAccountService) Close(ctx context.Context, id string) (*model.Account, error) {
+	return mas.close(ctx, id)
+}
+
+func (mas *mockAccountService) Check(ctx context.Context) *model.LedgerCheck {
+	return &model.LedgerCheck{Consistent: true}
+}
+
+func Test_Account_Controller(t *testing.T) {
+	openAccount := &model.Account{ID: "3f2a", DecisionID: "9c1d", Status: model.AccountOpen, Limit: 14510,
+		Outstanding: 10000, Available: 4510}
+	drawdown := func(ctx context.Context, id string, movementType model.MovementType, amount model.Cents,
+		reference string) (*model.Movement, bool, error) {
+		if id != "3f2a" || movementType != model.Drawdown || amount != 10000 {
+			return nil, false, fmt.Errorf("unexpected movement %s %s %s", id, movementType, amount)
+		}
+		return &model.Movement{ID: "m1", AccountID: id, Type: movementType, Amount: amount, Reference: reference,
+			Outstanding: amount}, reference != "retried", nil
+	}
+
+	testCases := map[string]struct {
+		service            *mockAccountService
+		handler            func(ah *AccountHandler) echo.HandlerFunc
+		request            string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"open_account": {
+			service: &mockAccountService{
+				open: func(ctx context.Context, ip, decisionID string) (*model.Account, error) {
+					if ip != "192.0.2.1" {
+						return nil, fmt.Errorf("%w %q", errors.ErrDecisionNotFound, decisionID)
+					}
+					return &model.Account{ID: "3f2a", DecisionID: decisionID, Status: model.AccountOpen, Limit: 14510,
+						Available: 14510, Token: "t0k3n"}, nil
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.OpenAccount },
+			request:            `{"decisionId": "9c1d"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `"limit":"145.10","outstanding":"0.00","available":"145.10","openedAt":"0001-01-01T00:00:00Z","token":"t0k3n"`,
+		},
+		"open_account_without_decision": {
+			service:            &mockAccountService{},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.OpenAccount },
+			request:            `{}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"required"`,
+		},
+		"open_account_not_approved": {
+			service: &mockAccountService{
+				open: func(ctx context.Context, ip, decisionID string) (*model.Account, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrDecisionNotApproved, decisionID)
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.OpenAccount },
+			request:            `{"decisionId": "9c1d"}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedBody:       `"code":"DECISION_NOT_APPROVED"`,
+		},
+		"account": {
+			service: &mockAccountService{
+				account: func(ctx context.Context, id string) (*model.Account, error) {
+					return openAccount, nil
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Account },
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"available":"45.10"`,
+		},
+		"account_not_found": {
+			service: &mockAccountService{
+				account: func(ctx context.Context, id string) (*model.Account, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrAccountNotFound, id)
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Account },
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+		"drawdown": {
+			service:            &mockAccountService{move: drawdown},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Drawdown },
+			request:            `{"amount": 100, "reference": "d1"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `"amount":"100.00"`,
+		},
+		"drawdown_retried": {
+			service:            &mockAccountService{move: drawdown},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Drawdown },
+			request:            `{"amount": 100, "reference": "retried"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"reference":"retried"`,
+		},
+		"drawdown_below_a_cent": {
+			service:            &mockAccountService{},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Drawdown },
+			request:            `{"amount": 0.001}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"gte"`,
+		},
+		"drawdown_above_available": {
+			service: &mockAccountService{
+				move: func(ctx context.Context, id string, movementType model.MovementType, amount model.Cents,
+					reference string) (*model.Movement, bool, error) {
+					return nil, false, fmt.Errorf("%w: %s of %s", errors.ErrInsufficientAvailable, amount, model.Cents(4510))
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Drawdown },
+			request:            `{"amount": 50}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedBody:       `"code":"INSUFFICIENT_AVAILABLE_BALANCE"`,
+		},
+		"repayment_above_outstanding": {
+			service: &mockAccountService{
+				move: func(ctx context.Context, id string, movementType model.MovementType, amount model.Cents,
+					reference string) (*model.Movement, bool, error) {
+					if movementType != model.Repayment {
+						return nil, false, fmt.Errorf("unexpected movement type %s", movementType)
+					}
+					return nil, false, fmt.Errorf("%w: %s of %s", errors.ErrRepaymentExceedsOutstanding, amount, model.Cents(10000))
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.Repayment },
+			request:            `{"amount": 150}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedBody:       `"code":"REPAYMENT_EXCEEDS_OUTSTANDING"`,
+		},
+		"close_account_with_outstanding": {
+			service: &mockAccountService{
+				close: func(ctx context.Context, id string) (*model.Account, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrAccountOutstanding, id)
+				},
+			},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.CloseAccount },
+			expectedStatusCode: http.StatusConflict,
+			expectedBody:       `"code":"ACCOUNT_OUTSTANDING"`,
+		},
+		"ledger_check": {
+			service:            &mockAccountService{},
+			handler:            func(ah *AccountHandler) echo.HandlerFunc { return ah.LedgerCheck },
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"consistent":true`,
+		},
+	}
+
+	e := echo.New()
+	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tc.request))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("3f2a")
+
+			handler := tc.handler(NewAccountHandler(tc.service))
+			if err := handler(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+)
+
+// AuditHandler struct that contains the service for the audit log of the decisions
+type AuditHandler struct {
+	service service.AuditService
+}
+
+// NewAuditHandler creates a new pointer of AuditHandler struct
+func NewAuditHandler(service service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// DecisionProof invokes the echo handler to retrieve the inclusion proof of a decision in the audit log
+func (ah *AuditHandler) DecisionProof(c echo.Context) error {
+	proof, err := ah.service.Proof(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+		errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+	return c.JSON(http.StatusOK, proof)
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+)
+
+type mockAuditService struct {
+	proof func(ctx context.Context, decisionID string) (*model.InclusionProof, error)
+}
+
+func (mas *mockAuditService) Proof(ctx context.Context, decisionID string) (*model.InclusionProof, error) {
+	return mas.proof(ctx, decisionID)
+}
+
+func Test_Audit_Controller(t *testing.T) {
+	testCases := map[string]struct {
+		service            *mockAuditService
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"decision_proof": {
+			service: &mockAuditService{
+				proof: func(ctx context.Context, decisionID string) (*model.InclusionProof, error) {
+					return &model.InclusionProof{
+						Entry:     model.AuditEntry{Index: 2, DecisionID: decisionID},
+						LeafIndex: 2,
+						TreeSize:  3,
+						RootHash:  "9a1c",
+						AuditPath: []string{"5be0"},
+					}, nil
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"decisionId":"7c1e"`,
+		},
+		"decision_not_audited": {
+			service: &mockAuditService{
+				proof: func(ctx context.Context, decisionID string) (*model.InclusionProof, error) {
+					return nil, fmt.Errorf("%w: %s", errors.ErrAuditEntryNotFound, decisionID)
+				},
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("7c1e")
+
+			handler := NewAuditHandler(tc.service)
+			if err := handler.DecisionProof(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
 
 	"credit-line/internal/model"
 	"credit-line/internal/service"
 	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+	"credit-line/pkg/tracing"
 )
 
 // CreditLineHandler struct that contains the service for the CreditLine entity
@@ -17,11 +20,29 @@ type CreditLineHandler struct {
 
 // CreditLineRequest struct that represents the CreditLine request
 type CreditLineRequest struct {
-	FoundingType        string  `json:"foundingType" validate:"required"`
-	CashBalance         float64 `json:"cashBalance" validate:"required"`
-	MonthlyRevenue      float64 `json:"monthlyRevenue" validate:"required"`
-	RequestedCreditLine float64 `json:"requestedCreditLine" validate:"required"`
-	RequestedDate       string  `json:"requestedDate" validate:"required"`
+	FoundingType        string            `json:"foundingType" validate:"required"`
+	CashBalance         float64           `json:"cashBalance" validate:"required"`
+	MonthlyRevenue      float64           `json:"monthlyRevenue" validate:"required"`
+	RequestedCreditLine float64           `json:"requestedCreditLine" validate:"required"`
+	RequestedDate       string            `json:"requestedDate" validate:"required"`
+	Contact             *ContactRequest   `json:"contact,omitempty"`
+	Applicant           *ApplicantRequest `json:"applicant,omitempty"`
+}
+
+// ContactRequest struct that represents the optional contact data of the applicant, it is sent to the
+// sales team when the decline retries are exhausted
+type ContactRequest struct {
+	Name  string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Email string `json:"email,omitempty" validate:"omitempty,email,max=254"`
+	Phone string `json:"phone,omitempty" validate:"omitempty,e164"`
+}
+
+// ApplicantRequest struct that represents the optional business identifiers of the applicant, the decline
+// retries of an identified applicant are counted by applicant instead of by ip
+type ApplicantRequest struct {
+	TaxID     string `json:"taxId,omitempty" validate:"omitempty,rfc"`
+	LegalName string `json:"legalName,omitempty" validate:"omitempty,max=150"`
+	Email     string `json:"email,omitempty" validate:"omitempty,email,max=254"`
 }
 
 // NewCreditLineHandler creates a new pointer of CreditLineHandler struct
@@ -34,24 +55,49 @@ func NewCreditLineHandler(service service.CreditLineService) *CreditLineHandler
 // CreditLine invokes the echo handler to calculate the credit line
 func (clh *CreditLineHandler) CreditLine(c echo.Context) error {
 	var request CreditLineRequest
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
 
 	if err := c.Bind(&request); err != nil {
-		errResponse, _ := errors.MapError(err, errors.UnmarshallErr)
-		return c.JSON(http.StatusBadRequest, errResponse)
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
 	}
 
 	if err := c.Validate(request); err != nil {
-		errResponse, _ := errors.MapError(err, errors.ValidationErr)
-		return c.JSON(http.StatusBadRequest, errResponse)
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
 	}
 
+	ctx, span := tracing.Start(c.Request().Context(), "controller.CreditLine",
+		attribute.String("credit.founding_type", request.FoundingType))
+	defer span.End()
+
 	creditLine := model.NewCreditLine(request.FoundingType, request.RequestedDate,
 		request.CashBalance, request.MonthlyRevenue, request.RequestedCreditLine)
+	creditLine.SetContact(request.Contact.model())
+	creditLine.SetIdentifiers(request.Applicant.model())
 
-	creditLineResponse, err := clh.service.DetermineCreditLimit(c.Request().Context(), c.RealIP(), creditLine)
+	creditLineResponse, err := clh.service.DetermineCreditLimit(ctx, c.RealIP(), creditLine)
 	if err != nil {
-		errResponse, code := errors.MapError(err, errors.DomainErr)
-		return c.JSON(code, errResponse)
+		span.RecordError(err)
+		errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
 	}
+	span.SetAttributes(attribute.String("credit.status", string(creditLineResponse.CreditStatus)))
 	return c.JSON(http.StatusOK, creditLineResponse)
 }
+
+// model retrieves the contact of the request as a model, nil when the applicant did not send contact data
+func (cr *ContactRequest) model() *model.Contact {
+	if cr == nil || (cr.Name == "" && cr.Email == "" && cr.Phone == "") {
+		return nil
+	}
+	return &model.Contact{Name: cr.Name, Email: cr.Email, Phone: cr.Phone}
+}
+
+// model retrieves the identifiers of the request as a model, nil when the applicant did not send identifiers
+func (ar *ApplicantRequest) model() *model.ApplicantIdentifiers {
+	if ar == nil || (ar.TaxID == "" && ar.LegalName == "" && ar.Email == "") {
+		return nil
+	}
+	return &model.ApplicantIdentifiers{TaxID: ar.TaxID, LegalName: ar.LegalName, Email: ar.Email}
+}
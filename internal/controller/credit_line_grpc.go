@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+
+	creditlinev1 "credit-line/api/creditline/v1"
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/middleware"
+	"credit-line/pkg/tracing"
+)
+
+// creditStatuses variable to identify the gRPC credit status of a credit status
+var creditStatuses = map[model.CreditStatus]creditlinev1.CreditStatus{
+	model.Approved:      creditlinev1.CreditStatus_CREDIT_STATUS_APPROVED,
+	model.Declined:      creditlinev1.CreditStatus_CREDIT_STATUS_DECLINED,
+	model.PendingReview: creditlinev1.CreditStatus_CREDIT_STATUS_PENDING_REVIEW,
+}
+
+// CreditLineGRPCHandler struct that contains the service for the CreditLine entity in the gRPC server
+type CreditLineGRPCHandler struct {
+	creditlinev1.UnimplementedCreditLineServiceServer
+	service   service.CreditLineService
+	validator echo.Validator
+}
+
+// NewCreditLineGRPCHandler creates a new pointer of CreditLineGRPCHandler struct
+func NewCreditLineGRPCHandler(service service.CreditLineService, validator echo.Validator) *CreditLineGRPCHandler {
+	return &CreditLineGRPCHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+// DetermineCreditLimit implement the interface creditlinev1.CreditLineServiceServer.DetermineCreditLimit
+func (clh *CreditLineGRPCHandler) DetermineCreditLimit(ctx context.Context, req *creditlinev1.DetermineCreditLimitRequest) (*creditlinev1.DetermineCreditLimitResponse, error) {
+	trans := middleware.GRPCTranslator(ctx)
+	request := CreditLineRequest{
+		FoundingType:        req.GetFoundingType(),
+		CashBalance:         req.GetCashBalance(),
+		MonthlyRevenue:      req.GetMonthlyRevenue(),
+		RequestedCreditLine: req.GetRequestedCreditLine(),
+		RequestedDate:       req.GetRequestedDate(),
+	}
+	if contact := req.GetContact(); contact != nil {
+		request.Contact = &ContactRequest{
+			Name:  contact.GetName(),
+			Email: contact.GetEmail(),
+			Phone: contact.GetPhone(),
+		}
+	}
+	if applicant := req.GetApplicant(); applicant != nil {
+		request.Applicant = &ApplicantRequest{
+			TaxID:     applicant.GetTaxId(),
+			LegalName: applicant.GetLegalName(),
+			Email:     applicant.GetEmail(),
+		}
+	}
+
+	if err := clh.validator.Validate(request); err != nil {
+		return nil, errors.MapGRPCError(err, errors.ValidationErr, trans)
+	}
+
+	ctx, span := tracing.Start(ctx, "controller.DetermineCreditLimit",
+		attribute.String("credit.founding_type", request.FoundingType))
+	defer span.End()
+
+	creditLine := model.NewCreditLine(request.FoundingType, request.RequestedDate,
+		request.CashBalance, request.MonthlyRevenue, request.RequestedCreditLine)
+	creditLine.SetContact(request.Contact.model())
+	creditLine.SetIdentifiers(request.Applicant.model())
+
+	creditLineResponse, err := clh.service.DetermineCreditLimit(ctx, middleware.GRPCRealIP(ctx), creditLine)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.MapGRPCError(err, errors.DomainErr, trans)
+	}
+	span.SetAttributes(attribute.String("credit.status", string(creditLineResponse.CreditStatus)))
+	return &creditlinev1.DetermineCreditLimitResponse{
+		CreditStatus:         creditStatuses[creditLineResponse.CreditStatus],
+		CreditLineAuthorized: creditLineResponse.CreditLineAuthorized,
+		LeadId:               creditLineResponse.LeadID,
+		ReviewId:             creditLineResponse.ReviewID,
+		DecisionId:           creditLineResponse.DecisionID,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	pv "github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	creditlinev1 "credit-line/api/creditline/v1"
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/validator"
+)
+
+func Test_Determine_Credit_Limit_GRPC_Controller(t *testing.T) {
+	testCases := map[string]struct {
+		service          service.CreditLineService
+		ctx              context.Context
+		request          *creditlinev1.DetermineCreditLimitRequest
+		expectedResponse *creditlinev1.DetermineCreditLimitResponse
+		expectedCode     codes.Code
+		expectedMessage  string
+	}{
+		"validation_error": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			ctx: context.Background(),
+			request: &creditlinev1.DetermineCreditLimitRequest{
+				CashBalance:         435.30,
+				RequestedCreditLine: 100,
+				RequestedDate:       "2021-07-19T16:32:59.860Z",
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "malformed request, please check the following parameters in the request: [foundingType, monthlyRevenue]",
+		},
+		"credit_line_could_not_be_determined_spanish": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, fmt.Errorf("determination failed: %w", errors.ErrInvalidFoundingType)
+				},
+			},
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "es-MX")),
+			request: &creditlinev1.DetermineCreditLimitRequest{
+				FoundingType:        "SMA",
+				CashBalance:         435.30,
+				MonthlyRevenue:      4235.45,
+				RequestedCreditLine: 100,
+				RequestedDate:       "2021-07-19T16:32:59.860Z",
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "foundingType inválido",
+		},
+		"credit_line_approved": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return model.NewCreditLineResponse(model.Approved, "145.10"), nil
+				},
+			},
+			ctx: context.Background(),
+			request: &creditlinev1.DetermineCreditLimitRequest{
+				FoundingType:        "SME",
+				CashBalance:         435.30,
+				MonthlyRevenue:      4235.45,
+				RequestedCreditLine: 100,
+				RequestedDate:       "2021-07-19T16:32:59.860Z",
+			},
+			expectedResponse: &creditlinev1.DetermineCreditLimitResponse{
+				CreditStatus:         creditlinev1.CreditStatus_CREDIT_STATUS_APPROVED,
+				CreditLineAuthorized: "145.10",
+			},
+			expectedCode: codes.OK,
+		},
+		"credit_line_declined": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return model.NewCreditLineResponse(model.Declined, "0.00"), nil
+				},
+			},
+			ctx: context.Background(),
+			request: &creditlinev1.DetermineCreditLimitRequest{
+				FoundingType:        "SME",
+				CashBalance:         435.30,
+				MonthlyRevenue:      4235.45,
+				RequestedCreditLine: 1000,
+				RequestedDate:       "2021-07-19T16:32:59.860Z",
+			},
+			expectedResponse: &creditlinev1.DetermineCreditLimitResponse{
+				CreditStatus:         creditlinev1.CreditStatus_CREDIT_STATUS_DECLINED,
+				CreditLineAuthorized: "0.00",
+			},
+			expectedCode: codes.OK,
+		},
+		"credit_line_declined_lead_captured": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					if contact := creditLine.Contact(); contact == nil || contact.Email != "owner@example.com" {
+						return nil, fmt.Errorf("unexpected contact %v", contact)
+					}
+					return &model.CreditLineResponse{CreditStatus: model.Declined, CreditLineAuthorized: "0.00", LeadID: "3f2a"}, nil
+				},
+			},
+			ctx: context.Background(),
+			request: &creditlinev1.DetermineCreditLimitRequest{
+				FoundingType:        "SME",
+				CashBalance:         435.30,
+				MonthlyRevenue:      4235.45,
+				RequestedCreditLine: 1000,
+				RequestedDate:       "2021-07-19T16:32:59.860Z",
+				Contact:             &creditlinev1.Contact{Name: "Owner", Email: "owner@example.com"},
+			},
+			expectedResponse: &creditlinev1.DetermineCreditLimitResponse{
+				CreditStatus:         creditlinev1.CreditStatus_CREDIT_STATUS_DECLINED,
+				CreditLineAuthorized: "0.00",
+				LeadId:               "3f2a",
+			},
+			expectedCode: codes.OK,
+		},
+		"invalid_contact_email": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			ctx: context.Background(),
+			request: &creditlinev1.DetermineCreditLimitRequest{
+				FoundingType:        "SME",
+				CashBalance:         435.30,
+				MonthlyRevenue:      4235.45,
+				RequestedCreditLine: 1000,
+				RequestedDate:       "2021-07-19T16:32:59.860Z",
+				Contact:             &creditlinev1.Contact{Email: "owner"},
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "malformed request, please check the following parameters in the request: [email]",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := NewCreditLineGRPCHandler(tc.service, validator.New(pv.New()))
+			got, err := handler.DetermineCreditLimit(tc.ctx, tc.request)
+
+			st := status.Convert(err)
+			if tc.expectedCode != st.Code() {
+				t.Fatalf("unexpected code, got: %v, expected: %v", st.Code(), tc.expectedCode)
+			}
+
+			if tc.expectedMessage != st.Message() {
+				t.Fatalf("unexpected message, got: %v, expected: %v", st.Message(), tc.expectedMessage)
+			}
+
+			if tc.expectedResponse != nil && !proto.Equal(tc.expectedResponse, got) {
+				t.Fatalf("unexpected result, got: %v, expected: %v", got, tc.expectedResponse)
+			}
+
+			if tc.expectedResponse == nil && !reflect.ValueOf(got).IsNil() {
+				t.Fatalf("unexpected result, got: %v, expected: nil", got)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"credit-line/internal/model"
 	"credit-line/internal/service"
 	"credit-line/pkg/errors"
+	"credit-line/pkg/pii"
 	"credit-line/pkg/validator"
 )
 
@@ -30,7 +31,10 @@ func (mcls *mockCreditLineService) DetermineCreditLimit(ctx context.Context, ip
 func Test_Determine_Credit_Limit_Controller(t *testing.T) {
 	testCases := map[string]struct {
 		service            service.CreditLineService
+		accept             string
+		acceptLanguage     string
 		request            []byte
+		bodyLimit          int64
 		expectedBody       interface{}
 		expectedStatusCode int
 	}{
@@ -48,12 +52,44 @@ func Test_Determine_Credit_Limit_Controller(t *testing.T) {
 				"requestedDate": "2021-07-19T16:32:59.860Z"
 			}`),
 
-			expectedBody: errors.ApiResponse{
-				Message: "unmarshal error data type, got: string, expected: number in cashBalance param",
-				Code:    "INVALID_REQUEST",
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/invalid-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "unmarshal error data type, got: string, expected: number in cashBalance param",
+				Instance: "/",
+				Code:     "INVALID_REQUEST",
+				Errors: []errors.FieldError{
+					{Field: "cashBalance", Rule: "type", Value: "string", Message: "cashBalance must be a number, got: string"},
+				},
 			},
 			expectedStatusCode: http.StatusBadRequest,
 		},
+		"request_body_too_large": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"monthlyRevenue": 4235.45,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z"
+			}`),
+			bodyLimit: 32,
+
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/payload-too-large",
+				Title:    "Request Entity Too Large",
+				Status:   http.StatusRequestEntityTooLarge,
+				Detail:   "the request body is too large",
+				Instance: "/",
+				Code:     "PAYLOAD_TOO_LARGE",
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
 		"validation_error": {
 			service: &mockCreditLineService{
 				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
@@ -66,12 +102,125 @@ func Test_Determine_Credit_Limit_Controller(t *testing.T) {
 				"requestedDate": "2021-07-19T16:32:59.860Z"
 			}`),
 
-			expectedBody: errors.ApiResponse{
-				Message: "malformed request, please check the following parameters in the request: [foundingType, monthlyRevenue]",
-				Code:    "INVALID_REQUEST",
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/invalid-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "malformed request, please check the following parameters in the request: [foundingType, monthlyRevenue]",
+				Instance: "/",
+				Code:     "INVALID_REQUEST",
+				Errors: []errors.FieldError{
+					{Field: "foundingType", Rule: "required", Value: "", Message: "foundingType is required"},
+					{Field: "monthlyRevenue", Rule: "required", Value: pii.Redacted, Message: "monthlyRevenue is required"},
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"validation_error_spanish": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			acceptLanguage: "es-MX,es;q=0.9,en;q=0.8",
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z"
+			}`),
+
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/invalid-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "solicitud mal formada, por favor revisa los siguientes parámetros de la solicitud: [monthlyRevenue]",
+				Instance: "/",
+				Code:     "INVALID_REQUEST",
+				Errors: []errors.FieldError{
+					{Field: "monthlyRevenue", Rule: "required", Value: pii.Redacted, Message: "monthlyRevenue es requerido"},
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"validation_error_redacted_contact": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"monthlyRevenue": 4235.45,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z",
+				"contact": {"email": "owner.example.com"}
+			}`),
+
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/invalid-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "malformed request, please check the following parameters in the request: [email]",
+				Instance: "/",
+				Code:     "INVALID_REQUEST",
+				Errors: []errors.FieldError{
+					{Field: "contact.email", Rule: "email", Value: pii.Redacted, Message: "email must be a valid email address"},
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"validation_error_invalid_tax_id": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"monthlyRevenue": 4235.45,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z",
+				"applicant": {"taxId": "ABC-123"}
+			}`),
+
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/invalid-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "malformed request, please check the following parameters in the request: [taxId]",
+				Instance: "/",
+				Code:     "INVALID_REQUEST",
+				Errors: []errors.FieldError{
+					{Field: "applicant.taxId", Rule: "rfc", Value: pii.Redacted, Message: "applicant.taxId must be a valid RFC"},
+				},
 			},
 			expectedStatusCode: http.StatusBadRequest,
 		},
+		"applicant_identifiers": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					expected := model.ApplicantIdentifiers{TaxID: "ABC010203XY9", LegalName: "Acme SA de CV", Email: "owner@acme.mx"}
+					if ids := creditLine.Identifiers(); ids == nil || *ids != expected {
+						return nil, fmt.Errorf("unexpected identifiers: %v", ids)
+					}
+					return model.NewCreditLineResponse(model.Approved, "145.10"), nil
+				},
+			},
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"monthlyRevenue": 4235.45,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z",
+				"applicant": {"taxId": "ABC010203XY9", "legalName": "Acme SA de CV", "email": "owner@acme.mx"}
+			}`),
+
+			expectedBody:       model.NewCreditLineResponse(model.Approved, "145.10"),
+			expectedStatusCode: http.StatusOK,
+		},
 		"credit_line_could_not_be_determined": {
 			service: &mockCreditLineService{
 				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
@@ -85,12 +234,60 @@ func Test_Determine_Credit_Limit_Controller(t *testing.T) {
 				"requestedCreditLine": 100,
 				"requestedDate": "2021-07-19T16:32:59.860Z"
 			}`),
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/invalid-request",
+				Title:    "Bad Request",
+				Status:   http.StatusBadRequest,
+				Detail:   "invalid foundingType",
+				Instance: "/",
+				Code:     "INVALID_REQUEST",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"credit_line_could_not_be_determined_legacy_response": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, fmt.Errorf("determination failed: %w", errors.ErrInvalidFoundingType)
+				},
+			},
+			accept: "application/json",
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"monthlyRevenue": 4235.45,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z"
+			}`),
 			expectedBody: errors.ApiResponse{
-				Message: "determination failed: invalid foundingType",
+				Message: "invalid foundingType",
 				Code:    "INVALID_REQUEST",
 			},
 			expectedStatusCode: http.StatusBadRequest,
 		},
+		"credit_line_determination_timed_out": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, fmt.Errorf("determination cancelled: %w", context.DeadlineExceeded)
+				},
+			},
+			request: []byte(`{
+				"foundingType": "SME",
+				"cashBalance": 435.30,
+				"monthlyRevenue": 4235.45,
+				"requestedCreditLine": 100,
+				"requestedDate": "2021-07-19T16:32:59.860Z"
+			}`),
+
+			expectedBody: errors.ProblemDetails{
+				Type:     "/problems/service-unavailable",
+				Title:    "Service Unavailable",
+				Status:   http.StatusServiceUnavailable,
+				Detail:   "the request could not be processed in time, please try again",
+				Instance: "/",
+				Code:     "SERVICE_UNAVAILABLE",
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
 		"credit_line_approved": {
 			service: &mockCreditLineService{
 				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
@@ -126,18 +323,29 @@ func Test_Determine_Credit_Limit_Controller(t *testing.T) {
 	}
 
 	e := echo.New()
+	// the translations are registered again by the second validator and the messages stay localized
+	validator.New(pv.New())
 	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(tc.request))
 			r.Header.Set("Content-Type", "application/json")
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			if tc.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
 			w := httptest.NewRecorder()
+			if tc.bodyLimit > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, tc.bodyLimit)
+			}
 			ctx := e.NewContext(r, w)
 
 			handler := NewCreditLineHandler(tc.service)
-			err := handler.CreditLine(ctx)
-			if err != nil {
-				t.Errorf("unexpected error, got: %v", err)
+			if err := handler.CreditLine(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
 			}
 
 			gotStatusCode := w.Code
@@ -145,19 +353,34 @@ func Test_Determine_Credit_Limit_Controller(t *testing.T) {
 				t.Errorf("unexpected status code, got: %v, expected: %v", gotStatusCode, tc.expectedStatusCode)
 			}
 
-			if name != "credit_line_approved" && name != "credit_line_declined" {
+			switch expectedBody := tc.expectedBody.(type) {
+			case errors.ProblemDetails:
+				if got := w.Header().Get("Content-Type"); got != errors.MIMEApplicationProblemJSON {
+					t.Errorf("unexpected content type, got: %v, expected: %v", got, errors.MIMEApplicationProblemJSON)
+				}
+
+				var gotBody errors.ProblemDetails
+				err := json.NewDecoder(w.Body).Decode(&gotBody)
+				if err != nil {
+					t.Errorf("unexpected unmarshall error, got: %v", err)
+				}
+
+				if !reflect.DeepEqual(expectedBody, gotBody) {
+					t.Errorf("unexpected response, got: %v, expected: %v", gotBody, expectedBody)
+				}
+			case errors.ApiResponse:
 				var gotBody errors.ApiResponse
-				err = json.NewDecoder(w.Body).Decode(&gotBody)
+				err := json.NewDecoder(w.Body).Decode(&gotBody)
 				if err != nil {
 					t.Errorf("unexpected unmarshall error, got: %v", err)
 				}
 
-				if !reflect.DeepEqual(tc.expectedBody, gotBody) {
-					t.Errorf("unexpected response, got: %v, expected: %v", gotBody, tc.expectedBody)
+				if !reflect.DeepEqual(expectedBody, gotBody) {
+					t.Errorf("unexpected response, got: %v, expected: %v", gotBody, expectedBody)
 				}
-			} else {
+			default:
 				var gotBody model.CreditLineResponse
-				err = json.NewDecoder(w.Body).Decode(&gotBody)
+				err := json.NewDecoder(w.Body).Decode(&gotBody)
 				if err != nil {
 					t.Errorf("unexpected unmarshall error, got: %v", err)
 				}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+)
+
+// LeadHandler struct that contains the service for the leads handed off to the sales team
+type LeadHandler struct {
+	service service.LeadService
+}
+
+// LeadStatusRequest struct that represents the request to update the status of a lead
+type LeadStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=CONTACTED CLOSED"`
+}
+
+// NewLeadHandler creates a new pointer of LeadHandler struct
+func NewLeadHandler(service service.LeadService) *LeadHandler {
+	return &LeadHandler{
+		service: service,
+	}
+}
+
+// LeadStatus invokes the echo handler to retrieve the status of a lead, it does not expose the contact data
+func (lh *LeadHandler) LeadStatus(c echo.Context) error {
+	lead, err := lh.service.Lead(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return lh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, model.NewLeadStatusResponse(lead))
+}
+
+// Leads invokes the echo handler to list the leads, newest first
+func (lh *LeadHandler) Leads(c echo.Context) error {
+	return c.JSON(http.StatusOK, lh.service.Leads(c.Request().Context()))
+}
+
+// Lead invokes the echo handler to retrieve a lead with its contact data and request history
+func (lh *LeadHandler) Lead(c echo.Context) error {
+	lead, err := lh.service.Lead(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return lh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, lead)
+}
+
+// UpdateLeadStatus invokes the echo handler to mark a lead as contacted or closed
+func (lh *LeadHandler) UpdateLeadStatus(c echo.Context) error {
+	var request LeadStatusRequest
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+
+	if err := c.Bind(&request); err != nil {
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	lead, err := lh.service.UpdateLeadStatus(c.Request().Context(), c.Param("id"), model.LeadStatus(request.Status))
+	if err != nil {
+		return lh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, lead)
+}
+
+// domainError maps an error of the lead service to an echo error
+func (lh *LeadHandler) domainError(c echo.Context, err error) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+	errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+	return echo.NewHTTPError(code, errResponse).SetInternal(err)
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pv "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/validator"
+)
+
+type mockLeadService struct {
+	lead             func(ctx context.Context, id string) (*model.Lead, error)
+	updateLeadStatus func(ctx context.Context, id string, status model.LeadStatus) (*model.Lead, error)
+}
+
+func (mls *mockLeadService) Capture(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string {
+	return ""
+}
+
+func (mls *mockLeadService) Release(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) {
+}
+
+func (mls *mockLeadService) Track(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead {
+	return nil
+}
+
+func (mls *mockLeadService) Lead(ctx context.Context, id string) (*model.Lead, error) {
+	return mls.lead(ctx, id)
+}
+
+func (mls *mockLeadService) Leads(ctx context.Context) []*model.Lead {
+	return nil
+}
+
+func (mls *mockLeadService) UpdateLeadStatus(ctx context.Context, id string, status model.LeadStatus) (*model.Lead, error) {
+	return mls.updateLeadStatus(ctx, id, status)
+}
+
+func Test_Lead_Controller(t *testing.T) {
+	contactedLead := &model.Lead{
+		ID:      "3f2a",
+		IP:      "10.0.0.1",
+		Status:  model.LeadContacted,
+		Contact: &model.Contact{Email: "owner@example.com"},
+	}
+
+	testCases := map[string]struct {
+		service            *mockLeadService
+		handler            func(lh *LeadHandler) echo.HandlerFunc
+		request            string
+		expectedStatusCode int
+		expectedBody       string
+		unexpectedBody     string
+	}{
+		"lead_status_without_contact": {
+			service: &mockLeadService{
+				lead: func(ctx context.Context, id string) (*model.Lead, error) {
+					return contactedLead, nil
+				},
+			},
+			handler:            func(lh *LeadHandler) echo.HandlerFunc { return lh.LeadStatus },
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"status":"CONTACTED"`,
+			unexpectedBody:     "owner@example.com",
+		},
+		"lead_status_not_found": {
+			service: &mockLeadService{
+				lead: func(ctx context.Context, id string) (*model.Lead, error) {
+					return nil, fmt.Errorf("%w: %s", errors.ErrLeadNotFound, id)
+				},
+			},
+			handler:            func(lh *LeadHandler) echo.HandlerFunc { return lh.LeadStatus },
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+		"lead_with_contact": {
+			service: &mockLeadService{
+				lead: func(ctx context.Context, id string) (*model.Lead, error) {
+					return contactedLead, nil
+				},
+			},
+			handler:            func(lh *LeadHandler) echo.HandlerFunc { return lh.Lead },
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"email":"owner@example.com"`,
+		},
+		"update_lead_status": {
+			service: &mockLeadService{
+				updateLeadStatus: func(ctx context.Context, id string, status model.LeadStatus) (*model.Lead, error) {
+					if id != "3f2a" || status != model.LeadClosed {
+						return nil, fmt.Errorf("unexpected status %s %s", id, status)
+					}
+					return &model.Lead{ID: id, Status: status}, nil
+				},
+			},
+			handler:            func(lh *LeadHandler) echo.HandlerFunc { return lh.UpdateLeadStatus },
+			request:            `{"status": "CLOSED"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"status":"CLOSED"`,
+		},
+		"update_lead_status_invalid": {
+			service:            &mockLeadService{},
+			handler:            func(lh *LeadHandler) echo.HandlerFunc { return lh.UpdateLeadStatus },
+			request:            `{"status": "NOTIFIED"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"oneof"`,
+		},
+	}
+
+	e := echo.New()
+	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", bytes.NewBufferString(tc.request))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("3f2a")
+
+			handler := tc.handler(NewLeadHandler(tc.service))
+			if err := handler(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+			if tc.unexpectedBody != "" && strings.Contains(w.Body.String(), tc.unexpectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to not contain: %v", w.Body.String(), tc.unexpectedBody)
+			}
+		})
+	}
+}
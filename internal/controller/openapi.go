@@ -0,0 +1,885 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/calculator"
+	"credit-line/internal/model"
+	"credit-line/pkg/access"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/openapi"
+)
+
+const (
+	// CreditLimitPath path of the endpoint to calculate the credit limit
+	CreditLimitPath = "/api/v1/credits/calculate/limit"
+	// AdminClientsPath path of the endpoint that lists the throttling state of the clients
+	AdminClientsPath = "/admin/clients"
+	// AdminClientPath path of the endpoints to inspect and reset the throttling state of a client
+	AdminClientPath = "/admin/clients/{ip}"
+	// AdminAllowancePath path of the endpoint to grant extra decline retries to a client
+	AdminAllowancePath = "/admin/clients/{ip}/allowance"
+	// AdminAccessListPath path of the endpoints to manage the allowed and blocked networks
+	AdminAccessListPath = "/admin/access-list"
+	// LeadStatusPath path of the endpoint that retrieves the status of a lead handed off to the sales team
+	LeadStatusPath = "/api/v1/credits/leads/{id}"
+	// AdminLeadsPath path of the endpoint that lists the leads handed off to the sales team
+	AdminLeadsPath = "/admin/leads"
+	// AdminLeadPath path of the endpoints to inspect a lead and update its status
+	AdminLeadPath = "/admin/leads/{id}"
+	// AdminWebhooksPath path of the endpoints to list and create the webhook subscriptions
+	AdminWebhooksPath = "/admin/webhooks"
+	// AdminWebhookPath path of the endpoint to remove a webhook subscription
+	AdminWebhookPath = "/admin/webhooks/{id}"
+	// AdminDeliveriesPath path of the endpoint that lists the deliveries of a webhook subscription
+	AdminDeliveriesPath = "/admin/webhooks/{id}/deliveries"
+	// AdminRedeliverPath path of the endpoint to send again the event of a delivery
+	AdminRedeliverPath = "/admin/webhooks/{id}/deliveries/{deliveryId}/redeliver"
+	// AdminDecisionProofPath path of the endpoint that retrieves the inclusion proof of a decision in the audit log
+	AdminDecisionProofPath = "/admin/audit/decisions/{id}/proof"
+	// AdminRetentionReportPath path of the endpoint that retrieves the report of the last retention run
+	AdminRetentionReportPath = "/admin/retention/report"
+	// AdminRetentionPurgePath path of the endpoint that runs the retention purge
+	AdminRetentionPurgePath = "/admin/retention/purge"
+	// BackofficeReviewsPath path of the endpoint that lists the review queue
+	BackofficeReviewsPath = "/backoffice/reviews"
+	// BackofficeReviewPath path of the endpoint that retrieves a review
+	BackofficeReviewPath = "/backoffice/reviews/{id}"
+	// BackofficeAssignPath path of the endpoint to assign a review to the authenticated reviewer
+	BackofficeAssignPath = "/backoffice/reviews/{id}/assign"
+	// BackofficeNotesPath path of the endpoint to add a note to a review
+	BackofficeNotesPath = "/backoffice/reviews/{id}/notes"
+	// BackofficeDecisionPath path of the endpoint to resolve a review
+	BackofficeDecisionPath = "/backoffice/reviews/{id}/decision"
+	// AccountsPath path of the endpoint to accept an approved credit line and open its account
+	AccountsPath = "/api/v1/credits/accounts"
+	// AccountPath path of the endpoint that retrieves an account with its balances
+	AccountPath = "/api/v1/credits/accounts/{id}"
+	// AccountMovementsPath path of the endpoint that lists the movements of an account
+	AccountMovementsPath = "/api/v1/credits/accounts/{id}/movements"
+	// AccountDrawdownsPath path of the endpoint to draw an amount of the available balance of an account
+	AccountDrawdownsPath = "/api/v1/credits/accounts/{id}/drawdowns"
+	// AccountRepaymentsPath path of the endpoint to repay an amount of the outstanding balance of an account
+	AccountRepaymentsPath = "/api/v1/credits/accounts/{id}/repayments"
+	// AdminAccountsPath path of the endpoint that lists the credit line accounts
+	AdminAccountsPath = "/admin/accounts"
+	// AdminCloseAccountPath path of the endpoint to close an account without outstanding balance
+	AdminCloseAccountPath = "/admin/accounts/{id}/close"
+	// AdminLedgerCheckPath path of the endpoint that checks the consistency of the ledger
+	AdminLedgerCheckPath = "/admin/ledger/check"
+	// OpenAPIPath path of the endpoint that serves the OpenAPI document
+	OpenAPIPath = "/openapi.json"
+	// SwaggerUIPath path of the endpoint that serves the Swagger UI page
+	SwaggerUIPath = "/docs"
+)
+
+// swaggerUIPage html page that renders the OpenAPI document with Swagger UI
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Credit Line API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "` + OpenAPIPath + `", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+// OpenAPIHandler struct that contains the OpenAPI document of the API
+type OpenAPIHandler struct {
+	document *openapi.Document
+}
+
+// NewOpenAPIHandler creates a new pointer of OpenAPIHandler struct
+func NewOpenAPIHandler(document *openapi.Document) *OpenAPIHandler {
+	return &OpenAPIHandler{
+		document: document,
+	}
+}
+
+// OpenAPI invokes the echo handler to serve the OpenAPI document
+func (oh *OpenAPIHandler) OpenAPI(c echo.Context) error {
+	return c.JSON(http.StatusOK, oh.document)
+}
+
+// SwaggerUI invokes the echo handler to serve the Swagger UI page
+func (oh *OpenAPIHandler) SwaggerUI(c echo.Context) error {
+	return c.HTML(http.StatusOK, swaggerUIPage)
+}
+
+// NewOpenAPIDocument builds the OpenAPI document from the request and response types of the handlers
+func NewOpenAPIDocument() *openapi.Document {
+	request := openapi.SchemaOf(CreditLineRequest{})
+	request.Properties["foundingType"].Enum = []interface{}{calculator.SME_FOUNDING_TYPE, calculator.STARTUP_FOUNDING_TYPE}
+	request.Properties["requestedDate"].Format = "date-time"
+	request.Properties["contact"] = openapi.Ref("ContactRequest")
+	contactRequest := openapi.SchemaOf(ContactRequest{})
+	contactRequest.Properties["email"].Format = "email"
+	request.Properties["applicant"] = openapi.Ref("ApplicantRequest")
+	applicantRequest := openapi.SchemaOf(ApplicantRequest{})
+	applicantRequest.Properties["taxId"].Description = "RFC of the applicant, only kept as a keyed hash"
+	applicantRequest.Properties["email"].Format = "email"
+
+	response := openapi.SchemaOf(model.CreditLineResponse{})
+	response.Properties["creditStatus"].Enum = []interface{}{string(model.Approved), string(model.Declined),
+		string(model.PendingReview)}
+
+	accessEntryRequest := openapi.SchemaOf(AccessEntryRequest{})
+	accessEntryRequest.Properties["action"].Enum = []interface{}{access.AllowAction, access.BlockAction}
+	accessEntry := openapi.SchemaOf(model.AccessEntry{})
+	accessEntry.Properties["action"].Enum = []interface{}{access.AllowAction, access.BlockAction}
+	accessEntry.Properties["source"].Enum = []interface{}{access.ConfigSource, access.FileSource, access.AdminSource}
+	clientThrottling := openapi.SchemaOf(model.ClientThrottling{})
+	clientThrottling.Properties["access"].Enum = []interface{}{access.AllowAction, access.BlockAction}
+	clientThrottling.Properties["allowance"] = openapi.Ref("Allowance")
+	clientThrottling.Properties["rateLimits"].Items = openapi.Ref("RateLimit")
+
+	leadStatuses := []interface{}{string(model.LeadNew), string(model.LeadNotified), string(model.LeadDeliveryFailed),
+		string(model.LeadContacted), string(model.LeadClosed)}
+	leadStatus := openapi.SchemaOf(model.LeadStatusResponse{})
+	leadStatus.Properties["status"].Enum = leadStatuses
+	lead := openapi.SchemaOf(model.Lead{})
+	lead.Properties["status"].Enum = leadStatuses
+	lead.Properties["contact"] = openapi.Ref("Contact")
+	lead.Properties["requests"].Items = openapi.Ref("LeadRequest")
+	leadRequest := openapi.SchemaOf(model.LeadRequest{})
+	leadRequest.Properties["creditStatus"].Enum = []interface{}{string(model.Approved), string(model.Declined)}
+	leadStatusRequest := openapi.SchemaOf(LeadStatusRequest{})
+	leadStatusRequest.Properties["status"].Enum = []interface{}{string(model.LeadContacted), string(model.LeadClosed)}
+
+	eventTypes := []interface{}{string(model.DecisionApproved), string(model.DecisionDeclined), string(model.RetriesExhausted),
+		string(model.ReviewRequested)}
+	webhookRequest := openapi.SchemaOf(WebhookRequest{})
+	webhookRequest.Properties["url"].Format = "uri"
+	webhookRequest.Properties["events"].Items.Enum = eventTypes
+	webhookSubscription := openapi.SchemaOf(model.WebhookSubscription{})
+	webhookSubscription.Properties["url"].Format = "uri"
+	webhookSubscription.Properties["events"].Items.Enum = eventTypes
+	webhookSubscription.Properties["secret"].Description = "Secret to verify the signatures, only retrieved when the subscription is created"
+	webhookDelivery := openapi.SchemaOf(model.WebhookDelivery{})
+	webhookDelivery.Properties["eventType"].Enum = eventTypes
+	webhookDelivery.Properties["status"].Enum = []interface{}{string(model.DeliveryPending), string(model.DeliverySucceeded),
+		string(model.DeliveryFailed)}
+
+	auditEntry := openapi.SchemaOf(model.AuditEntry{})
+	auditEntry.Properties["outcome"].Properties["creditStatus"].Enum = []interface{}{string(model.Approved),
+		string(model.Declined), string(model.PendingReview)}
+	inclusionProof := openapi.SchemaOf(model.InclusionProof{})
+	inclusionProof.Properties["entry"] = openapi.Ref("AuditEntry")
+	inclusionProof.Properties["auditPath"].Description = "Hex sibling hashes from the leaf to the root of the RFC 6962 Merkle tree"
+
+	retentionReport := openapi.SchemaOf(model.RetentionReport{})
+	retentionReport.Properties["mode"].Enum = []interface{}{string(model.RetentionPurge), string(model.RetentionAnonymize)}
+	retentionReport.Properties["auditEntries"].Description = "Expired audit entries are anonymized by removing the data key of their applicant data"
+
+	reviewStatuses := []interface{}{string(model.ReviewPending), string(model.ReviewAssigned), string(model.ReviewApproved),
+		string(model.ReviewDeclined)}
+	reviewActions := []interface{}{string(model.ReviewApprove), string(model.ReviewDecline), string(model.ReviewAdjust)}
+	review := openapi.SchemaOf(model.Review{})
+	review.Properties["status"].Enum = reviewStatuses
+	review.Properties["reason"].Enum = []interface{}{string(model.ReviewBand), string(model.ReviewRisk)}
+	review.Properties["action"].Enum = reviewActions
+	review.Properties["risk"].Properties["action"].Enum = []interface{}{string(model.RiskAllow), string(model.RiskFlag),
+		string(model.RiskDelay), string(model.RiskReview)}
+	review.Properties["notes"].Items = openapi.Ref("ReviewNote")
+	review.Properties["breachedAt"].Description = "Time the review was found overdue, it is not resolved within the SLA"
+	reviewDecisionRequest := openapi.SchemaOf(ReviewDecisionRequest{})
+	reviewDecisionRequest.Properties["action"].Enum = reviewActions
+	reviewDecisionRequest.Properties["amount"].Description = "Credit line authorized by the adjust action"
+
+	account := openapi.SchemaOf(model.Account{})
+	account.Properties["status"].Enum = []interface{}{string(model.AccountOpen), string(model.AccountClosed)}
+	amountProperties(account, "limit", "outstanding", "available")
+	account.Properties["available"].Description = "Limit that is not outstanding, zero once the account is closed"
+	account.Properties["token"].Description = "Token of the requests of the account, only retrieved when the account is opened"
+	movementTypes := []interface{}{string(model.Drawdown), string(model.Repayment)}
+	movement := openapi.SchemaOf(model.Movement{})
+	movement.Properties["type"].Enum = movementTypes
+	amountProperties(movement, "amount", "outstanding")
+	movement.Properties["outstanding"].Description = "Outstanding balance of the account after the movement"
+	ledgerCheck := openapi.SchemaOf(model.LedgerCheck{})
+	amountProperties(ledgerCheck, "debits", "credits")
+	amountProperties(ledgerCheck.Properties["mismatches"].Items, "outstanding", "ledgerBalance", "limit")
+	ledgerCheck.Properties["unbalanced"].Description = "Identifiers of the transactions whose debits are not their credits"
+	ledgerCheck.Properties["drifted"].Description = "Ledger accounts whose balance is not the sum of their entries"
+	movementRequest := openapi.SchemaOf(MovementRequest{})
+	movementRequest.Properties["reference"].Description = "Reference of the client, a retry with the same reference does not post the movement again"
+
+	webhookParameter := &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Identifier of the webhook subscription",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	idParameter := &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Identifier of the lead",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	ipParameter := &openapi.Parameter{
+		Name:        "ip",
+		In:          "path",
+		Description: "IPv4 or IPv6 address of the client",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	reviewParameter := &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Identifier of the review",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	accountParameter := &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "Identifier of the account",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	adminSecurity := []openapi.SecurityRequirement{{"adminToken": {}}}
+	reviewerSecurity := []openapi.SecurityRequirement{{"reviewerToken": {}}}
+	accountSecurity := []openapi.SecurityRequirement{{"accountToken": {}}}
+
+	return &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Credit Line API",
+			Description: "API to simulate the calculation of the credit limit allowed for a Startup or an SME",
+			Version:     "1.0.0",
+		},
+		Servers: []openapi.Server{{URL: "/"}},
+		Paths: map[string]*openapi.PathItem{
+			CreditLimitPath: {
+				Post: &openapi.Operation{
+					OperationID: "calculateCreditLimit",
+					Summary:     "Calculates the credit limit allowed for a founding type",
+					Tags:        []string{"credits"},
+					Parameters: []*openapi.Parameter{{
+						Name:        "Accept-Language",
+						In:          "header",
+						Description: "Language of the error messages (en, es)",
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("CreditLineRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Credit line determined or pending the decision of a reviewer", Content: jsonContent(openapi.Ref("CreditLineResponse"))},
+						"400": errorResponse("Invalid request"),
+						"403": errorResponse("Blocked ip"),
+						"408": errorResponse("Request body not received in time"),
+						"413": errorResponse("Request body too large"),
+						"429": errorResponse("Rate limit exceeded or decline retries exhausted"),
+						"500": errorResponse("Internal server error"),
+						"503": errorResponse("Request not processed in time"),
+					},
+				},
+			},
+			LeadStatusPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveLeadStatus",
+					Summary:     "Retrieves the status of the lead created when the decline retries were exhausted",
+					Tags:        []string{"credits"},
+					Parameters:  []*openapi.Parameter{idParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Status of the lead", Content: jsonContent(openapi.Ref("LeadStatusResponse"))},
+						"403": errorResponse("Blocked ip"),
+						"404": errorResponse("Lead not found"),
+					},
+				},
+			},
+			AccountsPath: {
+				Post: &openapi.Operation{
+					OperationID: "openAccount",
+					Summary:     "Accepts an approved credit line within the acceptance window and opens its account with the authorized credit line as limit",
+					Tags:        []string{"accounts"},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("AccountRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"201": {Description: "Opened account", Content: jsonContent(openapi.Ref("Account"))},
+						"400": errorResponse("Invalid request"),
+						"403": errorResponse("Blocked ip"),
+						"404": errorResponse("Decision not found or determined for another ip"),
+						"409": errorResponse("Decision not approved, acceptance window expired or account already opened"),
+					},
+				},
+			},
+			AccountPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveAccount",
+					Summary:     "Retrieves an account with its limit, outstanding and available balances",
+					Tags:        []string{"accounts"},
+					Security:    accountSecurity,
+					Parameters:  []*openapi.Parameter{accountParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Account", Content: jsonContent(openapi.Ref("Account"))},
+						"401": errorResponse("Missing or invalid account token"),
+						"403": errorResponse("Blocked ip"),
+						"404": errorResponse("Account not found"),
+					},
+				},
+			},
+			AccountMovementsPath: {
+				Get: &openapi.Operation{
+					OperationID: "listAccountMovements",
+					Summary:     "Lists the movements of an account, from the oldest to the newest",
+					Tags:        []string{"accounts"},
+					Security:    accountSecurity,
+					Parameters:  []*openapi.Parameter{accountParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Movements of the account", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("Movement")})},
+						"401": errorResponse("Missing or invalid account token"),
+						"403": errorResponse("Blocked ip"),
+						"404": errorResponse("Account not found"),
+					},
+				},
+			},
+			AccountDrawdownsPath: {
+				Post: &openapi.Operation{
+					OperationID: "drawAccount",
+					Summary:     "Draws an amount of the available balance of an account",
+					Tags:        []string{"accounts"},
+					Security:    accountSecurity,
+					Parameters:  []*openapi.Parameter{accountParameter},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("MovementRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Drawdown already posted with the same reference", Content: jsonContent(openapi.Ref("Movement"))},
+						"201": {Description: "Posted drawdown", Content: jsonContent(openapi.Ref("Movement"))},
+						"400": errorResponse("Invalid request"),
+						"401": errorResponse("Missing or invalid account token"),
+						"403": errorResponse("Blocked ip"),
+						"404": errorResponse("Account not found"),
+						"409": errorResponse("Account closed or reference used by another movement"),
+						"422": errorResponse("Amount greater than the available balance"),
+					},
+				},
+			},
+			AccountRepaymentsPath: {
+				Post: &openapi.Operation{
+					OperationID: "repayAccount",
+					Summary:     "Repays an amount of the outstanding balance of an account",
+					Tags:        []string{"accounts"},
+					Security:    accountSecurity,
+					Parameters:  []*openapi.Parameter{accountParameter},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("MovementRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Repayment already posted with the same reference", Content: jsonContent(openapi.Ref("Movement"))},
+						"201": {Description: "Posted repayment", Content: jsonContent(openapi.Ref("Movement"))},
+						"400": errorResponse("Invalid request"),
+						"401": errorResponse("Missing or invalid account token"),
+						"403": errorResponse("Blocked ip"),
+						"404": errorResponse("Account not found"),
+						"409": errorResponse("Account closed or reference used by another movement"),
+						"422": errorResponse("Amount greater than the outstanding balance"),
+					},
+				},
+			},
+			AdminAccountsPath: {
+				Get: &openapi.Operation{
+					OperationID: "listAccounts",
+					Summary:     "Lists the credit line accounts, newest first",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Credit line accounts", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("Account")})},
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+			},
+			AdminCloseAccountPath: {
+				Post: &openapi.Operation{
+					OperationID: "closeAccount",
+					Summary:     "Closes an account without outstanding balance, it does not accept more movements",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{accountParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Closed account", Content: jsonContent(openapi.Ref("Account"))},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Account not found"),
+						"409": errorResponse("Account already closed or with outstanding balance"),
+					},
+				},
+			},
+			AdminLedgerCheckPath: {
+				Get: &openapi.Operation{
+					OperationID: "checkLedger",
+					Summary:     "Checks that the ledger transactions are balanced and the outstanding balances of the accounts match the ledger",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Result of the consistency checks", Content: jsonContent(openapi.Ref("LedgerCheck"))},
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+			},
+			AdminLeadsPath: {
+				Get: &openapi.Operation{
+					OperationID: "listLeads",
+					Summary:     "Lists the leads handed off to the sales team, newest first",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Leads with the contact data and the request history", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("Lead")})},
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+			},
+			AdminLeadPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveLead",
+					Summary:     "Retrieves a lead with the contact data and the request history",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{idParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Lead", Content: jsonContent(openapi.Ref("Lead"))},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Lead not found"),
+					},
+				},
+				Patch: &openapi.Operation{
+					OperationID: "updateLeadStatus",
+					Summary:     "Marks a lead as contacted or closed, a closed lead allows a new lead for the same ip",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{idParameter},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("LeadStatusRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Lead with the new status", Content: jsonContent(openapi.Ref("Lead"))},
+						"400": errorResponse("Invalid request"),
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Lead not found"),
+					},
+				},
+			},
+			AdminWebhooksPath: {
+				Get: &openapi.Operation{
+					OperationID: "listWebhooks",
+					Summary:     "Lists the webhook subscriptions of the decision events",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Webhook subscriptions without the secrets", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("WebhookSubscription")})},
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+				Post: &openapi.Operation{
+					OperationID: "createWebhook",
+					Summary:     "Subscribes an URL to the decision events, the events are signed with HMAC-SHA256",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("WebhookRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"201": {Description: "Webhook subscription with the secret", Content: jsonContent(openapi.Ref("WebhookSubscription"))},
+						"400": errorResponse("Invalid request or url"),
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+			},
+			AdminWebhookPath: {
+				Delete: &openapi.Operation{
+					OperationID: "deleteWebhook",
+					Summary:     "Removes a webhook subscription, its pending deliveries are not retried",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{webhookParameter},
+					Responses: map[string]*openapi.Response{
+						"204": {Description: "Webhook subscription removed"},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Webhook subscription not found"),
+					},
+				},
+			},
+			AdminDeliveriesPath: {
+				Get: &openapi.Operation{
+					OperationID: "listWebhookDeliveries",
+					Summary:     "Lists the last deliveries of a webhook subscription, newest first",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{webhookParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Deliveries of the webhook subscription", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("WebhookDelivery")})},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Webhook subscription not found"),
+					},
+				},
+			},
+			AdminRedeliverPath: {
+				Post: &openapi.Operation{
+					OperationID: "redeliverWebhook",
+					Summary:     "Sends again the event of a delivery in a new delivery with the same event id",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters: []*openapi.Parameter{webhookParameter, {
+						Name:        "deliveryId",
+						In:          "path",
+						Description: "Identifier of the delivery",
+						Required:    true,
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					Responses: map[string]*openapi.Response{
+						"202": {Description: "New delivery of the event", Content: jsonContent(openapi.Ref("WebhookDelivery"))},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Webhook subscription or delivery not found"),
+					},
+				},
+			},
+			AdminDecisionProofPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveDecisionProof",
+					Summary:     "Retrieves the audit entry of a decision with its inclusion proof in the current tree of the audit log",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters: []*openapi.Parameter{{
+						Name:        "id",
+						In:          "path",
+						Description: "Identifier of the decision",
+						Required:    true,
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Audit entry and inclusion proof of the decision", Content: jsonContent(openapi.Ref("InclusionProof"))},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Decision not in the audit log"),
+					},
+				},
+			},
+			AdminRetentionReportPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveRetentionReport",
+					Summary:     "Retrieves the report of the last retention run",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Report of the last retention run", Content: jsonContent(openapi.Ref("RetentionReport"))},
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("No retention run since the start"),
+					},
+				},
+			},
+			AdminRetentionPurgePath: {
+				Post: &openapi.Operation{
+					OperationID: "purgeRetention",
+					Summary:     "Purges or anonymizes the applicant data older than the retention days and expires the idle cache entries",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters: []*openapi.Parameter{{
+						Name:        "dryRun",
+						In:          "query",
+						Description: "Only reports the records that would be affected",
+						Schema:      &openapi.Schema{Type: "boolean"},
+					}},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Report of the retention run", Content: jsonContent(openapi.Ref("RetentionReport"))},
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+			},
+			BackofficeReviewsPath: {
+				Get: &openapi.Operation{
+					OperationID: "listReviews",
+					Summary:     "Lists the review queue, the first due first",
+					Tags:        []string{"backoffice"},
+					Security:    reviewerSecurity,
+					Parameters: []*openapi.Parameter{{
+						Name:        "status",
+						In:          "query",
+						Description: "Status of the reviews",
+						Schema:      &openapi.Schema{Type: "string", Enum: reviewStatuses},
+					}, {
+						Name:        "reviewer",
+						In:          "query",
+						Description: "Name of the reviewer of the reviews",
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Reviews of the queue", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("Review")})},
+						"400": errorResponse("Invalid status"),
+						"401": errorResponse("Missing or invalid reviewer token"),
+					},
+				},
+			},
+			BackofficeReviewPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveReview",
+					Summary:     "Retrieves a review with the inputs, the risk assessment and the notes of the request",
+					Tags:        []string{"backoffice"},
+					Security:    reviewerSecurity,
+					Parameters:  []*openapi.Parameter{reviewParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Review", Content: jsonContent(openapi.Ref("Review"))},
+						"401": errorResponse("Missing or invalid reviewer token"),
+						"404": errorResponse("Review not found"),
+					},
+				},
+			},
+			BackofficeAssignPath: {
+				Post: &openapi.Operation{
+					OperationID: "assignReview",
+					Summary:     "Assigns a review to the authenticated reviewer, it takes over the review of another reviewer",
+					Tags:        []string{"backoffice"},
+					Security:    reviewerSecurity,
+					Parameters:  []*openapi.Parameter{reviewParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Assigned review", Content: jsonContent(openapi.Ref("Review"))},
+						"401": errorResponse("Missing or invalid reviewer token"),
+						"404": errorResponse("Review not found"),
+						"409": errorResponse("Review already resolved"),
+					},
+				},
+			},
+			BackofficeNotesPath: {
+				Post: &openapi.Operation{
+					OperationID: "addReviewNote",
+					Summary:     "Adds a note of the authenticated reviewer to a review",
+					Tags:        []string{"backoffice"},
+					Security:    reviewerSecurity,
+					Parameters:  []*openapi.Parameter{reviewParameter},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("ReviewNoteRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"201": {Description: "Review with the note", Content: jsonContent(openapi.Ref("Review"))},
+						"400": errorResponse("Invalid request"),
+						"401": errorResponse("Missing or invalid reviewer token"),
+						"404": errorResponse("Review not found"),
+					},
+				},
+			},
+			BackofficeDecisionPath: {
+				Post: &openapi.Operation{
+					OperationID: "resolveReview",
+					Summary:     "Approves, declines or adjusts the credit line of a review assigned to the authenticated reviewer, the final decision is recorded and notified",
+					Tags:        []string{"backoffice"},
+					Security:    reviewerSecurity,
+					Parameters:  []*openapi.Parameter{reviewParameter},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("ReviewDecisionRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Resolved review", Content: jsonContent(openapi.Ref("Review"))},
+						"400": errorResponse("Invalid request"),
+						"401": errorResponse("Missing or invalid reviewer token"),
+						"404": errorResponse("Review not found"),
+						"409": errorResponse("Review already resolved or not assigned to the reviewer"),
+					},
+				},
+			},
+			AdminClientsPath: {
+				Get: &openapi.Operation{
+					OperationID: "listClients",
+					Summary:     "Lists the decline retries and the rate limits of the clients with declined requests",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Throttling state of the clients", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("ClientThrottling")})},
+						"401": errorResponse("Missing or invalid admin token"),
+						"500": errorResponse("Internal server error"),
+					},
+				},
+			},
+			AdminClientPath: {
+				Get: &openapi.Operation{
+					OperationID: "retrieveClient",
+					Summary:     "Retrieves the decline retries and the rate limits of a client",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{ipParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Throttling state of the client", Content: jsonContent(openapi.Ref("ClientThrottling"))},
+						"400": errorResponse("Invalid ip"),
+						"401": errorResponse("Missing or invalid admin token"),
+						"500": errorResponse("Internal server error"),
+					},
+				},
+				Delete: &openapi.Operation{
+					OperationID: "resetClient",
+					Summary:     "Resets the decline retries, the allowance and the rate limits of a client",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{ipParameter},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Throttling state of the client after the reset", Content: jsonContent(openapi.Ref("ClientThrottling"))},
+						"400": errorResponse("Invalid ip"),
+						"401": errorResponse("Missing or invalid admin token"),
+						"500": errorResponse("Internal server error"),
+					},
+				},
+			},
+			AdminAllowancePath: {
+				Post: &openapi.Operation{
+					OperationID: "grantAllowance",
+					Summary:     "Grants temporary extra decline retries to a client, it replaces the current allowance",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters:  []*openapi.Parameter{ipParameter},
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("AllowanceRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Throttling state of the client with the allowance", Content: jsonContent(openapi.Ref("ClientThrottling"))},
+						"400": errorResponse("Invalid request or ip"),
+						"401": errorResponse("Missing or invalid admin token"),
+						"500": errorResponse("Internal server error"),
+					},
+				},
+			},
+			AdminAccessListPath: {
+				Get: &openapi.Operation{
+					OperationID: "listAccessEntries",
+					Summary:     "Lists the allowed and blocked networks",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Responses: map[string]*openapi.Response{
+						"200": {Description: "Networks of the access list", Content: jsonContent(&openapi.Schema{Type: "array", Items: openapi.Ref("AccessEntry")})},
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+				Post: &openapi.Operation{
+					OperationID: "addAccessEntry",
+					Summary:     "Allows or blocks an ip or a network, the allowed clients skip the rate limits and the decline retries",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					RequestBody: &openapi.RequestBody{
+						Required: true,
+						Content:  jsonContent(openapi.Ref("AccessEntryRequest")),
+					},
+					Responses: map[string]*openapi.Response{
+						"201": {Description: "Network added to the access list", Content: jsonContent(openapi.Ref("AccessEntry"))},
+						"400": errorResponse("Invalid request or CIDR"),
+						"401": errorResponse("Missing or invalid admin token"),
+					},
+				},
+				Delete: &openapi.Operation{
+					OperationID: "removeAccessEntry",
+					Summary:     "Removes an ip or a network added to the access list with the admin API",
+					Tags:        []string{"admin"},
+					Security:    adminSecurity,
+					Parameters: []*openapi.Parameter{{
+						Name:        "cidr",
+						In:          "query",
+						Description: "ip or network in CIDR notation",
+						Required:    true,
+						Schema:      &openapi.Schema{Type: "string"},
+					}},
+					Responses: map[string]*openapi.Response{
+						"204": {Description: "Network removed from the access list"},
+						"400": errorResponse("Invalid CIDR"),
+						"401": errorResponse("Missing or invalid admin token"),
+						"404": errorResponse("Network not added with the admin API"),
+					},
+				},
+			},
+		},
+		Components: openapi.Components{
+			Schemas: map[string]*openapi.Schema{
+				"CreditLineRequest":     request,
+				"CreditLineResponse":    response,
+				"ContactRequest":        contactRequest,
+				"ApplicantRequest":      applicantRequest,
+				"LeadStatusResponse":    leadStatus,
+				"Lead":                  lead,
+				"Contact":               openapi.SchemaOf(model.Contact{}),
+				"LeadRequest":           leadRequest,
+				"LeadStatusRequest":     leadStatusRequest,
+				"WebhookRequest":        webhookRequest,
+				"WebhookSubscription":   webhookSubscription,
+				"WebhookDelivery":       webhookDelivery,
+				"AuditEntry":            auditEntry,
+				"InclusionProof":        inclusionProof,
+				"RetentionReport":       retentionReport,
+				"Review":                review,
+				"ReviewNote":            openapi.SchemaOf(model.ReviewNote{}),
+				"ReviewNoteRequest":     openapi.SchemaOf(ReviewNoteRequest{}),
+				"ReviewDecisionRequest": reviewDecisionRequest,
+				"Account":               account,
+				"AccountRequest":        openapi.SchemaOf(AccountRequest{}),
+				"Movement":              movement,
+				"MovementRequest":       movementRequest,
+				"LedgerCheck":           ledgerCheck,
+				"ClientThrottling":      clientThrottling,
+				"Allowance":             openapi.SchemaOf(model.Allowance{}),
+				"RateLimit":             openapi.SchemaOf(model.RateLimit{}),
+				"AllowanceRequest":      openapi.SchemaOf(AllowanceRequest{}),
+				"AccessEntryRequest":    accessEntryRequest,
+				"AccessEntry":           accessEntry,
+				"ApiResponse":           openapi.SchemaOf(errors.ApiResponse{}),
+				"ProblemDetails":        openapi.SchemaOf(errors.ProblemDetails{}),
+			},
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"adminToken": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Token of the ADMIN_TOKEN variable",
+				},
+				"reviewerToken": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Token of a reviewer of the REVIEWS_REVIEWERS variable",
+				},
+				"accountToken": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Token of the account retrieved when it is opened",
+				},
+			},
+		},
+	}
+}
+
+// amountProperties documents the properties of amounts in cents, they are rendered as strings with two decimals
+func amountProperties(schema *openapi.Schema, names ...string) {
+	for _, name := range names {
+		schema.Properties[name] = &openapi.Schema{Type: "string"}
+	}
+}
+
+// jsonContent builds the application/json content of a schema
+func jsonContent(schema *openapi.Schema) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{
+		echo.MIMEApplicationJSON: {Schema: schema},
+	}
+}
+
+// errorResponse builds an error response with the problem details and the legacy shapes
+func errorResponse(description string) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content: map[string]*openapi.MediaType{
+			errors.MIMEApplicationProblemJSON: {Schema: openapi.Ref("ProblemDetails")},
+			echo.MIMEApplicationJSON:          {Schema: openapi.Ref("ApiResponse")},
+		},
+	}
+}
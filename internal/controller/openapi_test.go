@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	pv "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/openapi"
+	"credit-line/pkg/validator"
+)
+
+func Test_OpenAPI_Document_Matches_Credit_Line_Handler(t *testing.T) {
+	testCases := map[string]struct {
+		service       service.CreditLineService
+		accept        string
+		request       []byte
+		expectedValid bool
+	}{
+		"approved": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return model.NewCreditLineResponse(model.Approved, "145.10"), nil
+				},
+			},
+			request:       []byte(`{"foundingType": "SME", "cashBalance": 435.30, "monthlyRevenue": 4235.45, "requestedCreditLine": 100, "requestedDate": "2021-07-19T16:32:59.860Z"}`),
+			expectedValid: true,
+		},
+		"declined": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return model.NewCreditLineResponse(model.Declined, "0.00"), nil
+				},
+			},
+			request:       []byte(`{"foundingType": "Startup", "cashBalance": 435.30, "monthlyRevenue": 4235.45, "requestedCreditLine": 1000, "requestedDate": "2021-07-19T16:32:59.860Z"}`),
+			expectedValid: true,
+		},
+		"pending_review": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					response := model.NewCreditLineResponse(model.PendingReview, "0.00")
+					response.ReviewID = "5b1e"
+					return response, nil
+				},
+			},
+			request:       []byte(`{"foundingType": "SME", "cashBalance": 435.30, "monthlyRevenue": 4235.45, "requestedCreditLine": 100, "requestedDate": "2021-07-19T16:32:59.860Z"}`),
+			expectedValid: true,
+		},
+		"missing_fields": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			request:       []byte(`{"cashBalance": 435.30, "requestedCreditLine": 100}`),
+			expectedValid: false,
+		},
+		"wrong_data_type_legacy_response": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, nil
+				},
+			},
+			accept:        echo.MIMEApplicationJSON,
+			request:       []byte(`{"foundingType": "SME", "cashBalance": "435.30", "monthlyRevenue": 4235.45, "requestedCreditLine": 100, "requestedDate": "2021-07-19T16:32:59.860Z"}`),
+			expectedValid: false,
+		},
+		"invalid_founding_type": {
+			service: &mockCreditLineService{
+				determineCreditLimit: func(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+					return nil, fmt.Errorf("determination failed: %w", errors.ErrInvalidFoundingType)
+				},
+			},
+			request:       []byte(`{"foundingType": "SMA", "cashBalance": 435.30, "monthlyRevenue": 4235.45, "requestedCreditLine": 100, "requestedDate": "2021-07-19T16:32:59.860Z"}`),
+			expectedValid: false,
+		},
+	}
+
+	doc := NewOpenAPIDocument()
+	operation := doc.Paths[CreditLimitPath].Operation(http.MethodPost)
+	if operation == nil {
+		t.Fatalf("the operation POST %s is not documented", CreditLimitPath)
+	}
+
+	e := echo.New()
+	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var request interface{}
+			if err := json.Unmarshal(tc.request, &request); err != nil {
+				t.Fatalf("unexpected unmarshall error, got: %v", err)
+			}
+			requestSchema := operation.RequestBody.Content[echo.MIMEApplicationJSON].Schema
+			violations := validateSchema(doc, requestSchema, request)
+			if gotValid := len(violations) == 0; gotValid != tc.expectedValid {
+				t.Fatalf("unexpected request validation, got: %v, expected: %v, violations: %v", gotValid, tc.expectedValid, violations)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, CreditLimitPath, bytes.NewBuffer(tc.request))
+			r.Header.Set("Content-Type", "application/json")
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+
+			handler := NewCreditLineHandler(tc.service)
+			if err := handler.CreditLine(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if gotValid := w.Code == http.StatusOK; gotValid != tc.expectedValid {
+				t.Fatalf("the handler and the document disagree, got status code: %v, expected valid: %v", w.Code, tc.expectedValid)
+			}
+
+			response, ok := operation.Responses[strconv.Itoa(w.Code)]
+			if !ok {
+				t.Fatalf("the status code %v is not documented", w.Code)
+			}
+			contentType := strings.Split(w.Header().Get("Content-Type"), ";")[0]
+			mediaType, ok := response.Content[contentType]
+			if !ok {
+				t.Fatalf("the content type %v of the status code %v is not documented", contentType, w.Code)
+			}
+
+			var body interface{}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("unexpected unmarshall error, got: %v", err)
+			}
+			if violations := validateSchema(doc, mediaType.Schema, body); len(violations) > 0 {
+				t.Errorf("the response does not match the document: %v", violations)
+			}
+		})
+	}
+}
+
+// validateSchema validates a decoded json value against a schema of the document, it retrieves
+// one message for each violation found
+func validateSchema(doc *openapi.Document, s *openapi.Schema, v interface{}) []string {
+	return validatePath(doc, s, v, "$")
+}
+
+// validatePath validates a value in one path of the json document
+func validatePath(doc *openapi.Document, s *openapi.Schema, v interface{}, path string) []string {
+	s = doc.ResolveSchema(s)
+	if s == nil {
+		return nil
+	}
+
+	var violations []string
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", path, v, s.Enum))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: expected object, got %T", path, v))
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				violations = append(violations, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ps, ok := s.Properties[name]; ok {
+				violations = append(violations, validatePath(doc, ps, obj[name], path+"."+name)...)
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: expected array, got %T", path, v))
+		}
+		for i, item := range arr {
+			violations = append(violations, validatePath(doc, s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			violations = append(violations, fmt.Sprintf("%s: expected string, got %T", path, v))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			violations = append(violations, fmt.Sprintf("%s: expected boolean, got %T", path, v))
+		}
+	case "number", "integer":
+		n, ok := v.(float64)
+		if !ok {
+			return append(violations, fmt.Sprintf("%s: expected %s, got %T", path, s.Type, v))
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			violations = append(violations, fmt.Sprintf("%s: expected integer, got %v", path, n))
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			violations = append(violations, fmt.Sprintf("%s: %v is lower than the minimum %v", path, n, *s.Minimum))
+		}
+	}
+	return violations
+}
+
+// containsValue reports if a value is one of the values of an enum
+func containsValue(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
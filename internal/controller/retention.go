@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+)
+
+// RetentionHandler struct that contains the service for the retention of the applicant data
+type RetentionHandler struct {
+	service service.RetentionService
+}
+
+// NewRetentionHandler creates a new pointer of RetentionHandler struct
+func NewRetentionHandler(service service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		service: service,
+	}
+}
+
+// RetentionReport invokes the echo handler to retrieve the report of the last retention run
+func (rh *RetentionHandler) RetentionReport(c echo.Context) error {
+	report, err := rh.service.Report(c.Request().Context())
+	if err != nil {
+		trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+		errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// PurgeRetention invokes the echo handler to run the retention purge, nothing is changed when the dryRun query
+// param is true
+func (rh *RetentionHandler) PurgeRetention(c echo.Context) error {
+	report, err := rh.service.Purge(c.Request().Context(), c.QueryParam("dryRun") == "true")
+	if err != nil {
+		trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+		errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
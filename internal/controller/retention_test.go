@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+)
+
+type mockRetentionService struct {
+	purge  func(ctx context.Context, dryRun bool) (*model.RetentionReport, error)
+	report func(ctx context.Context) (*model.RetentionReport, error)
+}
+
+func (mrs *mockRetentionService) Purge(ctx context.Context, dryRun bool) (*model.RetentionReport, error) {
+	return mrs.purge(ctx, dryRun)
+}
+
+func (mrs *mockRetentionService) Report(ctx context.Context) (*model.RetentionReport, error) {
+	return mrs.report(ctx)
+}
+
+func Test_Retention_Controller(t *testing.T) {
+	startedAt := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		service            *mockRetentionService
+		method             string
+		target             string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"retention_report": {
+			service: &mockRetentionService{
+				report: func(ctx context.Context) (*model.RetentionReport, error) {
+					return &model.RetentionReport{StartedAt: startedAt, Mode: model.RetentionPurge,
+						Decisions: model.RetentionCounts{Expired: 2, Purged: 2}}, nil
+				},
+			},
+			method:             http.MethodGet,
+			target:             "/",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"decisions":{"expired":2,"purged":2`,
+		},
+		"retention_report_not_found": {
+			service: &mockRetentionService{
+				report: func(ctx context.Context) (*model.RetentionReport, error) {
+					return nil, errors.ErrRetentionReportNotFound
+				},
+			},
+			method:             http.MethodGet,
+			target:             "/",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+		"purge_dry_run": {
+			service: &mockRetentionService{
+				purge: func(ctx context.Context, dryRun bool) (*model.RetentionReport, error) {
+					return &model.RetentionReport{StartedAt: startedAt, DryRun: dryRun, Mode: model.RetentionAnonymize}, nil
+				},
+			},
+			method:             http.MethodPost,
+			target:             "/?dryRun=true",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"dryRun":true`,
+		},
+		"purge": {
+			service: &mockRetentionService{
+				purge: func(ctx context.Context, dryRun bool) (*model.RetentionReport, error) {
+					return &model.RetentionReport{StartedAt: startedAt, DryRun: dryRun, Mode: model.RetentionAnonymize}, nil
+				},
+			},
+			method:             http.MethodPost,
+			target:             "/",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"dryRun":false`,
+		},
+		"purge_failed": {
+			service: &mockRetentionService{
+				purge: func(ctx context.Context, dryRun bool) (*model.RetentionReport, error) {
+					return nil, fmt.Errorf("decisions not expired: %w", fmt.Errorf("disk full"))
+				},
+			},
+			method:             http.MethodPost,
+			target:             "/",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `"code":"INTERNAL_SERVER_ERROR"`,
+		},
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.target, nil)
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+
+			handler := NewRetentionHandler(tc.service)
+			handle := handler.RetentionReport
+			if tc.method == http.MethodPost {
+				handle = handler.PurgeRetention
+			}
+			if err := handle(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+	"credit-line/pkg/middleware"
+)
+
+// ReviewHandler struct that contains the service for the manual review queue of the back-office
+type ReviewHandler struct {
+	service service.ReviewService
+}
+
+// ReviewsRequest struct that represents the filters of the review queue
+type ReviewsRequest struct {
+	Status   string `query:"status" validate:"omitempty,oneof=PENDING ASSIGNED APPROVED DECLINED"`
+	Reviewer string `query:"reviewer"`
+}
+
+// ReviewNoteRequest struct that represents the request to add a note to a review
+type ReviewNoteRequest struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
+
+// ReviewDecisionRequest struct that represents the request of a reviewer to resolve a review, the amount is only
+// required to adjust the credit line
+type ReviewDecisionRequest struct {
+	Action string  `json:"action" validate:"required,oneof=approve decline adjust"`
+	Amount float64 `json:"amount" validate:"required_if=Action adjust,gte=0"`
+	Note   string  `json:"note" validate:"max=2000"`
+}
+
+// NewReviewHandler creates a new pointer of ReviewHandler struct
+func NewReviewHandler(service service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+	}
+}
+
+// Reviews invokes the echo handler to list the review queue filtered by status and reviewer, the first due first
+func (vh *ReviewHandler) Reviews(c echo.Context) error {
+	var request ReviewsRequest
+	if err := vh.bind(c, &request); err != nil {
+		return err
+	}
+	reviews := vh.service.Reviews(c.Request().Context(), model.ReviewStatus(request.Status), request.Reviewer)
+	return c.JSON(http.StatusOK, reviews)
+}
+
+// Review invokes the echo handler to retrieve a review with its inputs, risk assessment and notes
+func (vh *ReviewHandler) Review(c echo.Context) error {
+	review, err := vh.service.Review(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return vh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, review)
+}
+
+// AssignReview invokes the echo handler to assign a review to the authenticated reviewer
+func (vh *ReviewHandler) AssignReview(c echo.Context) error {
+	review, err := vh.service.Assign(c.Request().Context(), c.Param("id"), middleware.Reviewer(c))
+	if err != nil {
+		return vh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, review)
+}
+
+// AddReviewNote invokes the echo handler to add a note of the authenticated reviewer to a review
+func (vh *ReviewHandler) AddReviewNote(c echo.Context) error {
+	var request ReviewNoteRequest
+	if err := vh.bind(c, &request); err != nil {
+		return err
+	}
+
+	review, err := vh.service.AddNote(c.Request().Context(), c.Param("id"), middleware.Reviewer(c), request.Text)
+	if err != nil {
+		return vh.domainError(c, err)
+	}
+	return c.JSON(http.StatusCreated, review)
+}
+
+// ResolveReview invokes the echo handler to approve, decline or adjust the credit line of a review assigned to
+// the authenticated reviewer
+func (vh *ReviewHandler) ResolveReview(c echo.Context) error {
+	var request ReviewDecisionRequest
+	if err := vh.bind(c, &request); err != nil {
+		return err
+	}
+
+	review, err := vh.service.Resolve(c.Request().Context(), c.Param("id"), middleware.Reviewer(c),
+		model.ReviewAction(request.Action), request.Amount, request.Note)
+	if err != nil {
+		return vh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, review)
+}
+
+// bind binds and validates a request to an echo error
+func (vh *ReviewHandler) bind(c echo.Context, request any) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+
+	if err := c.Bind(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+	return nil
+}
+
+// domainError maps an error of the review service to an echo error
+func (vh *ReviewHandler) domainError(c echo.Context, err error) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+	errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+	return echo.NewHTTPError(code, errResponse).SetInternal(err)
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pv "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/validator"
+)
+
+type mockReviewService struct {
+	reviews func(ctx context.Context, status model.ReviewStatus, reviewer string) []*model.Review
+	review  func(ctx context.Context, id string) (*model.Review, error)
+	assign  func(ctx context.Context, id, reviewer string) (*model.Review, error)
+	resolve func(ctx context.Context, id, reviewer string, action model.ReviewAction, amount float64, note string) (*model.Review, error)
+}
+
+func (mrs *mockReviewService) Reviews(ctx context.Context, status model.ReviewStatus, reviewer string) []*model.Review {
+	return mrs.reviews(ctx, status, reviewer)
+}
+
+func (mrs *mockReviewService) Review(ctx context.Context, id string) (*model.Review, error) {
+	return mrs.review(ctx, id)
+}
+
+func (mrs *mockReviewService) Assign(ctx context.Context, id, reviewer string) (*model.Review, error) {
+	return mrs.assign(ctx, id, reviewer)
+}
+
+func (mrs *mockReviewService) AddNote(ctx context.Context, id, reviewer, text string) (*model.Review, error) {
+	return &model.Review{ID: id, Notes: []model.ReviewNote{{Reviewer: reviewer, Text: text}}}, nil
+}
+
+func (mrs *mockReviewService) Resolve(ctx context.Context, id, reviewer string, action model.ReviewAction, amount float64,
+	note string) (*model.Review, error) {
+	return mrs.resolve(ctx, id, reviewer, action, amount, note)
+}
+
+func Test_Review_Controller(t *testing.T) {
+	testCases := map[string]struct {
+		service            *mockReviewService
+		handler            func(vh *ReviewHandler) echo.HandlerFunc
+		query              string
+		request            string
+		expectedStatusCode int
+		expectedBody       string
+		unexpectedBody     string
+	}{
+		"reviews_filtered": {
+			service: &mockReviewService{
+				reviews: func(ctx context.Context, status model.ReviewStatus, reviewer string) []*model.Review {
+					if status != model.ReviewAssigned || reviewer != "ana" {
+						return nil
+					}
+					return []*model.Review{{ID: "5b1e", Status: status, Reviewer: reviewer}}
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.Reviews },
+			query:              "?status=ASSIGNED&reviewer=ana",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"id":"5b1e"`,
+		},
+		"reviews_invalid_status": {
+			service:            &mockReviewService{},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.Reviews },
+			query:              "?status=CLOSED",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"oneof"`,
+		},
+		"review_without_ip": {
+			service: &mockReviewService{
+				review: func(ctx context.Context, id string) (*model.Review, error) {
+					return &model.Review{ID: id, IP: "10.0.0.1", Reason: model.ReviewBand}, nil
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.Review },
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"reason":"band"`,
+			unexpectedBody:     "10.0.0.1",
+		},
+		"review_not_found": {
+			service: &mockReviewService{
+				review: func(ctx context.Context, id string) (*model.Review, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrReviewNotFound, id)
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.Review },
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+		"assign_to_the_authenticated_reviewer": {
+			service: &mockReviewService{
+				assign: func(ctx context.Context, id, reviewer string) (*model.Review, error) {
+					return &model.Review{ID: id, Status: model.ReviewAssigned, Reviewer: reviewer}, nil
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.AssignReview },
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"reviewer":"ana"`,
+		},
+		"assign_resolved_review": {
+			service: &mockReviewService{
+				assign: func(ctx context.Context, id, reviewer string) (*model.Review, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrReviewResolved, id)
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.AssignReview },
+			expectedStatusCode: http.StatusConflict,
+			expectedBody:       `"code":"REVIEW_RESOLVED"`,
+		},
+		"add_note": {
+			service:            &mockReviewService{},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.AddReviewNote },
+			request:            `{"text": "bank statements requested"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `"text":"bank statements requested"`,
+		},
+		"add_empty_note": {
+			service:            &mockReviewService{},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.AddReviewNote },
+			request:            `{"text": ""}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"required"`,
+		},
+		"adjust_credit_line": {
+			service: &mockReviewService{
+				resolve: func(ctx context.Context, id, reviewer string, action model.ReviewAction, amount float64,
+					note string) (*model.Review, error) {
+					if reviewer != "ana" || action != model.ReviewAdjust || amount != 80 {
+						return nil, fmt.Errorf("unexpected decision %s %s %v", reviewer, action, amount)
+					}
+					return &model.Review{ID: id, Status: model.ReviewApproved, Action: action,
+						CreditLineAuthorized: "80.00"}, nil
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.ResolveReview },
+			request:            `{"action": "adjust", "amount": 80}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"creditLineAuthorized":"80.00"`,
+		},
+		"adjust_without_amount": {
+			service:            &mockReviewService{},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.ResolveReview },
+			request:            `{"action": "adjust"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"required_if"`,
+		},
+		"resolve_review_of_other_reviewer": {
+			service: &mockReviewService{
+				resolve: func(ctx context.Context, id, reviewer string, action model.ReviewAction, amount float64,
+					note string) (*model.Review, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrReviewNotAssigned, id)
+				},
+			},
+			handler:            func(vh *ReviewHandler) echo.HandlerFunc { return vh.ResolveReview },
+			request:            `{"action": "decline"}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedBody:       `"code":"REVIEW_NOT_ASSIGNED"`,
+		},
+	}
+
+	e := echo.New()
+	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			method := http.MethodPost
+			if tc.request == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/"+tc.query, bytes.NewBufferString(tc.request))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("5b1e")
+			ctx.Set("reviewer", "ana")
+
+			handler := tc.handler(NewReviewHandler(tc.service))
+			if err := handler(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+			if tc.unexpectedBody != "" && strings.Contains(w.Body.String(), tc.unexpectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to not contain: %v", w.Body.String(), tc.unexpectedBody)
+			}
+		})
+	}
+}
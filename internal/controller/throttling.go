@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+)
+
+// ThrottlingHandler struct that contains the service for the throttling state of the clients
+type ThrottlingHandler struct {
+	service service.ThrottlingService
+}
+
+// AllowanceRequest struct that represents the request to grant extra decline retries to a client
+type AllowanceRequest struct {
+	Retries         uint `json:"retries" validate:"required,min=1"`
+	DurationSeconds uint `json:"durationSeconds" validate:"required,min=1,max=604800"`
+}
+
+// AccessEntryRequest struct that represents the request to add a network to the access list
+type AccessEntryRequest struct {
+	CIDR   string `json:"cidr" validate:"required"`
+	Action string `json:"action" validate:"required,oneof=allow block"`
+}
+
+// NewThrottlingHandler creates a new pointer of ThrottlingHandler struct
+func NewThrottlingHandler(service service.ThrottlingService) *ThrottlingHandler {
+	return &ThrottlingHandler{
+		service: service,
+	}
+}
+
+// Clients invokes the echo handler to list the throttling state of the clients
+func (th *ThrottlingHandler) Clients(c echo.Context) error {
+	clients, err := th.service.Clients(c.Request().Context())
+	if err != nil {
+		return th.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, clients)
+}
+
+// Client invokes the echo handler to retrieve the throttling state of a client
+func (th *ThrottlingHandler) Client(c echo.Context) error {
+	client, err := th.service.Client(c.Request().Context(), c.Param("ip"))
+	if err != nil {
+		return th.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, client)
+}
+
+// ResetClient invokes the echo handler to reset the decline retries and the rate limits of a client
+func (th *ThrottlingHandler) ResetClient(c echo.Context) error {
+	client, err := th.service.ResetClient(c.Request().Context(), c.Param("ip"))
+	if err != nil {
+		return th.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, client)
+}
+
+// GrantAllowance invokes the echo handler to grant temporary extra decline retries to a client
+func (th *ThrottlingHandler) GrantAllowance(c echo.Context) error {
+	var request AllowanceRequest
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+
+	if err := c.Bind(&request); err != nil {
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	client, err := th.service.GrantAllowance(c.Request().Context(), c.Param("ip"), request.Retries,
+		time.Duration(request.DurationSeconds)*time.Second)
+	if err != nil {
+		return th.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, client)
+}
+
+// AccessList invokes the echo handler to list the networks of the access list
+func (th *ThrottlingHandler) AccessList(c echo.Context) error {
+	return c.JSON(http.StatusOK, th.service.AccessList(c.Request().Context()))
+}
+
+// AddAccessEntry invokes the echo handler to allow or block a network
+func (th *ThrottlingHandler) AddAccessEntry(c echo.Context) error {
+	var request AccessEntryRequest
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+
+	if err := c.Bind(&request); err != nil {
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	entry, err := th.service.AddAccessEntry(c.Request().Context(), request.CIDR, request.Action)
+	if err != nil {
+		return th.domainError(c, err)
+	}
+	return c.JSON(http.StatusCreated, entry)
+}
+
+// RemoveAccessEntry invokes the echo handler to remove a network of the access list
+func (th *ThrottlingHandler) RemoveAccessEntry(c echo.Context) error {
+	if err := th.service.RemoveAccessEntry(c.Request().Context(), c.QueryParam("cidr")); err != nil {
+		return th.domainError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// domainError maps an error of the throttling service to an echo error
+func (th *ThrottlingHandler) domainError(c echo.Context, err error) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+	errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+	return echo.NewHTTPError(code, errResponse).SetInternal(err)
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pv "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/validator"
+)
+
+type mockThrottlingService struct {
+	grantAllowance    func(ctx context.Context, ip string, retries uint, duration time.Duration) (*model.ClientThrottling, error)
+	addAccessEntry    func(ctx context.Context, cidr, action string) (*model.AccessEntry, error)
+	removeAccessEntry func(ctx context.Context, cidr string) error
+}
+
+func (mts *mockThrottlingService) Clients(ctx context.Context) ([]*model.ClientThrottling, error) {
+	return nil, nil
+}
+
+func (mts *mockThrottlingService) Client(ctx context.Context, ip string) (*model.ClientThrottling, error) {
+	return nil, nil
+}
+
+func (mts *mockThrottlingService) ResetClient(ctx context.Context, ip string) (*model.ClientThrottling, error) {
+	return nil, nil
+}
+
+func (mts *mockThrottlingService) GrantAllowance(ctx context.Context, ip string, retries uint, duration time.Duration) (*model.ClientThrottling, error) {
+	return mts.grantAllowance(ctx, ip, retries, duration)
+}
+
+func (mts *mockThrottlingService) AccessList(ctx context.Context) []model.AccessEntry {
+	return nil
+}
+
+func (mts *mockThrottlingService) AddAccessEntry(ctx context.Context, cidr, action string) (*model.AccessEntry, error) {
+	return mts.addAccessEntry(ctx, cidr, action)
+}
+
+func (mts *mockThrottlingService) RemoveAccessEntry(ctx context.Context, cidr string) error {
+	return mts.removeAccessEntry(ctx, cidr)
+}
+
+func Test_Throttling_Controller(t *testing.T) {
+	testCases := map[string]struct {
+		service            *mockThrottlingService
+		handler            func(th *ThrottlingHandler) echo.HandlerFunc
+		request            string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"grant_allowance": {
+			service: &mockThrottlingService{
+				grantAllowance: func(ctx context.Context, ip string, retries uint, duration time.Duration) (*model.ClientThrottling, error) {
+					if ip != "10.0.0.1" || retries != 2 || duration != time.Hour {
+						return nil, fmt.Errorf("unexpected allowance %s %d %v", ip, retries, duration)
+					}
+					return &model.ClientThrottling{IP: ip, RetriesAllowed: 5, RetriesRemaining: 2}, nil
+				},
+			},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.GrantAllowance },
+			request:            `{"retries": 2, "durationSeconds": 3600}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `"retriesRemaining":2`,
+		},
+		"grant_allowance_without_retries": {
+			service:            &mockThrottlingService{},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.GrantAllowance },
+			request:            `{"durationSeconds": 3600}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"field":"retries"`,
+		},
+		"grant_allowance_invalid_ip": {
+			service: &mockThrottlingService{
+				grantAllowance: func(ctx context.Context, ip string, retries uint, duration time.Duration) (*model.ClientThrottling, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrInvalidIP, ip)
+				},
+			},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.GrantAllowance },
+			request:            `{"retries": 2, "durationSeconds": 3600}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"detail":"invalid ip"`,
+		},
+		"add_access_entry": {
+			service: &mockThrottlingService{
+				addAccessEntry: func(ctx context.Context, cidr, action string) (*model.AccessEntry, error) {
+					return &model.AccessEntry{CIDR: "2001:db8::/32", Action: action}, nil
+				},
+			},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.AddAccessEntry },
+			request:            `{"cidr": "2001:db8::1/32", "action": "block"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `{"cidr":"2001:db8::/32","action":"block"}`,
+		},
+		"add_access_entry_invalid_action": {
+			service:            &mockThrottlingService{},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.AddAccessEntry },
+			request:            `{"cidr": "10.0.0.0/8", "action": "deny"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"oneof"`,
+		},
+		"add_access_entry_invalid_cidr": {
+			service: &mockThrottlingService{
+				addAccessEntry: func(ctx context.Context, cidr, action string) (*model.AccessEntry, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrInvalidCIDR, cidr)
+				},
+			},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.AddAccessEntry },
+			request:            `{"cidr": "10.0.0.0/33", "action": "allow"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"detail":"invalid ip or CIDR"`,
+		},
+		"remove_access_entry_not_found": {
+			service: &mockThrottlingService{
+				removeAccessEntry: func(ctx context.Context, cidr string) error {
+					return fmt.Errorf("%w: %s", errors.ErrAccessEntryNotFound, cidr)
+				},
+			},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.RemoveAccessEntry },
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+		"remove_access_entry": {
+			service: &mockThrottlingService{
+				removeAccessEntry: func(ctx context.Context, cidr string) error {
+					return nil
+				},
+			},
+			handler:            func(th *ThrottlingHandler) echo.HandlerFunc { return th.RemoveAccessEntry },
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+
+	e := echo.New()
+	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tc.request))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+			ctx.SetParamNames("ip")
+			ctx.SetParamValues("10.0.0.1")
+
+			handler := tc.handler(NewThrottlingHandler(tc.service))
+			if err := handler(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/internal/service"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+)
+
+// WebhookHandler struct that contains the service for the webhook subscriptions of the decision events
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+// WebhookRequest struct that represents the request to subscribe an URL to the decision events
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=decision.approved decision.declined retries.exhausted review.requested"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+}
+
+// NewWebhookHandler creates a new pointer of WebhookHandler struct
+func NewWebhookHandler(service service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+// Webhooks invokes the echo handler to list the webhook subscriptions
+func (wh *WebhookHandler) Webhooks(c echo.Context) error {
+	return c.JSON(http.StatusOK, wh.service.Subscriptions(c.Request().Context()))
+}
+
+// CreateWebhook invokes the echo handler to subscribe an URL to the decision events, the secret is only
+// retrieved in this response
+func (wh *WebhookHandler) CreateWebhook(c echo.Context) error {
+	var request WebhookRequest
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+
+	if err := c.Bind(&request); err != nil {
+		errResponse, code := errors.MapError(err, errors.UnmarshallErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	if err := c.Validate(request); err != nil {
+		errResponse, code := errors.MapError(err, errors.ValidationErr, trans)
+		return echo.NewHTTPError(code, errResponse).SetInternal(err)
+	}
+
+	events := make([]model.EventType, 0, len(request.Events))
+	for _, event := range request.Events {
+		events = append(events, model.EventType(event))
+	}
+	subscription, err := wh.service.CreateSubscription(c.Request().Context(), request.URL, events, request.Secret)
+	if err != nil {
+		return wh.domainError(c, err)
+	}
+	return c.JSON(http.StatusCreated, subscription)
+}
+
+// DeleteWebhook invokes the echo handler to remove a webhook subscription
+func (wh *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	if err := wh.service.DeleteSubscription(c.Request().Context(), c.Param("id")); err != nil {
+		return wh.domainError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Deliveries invokes the echo handler to list the last deliveries of a webhook subscription, newest first
+func (wh *WebhookHandler) Deliveries(c echo.Context) error {
+	deliveries, err := wh.service.Deliveries(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return wh.domainError(c, err)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver invokes the echo handler to send again the event of a delivery
+func (wh *WebhookHandler) Redeliver(c echo.Context) error {
+	delivery, err := wh.service.Redeliver(c.Request().Context(), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		return wh.domainError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+// domainError maps an error of the webhook service to an echo error
+func (wh *WebhookHandler) domainError(c echo.Context, err error) error {
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+	errResponse, code := errors.MapError(err, errors.DomainErr, trans)
+	return echo.NewHTTPError(code, errResponse).SetInternal(err)
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pv "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/validator"
+)
+
+type mockWebhookService struct {
+	createSubscription func(ctx context.Context, rawURL string, events []model.EventType, secret string) (*model.WebhookSubscription, error)
+	deleteSubscription func(ctx context.Context, id string) error
+	redeliver          func(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error)
+}
+
+func (mws *mockWebhookService) Subscriptions(ctx context.Context) []*model.WebhookSubscription {
+	return nil
+}
+
+func (mws *mockWebhookService) CreateSubscription(ctx context.Context, rawURL string, events []model.EventType, secret string) (*model.WebhookSubscription, error) {
+	return mws.createSubscription(ctx, rawURL, events, secret)
+}
+
+func (mws *mockWebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	return mws.deleteSubscription(ctx, id)
+}
+
+func (mws *mockWebhookService) Deliveries(ctx context.Context, subscriptionID string) ([]*model.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (mws *mockWebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error) {
+	return mws.redeliver(ctx, subscriptionID, deliveryID)
+}
+
+func Test_Webhook_Controller(t *testing.T) {
+	testCases := map[string]struct {
+		service            *mockWebhookService
+		handler            func(wh *WebhookHandler) echo.HandlerFunc
+		request            string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		"create_webhook": {
+			service: &mockWebhookService{
+				createSubscription: func(ctx context.Context, rawURL string, events []model.EventType, secret string) (*model.WebhookSubscription, error) {
+					if len(events) != 2 || events[1] != model.RetriesExhausted {
+						return nil, fmt.Errorf("unexpected events %v", events)
+					}
+					return &model.WebhookSubscription{ID: "9b1c", URL: rawURL, Events: events, Secret: "generated"}, nil
+				},
+			},
+			handler:            func(wh *WebhookHandler) echo.HandlerFunc { return wh.CreateWebhook },
+			request:            `{"url": "https://crm.example.com/hooks", "events": ["decision.declined", "retries.exhausted"]}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedBody:       `"secret":"generated"`,
+		},
+		"create_webhook_unknown_event": {
+			service:            &mockWebhookService{},
+			handler:            func(wh *WebhookHandler) echo.HandlerFunc { return wh.CreateWebhook },
+			request:            `{"url": "https://crm.example.com/hooks", "events": ["decision.unknown"]}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"rule":"oneof"`,
+		},
+		"create_webhook_short_secret": {
+			service:            &mockWebhookService{},
+			handler:            func(wh *WebhookHandler) echo.HandlerFunc { return wh.CreateWebhook },
+			request:            `{"url": "https://crm.example.com/hooks", "events": ["decision.approved"], "secret": "short"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"field":"secret"`,
+		},
+		"create_webhook_invalid_scheme": {
+			service: &mockWebhookService{
+				createSubscription: func(ctx context.Context, rawURL string, events []model.EventType, secret string) (*model.WebhookSubscription, error) {
+					return nil, fmt.Errorf("%w %q", errors.ErrInvalidWebhookURL, rawURL)
+				},
+			},
+			handler:            func(wh *WebhookHandler) echo.HandlerFunc { return wh.CreateWebhook },
+			request:            `{"url": "ftp://crm.example.com/hooks", "events": ["decision.approved"]}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `"detail":"the webhook url must be an absolute http or https url"`,
+		},
+		"delete_webhook_not_found": {
+			service: &mockWebhookService{
+				deleteSubscription: func(ctx context.Context, id string) error {
+					return fmt.Errorf("%w: %s", errors.ErrWebhookNotFound, id)
+				},
+			},
+			handler:            func(wh *WebhookHandler) echo.HandlerFunc { return wh.DeleteWebhook },
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `"code":"NOT_FOUND"`,
+		},
+		"redeliver": {
+			service: &mockWebhookService{
+				redeliver: func(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error) {
+					if subscriptionID != "9b1c" || deliveryID != "77aa" {
+						return nil, fmt.Errorf("unexpected delivery %s %s", subscriptionID, deliveryID)
+					}
+					return &model.WebhookDelivery{ID: "88bb", RedeliveryOf: deliveryID, Status: model.DeliveryPending}, nil
+				},
+			},
+			handler:            func(wh *WebhookHandler) echo.HandlerFunc { return wh.Redeliver },
+			expectedStatusCode: http.StatusAccepted,
+			expectedBody:       `"redeliveryOf":"77aa"`,
+		},
+	}
+
+	e := echo.New()
+	e.Validator = validator.New(pv.New())
+	e.HTTPErrorHandler = errors.HTTPErrorHandler
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tc.request))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctx := e.NewContext(r, w)
+			ctx.SetParamNames("id", "deliveryId")
+			ctx.SetParamValues("9b1c", "77aa")
+
+			handler := tc.handler(NewWebhookHandler(tc.service))
+			if err := handler(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+
+			if tc.expectedStatusCode != w.Code {
+				t.Errorf("unexpected status code, got: %v, expected: %v", w.Code, tc.expectedStatusCode)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("unexpected response, got: %v, expected to contain: %v", w.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
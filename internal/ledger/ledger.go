@@ -0,0 +1,152 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/errors"
+)
+
+const (
+	// FundingAccount ledger account of the funds lent to the credit line accounts, it is credited by the drawdowns
+	// and debited by the repayments
+	FundingAccount = "funding"
+	// receivablePrefix prefix of the ledger account of the amount owed by a credit line account
+	receivablePrefix = "receivable:"
+)
+
+// Ledger ledger contracts for the double-entry ledger of the credit line accounts
+type Ledger interface {
+	Post(ctx context.Context, transaction *model.LedgerTransaction) error
+	Balance(account string) model.Cents
+	Check(ctx context.Context) *model.LedgerCheck
+}
+
+// ledger struct that implement the Ledger interface in memory, the transactions are append only and the
+// balances of the ledger accounts are kept as the debits minus the credits
+type ledger struct {
+	mu           sync.RWMutex
+	transactions []*model.LedgerTransaction
+	ids          map[string]bool
+	balances     map[string]model.Cents
+}
+
+// NewLedger creates a new pointer of ledger struct without transactions
+func NewLedger() *ledger {
+	return &ledger{
+		ids:      make(map[string]bool),
+		balances: make(map[string]model.Cents),
+	}
+}
+
+// ReceivableAccount retrieves the ledger account of the amount owed by a credit line account
+func ReceivableAccount(accountID string) string {
+	return receivablePrefix + accountID
+}
+
+// NewTransaction creates the balanced transaction of a movement of a credit line account: a drawdown debits the
+// receivable of the account and credits the funding, a repayment debits the funding and credits the receivable
+func NewTransaction(id string, movement *model.Movement, postedAt time.Time) *model.LedgerTransaction {
+	debit, credit := ReceivableAccount(movement.AccountID), FundingAccount
+	if movement.Type == model.Repayment {
+		debit, credit = credit, debit
+	}
+	return &model.LedgerTransaction{
+		ID:        id,
+		Reference: movement.ID,
+		Entries: []model.LedgerEntry{
+			{Account: debit, Debit: movement.Amount},
+			{Account: credit, Credit: movement.Amount},
+		},
+		PostedAt: postedAt,
+	}
+}
+
+// Post implement the interface Ledger.Post, only the transactions with at least two entries, positive amounts
+// in one side of each entry and the same debits and credits are posted
+func (l *ledger) Post(ctx context.Context, transaction *model.LedgerTransaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validate(transaction); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ids[transaction.ID] {
+		return fmt.Errorf("duplicated ledger transaction %q", transaction.ID)
+	}
+	posted := *transaction
+	posted.Entries = append([]model.LedgerEntry(nil), transaction.Entries...)
+	l.transactions = append(l.transactions, &posted)
+	l.ids[posted.ID] = true
+	for _, entry := range posted.Entries {
+		l.balances[entry.Account] += entry.Debit - entry.Credit
+	}
+	return nil
+}
+
+// Balance implement the interface Ledger.Balance, the debits minus the credits of a ledger account
+func (l *ledger) Balance(account string) model.Cents {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.balances[account]
+}
+
+// Check implement the interface Ledger.Check, the balances are computed again from the entries of the
+// transactions and compared with the kept balances
+func (l *ledger) Check(_ context.Context) *model.LedgerCheck {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	check := &model.LedgerCheck{Transactions: len(l.transactions)}
+	balances := make(map[string]model.Cents)
+	for _, transaction := range l.transactions {
+		if validate(transaction) != nil {
+			check.Unbalanced = append(check.Unbalanced, transaction.ID)
+		}
+		for _, entry := range transaction.Entries {
+			check.Debits += entry.Debit
+			check.Credits += entry.Credit
+			balances[entry.Account] += entry.Debit - entry.Credit
+		}
+	}
+	for account, balance := range l.balances {
+		if balances[account] != balance {
+			check.Drifted = append(check.Drifted, account)
+		}
+	}
+	for account := range balances {
+		if _, ok := l.balances[account]; !ok {
+			check.Drifted = append(check.Drifted, account)
+		}
+	}
+	sort.Strings(check.Drifted)
+	check.Consistent = len(check.Unbalanced) == 0 && len(check.Drifted) == 0 && check.Debits == check.Credits
+	return check
+}
+
+// validate checks that a transaction is balanced
+func validate(transaction *model.LedgerTransaction) error {
+	if len(transaction.Entries) < 2 {
+		return fmt.Errorf("%w: transaction %q with less than two entries", errors.ErrUnbalancedTransaction, transaction.ID)
+	}
+	var debits, credits model.Cents
+	for _, entry := range transaction.Entries {
+		if entry.Debit < 0 || entry.Credit < 0 || (entry.Debit == 0) == (entry.Credit == 0) {
+			return fmt.Errorf("%w: transaction %q with an invalid entry of %q", errors.ErrUnbalancedTransaction,
+				transaction.ID, entry.Account)
+		}
+		debits += entry.Debit
+		credits += entry.Credit
+	}
+	if debits != credits {
+		return fmt.Errorf("%w: transaction %q debits %s and credits %s", errors.ErrUnbalancedTransaction,
+			transaction.ID, debits, credits)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	pkgerrors "credit-line/pkg/errors"
+)
+
+func Test_Ledger_Post(t *testing.T) {
+	testCases := map[string]struct {
+		entries       []model.LedgerEntry
+		expectedError error
+	}{
+		"balanced": {
+			entries: []model.LedgerEntry{
+				{Account: "receivable:3f2a", Debit: 10000},
+				{Account: FundingAccount, Credit: 10000},
+			},
+		},
+		"debits_not_credits": {
+			entries: []model.LedgerEntry{
+				{Account: "receivable:3f2a", Debit: 10000},
+				{Account: FundingAccount, Credit: 9999},
+			},
+			expectedError: pkgerrors.ErrUnbalancedTransaction,
+		},
+		"single_entry": {
+			entries:       []model.LedgerEntry{{Account: "receivable:3f2a", Debit: 10000}},
+			expectedError: pkgerrors.ErrUnbalancedTransaction,
+		},
+		"entry_with_both_sides": {
+			entries: []model.LedgerEntry{
+				{Account: "receivable:3f2a", Debit: 10000, Credit: 10000},
+				{Account: FundingAccount, Debit: 100, Credit: 100},
+			},
+			expectedError: pkgerrors.ErrUnbalancedTransaction,
+		},
+		"negative_amount": {
+			entries: []model.LedgerEntry{
+				{Account: "receivable:3f2a", Debit: -100},
+				{Account: FundingAccount, Credit: -100},
+			},
+			expectedError: pkgerrors.ErrUnbalancedTransaction,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			l := NewLedger()
+			err := l.Post(context.Background(), &model.LedgerTransaction{ID: "tx1", Entries: tc.entries})
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if tc.expectedError != nil && l.Check(context.Background()).Transactions != 0 {
+				t.Errorf("unexpected unbalanced transaction posted")
+			}
+		})
+	}
+}
+
+func Test_Ledger_Balances(t *testing.T) {
+	l := NewLedger()
+	now := time.Now()
+	movements := []*model.Movement{
+		{ID: "m1", AccountID: "3f2a", Type: model.Drawdown, Amount: 10000},
+		{ID: "m2", AccountID: "3f2a", Type: model.Repayment, Amount: 2550},
+		{ID: "m3", AccountID: "4b1c", Type: model.Drawdown, Amount: 199},
+	}
+	for i, movement := range movements {
+		if err := l.Post(context.Background(), NewTransaction(movement.ID+"-tx", movement, now)); err != nil {
+			t.Fatalf("unexpected error posting movement %d: %v", i, err)
+		}
+	}
+
+	if err := l.Post(context.Background(), NewTransaction("m1-tx", movements[0], now)); err == nil {
+		t.Errorf("expected error posting a duplicated transaction")
+	}
+	balances := map[string]model.Cents{
+		ReceivableAccount("3f2a"): 7450,
+		ReceivableAccount("4b1c"): 199,
+		FundingAccount:            -7649,
+	}
+	for account, expected := range balances {
+		if balance := l.Balance(account); balance != expected {
+			t.Errorf("unexpected balance of %s, got: %v, expected: %v", account, balance, expected)
+		}
+	}
+
+	expected := &model.LedgerCheck{Consistent: true, Transactions: 3, Debits: 12749, Credits: 12749}
+	if check := l.Check(context.Background()); !reflect.DeepEqual(check, expected) {
+		t.Errorf("unexpected check, got: %+v, expected: %+v", check, expected)
+	}
+}
+
+func Test_Ledger_Check_Drifted(t *testing.T) {
+	l := NewLedger()
+	movement := &model.Movement{ID: "m1", AccountID: "3f2a", Type: model.Drawdown, Amount: 10000}
+	if err := l.Post(context.Background(), NewTransaction("tx1", movement, time.Now())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.balances[ReceivableAccount("3f2a")] = 9000
+	l.transactions[0].Entries[1].Credit = 9000
+
+	check := l.Check(context.Background())
+	if check.Consistent {
+		t.Fatalf("expected an inconsistent ledger")
+	}
+	if !reflect.DeepEqual(check.Unbalanced, []string{"tx1"}) {
+		t.Errorf("unexpected unbalanced transactions, got: %v", check.Unbalanced)
+	}
+	if !reflect.DeepEqual(check.Drifted, []string{FundingAccount, ReceivableAccount("3f2a")}) {
+		t.Errorf("unexpected drifted accounts, got: %v", check.Drifted)
+	}
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// AccountOpen identify the account that accepts drawdowns and repayments
+	AccountOpen AccountStatus = "OPEN"
+	// AccountClosed identify the account that was closed without outstanding balance
+	AccountClosed AccountStatus = "CLOSED"
+
+	// Drawdown identify the movement that draws an amount of the available balance
+	Drawdown MovementType = "drawdown"
+	// Repayment identify the movement that repays an amount of the outstanding balance
+	Repayment MovementType = "repayment"
+)
+
+// AccountStatus type to specify the status of a credit line account
+type AccountStatus string
+
+// MovementType type to specify the type of a movement of a credit line account
+type MovementType string
+
+// Cents type to specify an amount of money in cents, the amounts of the accounts and the ledger are integers to
+// keep the balances exact; it is rendered as a string with two decimals like the authorized credit lines
+type Cents int64
+
+// NewCents creates an amount of cents from an amount with decimals, rounded to the nearest cent
+func NewCents(amount float64) Cents {
+	return Cents(math.Round(amount * 100))
+}
+
+// String retrieves the amount with two decimals
+func (c Cents) String() string {
+	sign, abs := "", int64(c)
+	if abs < 0 {
+		sign, abs = "-", -abs
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, abs/100, abs%100)
+}
+
+// MarshalJSON marshals the amount as a string with two decimals
+func (c Cents) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// UnmarshalJSON unmarshals an amount marshaled as a string with two decimals
+func (c *Cents) UnmarshalJSON(data []byte) error {
+	var amount string
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return err
+	}
+	parsed, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q, %w", amount, err)
+	}
+	*c = NewCents(parsed)
+	return nil
+}
+
+// Account struct that represents the credit line account opened when an applicant accepts an approved credit
+// line, the available balance is the limit that is not outstanding; the token authenticates the applicant in the
+// requests of the account, it is only retrieved when the account is opened and only its hash is stored
+type Account struct {
+	ID          string        `json:"id"`
+	DecisionID  string        `json:"decisionId"`
+	ApplicantID string        `json:"applicantId,omitempty"`
+	Status      AccountStatus `json:"status"`
+	Limit       Cents         `json:"limit"`
+	Outstanding Cents         `json:"outstanding"`
+	Available   Cents         `json:"available"`
+	OpenedAt    time.Time     `json:"openedAt"`
+	ClosedAt    *time.Time    `json:"closedAt,omitempty"`
+	Token       string        `json:"token,omitempty"`
+	TokenHash   string        `json:"-"`
+}
+
+// NewAccount creates a new pointer of an open Account with the authorized credit line of a decision as its limit
+func NewAccount(id string, decision *Decision, limit Cents, openedAt time.Time) *Account {
+	return &Account{
+		ID:          id,
+		DecisionID:  decision.ID,
+		ApplicantID: decision.ApplicantID,
+		Status:      AccountOpen,
+		Limit:       limit,
+		Available:   limit,
+		OpenedAt:    openedAt,
+	}
+}
+
+// Apply updates the outstanding and the available balances with a movement
+func (a *Account) Apply(movement *Movement) {
+	switch movement.Type {
+	case Drawdown:
+		a.Outstanding += movement.Amount
+	case Repayment:
+		a.Outstanding -= movement.Amount
+	}
+	a.Available = a.Limit - a.Outstanding
+}
+
+// Movement struct that represents a drawdown or a repayment of a credit line account and the ledger transaction
+// that posted it, the reference of the client makes the retries of a movement idempotent
+type Movement struct {
+	ID            string       `json:"id"`
+	AccountID     string       `json:"accountId"`
+	Type          MovementType `json:"type"`
+	Amount        Cents        `json:"amount"`
+	Reference     string       `json:"reference,omitempty"`
+	Outstanding   Cents        `json:"outstanding"`
+	TransactionID string       `json:"transactionId"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+// LedgerEntry struct that represents the debit or the credit of a ledger account in a transaction, only one of
+// the amounts is not zero
+type LedgerEntry struct {
+	Account string `json:"account"`
+	Debit   Cents  `json:"debit"`
+	Credit  Cents  `json:"credit"`
+}
+
+// LedgerTransaction struct that represents a balanced set of ledger entries, the debits equal the credits
+type LedgerTransaction struct {
+	ID        string        `json:"id"`
+	Reference string        `json:"reference"`
+	Entries   []LedgerEntry `json:"entries"`
+	PostedAt  time.Time     `json:"postedAt"`
+}
+
+// LedgerMismatch struct that represents an account whose outstanding balance is not the balance of its ledger
+// account or is not within its limit
+type LedgerMismatch struct {
+	AccountID     string `json:"accountId"`
+	Outstanding   Cents  `json:"outstanding"`
+	LedgerBalance Cents  `json:"ledgerBalance"`
+	Limit         Cents  `json:"limit"`
+}
+
+// LedgerCheck struct that represents the result of the consistency checks of the ledger: every transaction is
+// balanced, the total debits equal the total credits, the balances are the sum of the entries and the outstanding
+// balance of each account is the balance of its ledger account
+type LedgerCheck struct {
+	Consistent   bool             `json:"consistent"`
+	Transactions int              `json:"transactions"`
+	Unbalanced   []string         `json:"unbalanced,omitempty"`
+	Debits       Cents            `json:"debits"`
+	Credits      Cents            `json:"credits"`
+	Drifted      []string         `json:"drifted,omitempty"`
+	Accounts     int              `json:"accounts"`
+	Mismatches   []LedgerMismatch `json:"mismatches,omitempty"`
+	CheckedAt    time.Time        `json:"checkedAt"`
+}
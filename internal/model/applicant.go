@@ -0,0 +1,45 @@
+package model
+
+// ApplicantKeyPrefix prefix of the keys of the decline retries of the identified applicants, the retries of the
+// anonymous applicants are kept by ip
+const ApplicantKeyPrefix = "applicant:"
+
+// ApplicantIdentifiers struct with the business identifiers sent by an applicant, they are only kept as hashes
+type ApplicantIdentifiers struct {
+	TaxID     string
+	LegalName string
+	Email     string
+}
+
+// Applicant struct that represents an identified applicant with the keyed hashes of its normalized identifiers,
+// the id is the hash of the tax id, or of the email or the legal name when the tax id is not sent
+type Applicant struct {
+	ID            string `json:"id"`
+	TaxIDHash     string `json:"taxIdHash,omitempty"`
+	LegalNameHash string `json:"legalNameHash,omitempty"`
+	EmailHash     string `json:"emailHash,omitempty"`
+}
+
+// ApplicationHistory struct with the previous applications of an applicant in the duplicate window, an
+// application is a repeat when the applicant applied from other ips
+type ApplicationHistory struct {
+	Applications int  `json:"applications"`
+	IPs          int  `json:"ips"`
+	Repeat       bool `json:"repeat"`
+}
+
+// Hashes retrieves the hashes of the identifiers sent by the applicant
+func (a *Applicant) Hashes() []string {
+	hashes := make([]string, 0, 3)
+	for _, hash := range []string{a.TaxIDHash, a.LegalNameHash, a.EmailHash} {
+		if hash != "" {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// Key retrieves the key of the decline retries of the applicant
+func (a *Applicant) Key() string {
+	return ApplicantKeyPrefix + a.ID
+}
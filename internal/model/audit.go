@@ -0,0 +1,101 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Policy struct that represents the ratios and the decline retries applied to a decision, the version identifies
+// the values so the decisions of the same policy can be grouped
+type Policy struct {
+	Version               string  `json:"version"`
+	CashBalanceRatio      float64 `json:"cashBalanceRatio"`
+	MonthlyRevenueRatio   float64 `json:"monthlyRevenueRatio"`
+	DeclineRetriesAllowed uint    `json:"declineRetriesAllowed"`
+}
+
+// AuditClient struct that represents the identity of the client of an audited decision
+type AuditClient struct {
+	IP                     string `json:"ip"`
+	CertificateSubject     string `json:"certificateSubject,omitempty"`
+	CertificateFingerprint string `json:"certificateFingerprint,omitempty"`
+	ApplicantID            string `json:"applicantId,omitempty"`
+}
+
+// AuditInputs struct that represents the credit line request of an audited decision
+type AuditInputs struct {
+	FoundingType        string  `json:"foundingType"`
+	CashBalance         float64 `json:"cashBalance"`
+	MonthlyRevenue      float64 `json:"monthlyRevenue"`
+	RequestedCreditLine float64 `json:"requestedCreditLine"`
+	RequestedDate       string  `json:"requestedDate"`
+}
+
+// AuditOutcome struct that represents the result of an audited decision
+type AuditOutcome struct {
+	CreditStatus         CreditStatus `json:"creditStatus"`
+	CreditLineAuthorized string       `json:"creditLineAuthorized"`
+	LeadID               string       `json:"leadId,omitempty"`
+}
+
+// AuditEntry struct that represents an entry of the audit log, the hash covers the entry and the hash of the
+// previous entry so a modified entry breaks the chain; the client and the amounts of the inputs are in the
+// sealed envelope when the log has a keyring
+type AuditEntry struct {
+	Index      uint64       `json:"index"`
+	DecisionID string       `json:"decisionId"`
+	Timestamp  time.Time    `json:"timestamp"`
+	Client     AuditClient  `json:"client"`
+	Inputs     AuditInputs  `json:"inputs"`
+	Policy     Policy       `json:"policy"`
+	Outcome    AuditOutcome `json:"outcome"`
+	Sealed     *Envelope    `json:"sealed,omitempty"`
+	PrevHash   string       `json:"prevHash"`
+	Hash       string       `json:"hash"`
+}
+
+// InclusionProof struct that represents the RFC 6962 inclusion proof of an audit entry in the Merkle tree of the
+// audit log, the leaves are the hashes of the entries
+type InclusionProof struct {
+	Entry     AuditEntry `json:"entry"`
+	LeafIndex uint64     `json:"leafIndex"`
+	TreeSize  uint64     `json:"treeSize"`
+	RootHash  string     `json:"rootHash"`
+	AuditPath []string   `json:"auditPath"`
+	HeadHash  string     `json:"headHash"`
+}
+
+// NewPolicy creates a new Policy, the version is the prefix of the sha256 of the values
+func NewPolicy(cashBalanceRatio, monthlyRevenueRatio float64, declineRetriesAllowed uint) Policy {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%g|%g|%d", cashBalanceRatio, monthlyRevenueRatio, declineRetriesAllowed)))
+	return Policy{
+		Version:               hex.EncodeToString(sum[:6]),
+		CashBalanceRatio:      cashBalanceRatio,
+		MonthlyRevenueRatio:   monthlyRevenueRatio,
+		DeclineRetriesAllowed: declineRetriesAllowed,
+	}
+}
+
+// NewAuditEntry creates a new AuditEntry of a decision without its position in the log
+func NewAuditEntry(decision *Decision, creditLine *CreditLine, client AuditClient, policy Policy) AuditEntry {
+	return AuditEntry{
+		DecisionID: decision.ID,
+		Timestamp:  decision.DeterminedAt,
+		Client:     client,
+		Inputs: AuditInputs{
+			FoundingType:        creditLine.FoundingType(),
+			CashBalance:         creditLine.CashBalance(),
+			MonthlyRevenue:      creditLine.MonthlyRevenue(),
+			RequestedCreditLine: creditLine.RequestedCreditLine(),
+			RequestedDate:       creditLine.RequestedDate(),
+		},
+		Policy: policy,
+		Outcome: AuditOutcome{
+			CreditStatus:         decision.CreditStatus,
+			CreditLineAuthorized: decision.CreditLineAuthorized,
+			LeadID:               decision.LeadID,
+		},
+	}
+}
@@ -5,6 +5,9 @@ const (
 	Approved CreditStatus = "APPROVED"
 	// Declined identify the declined credit request status
 	Declined CreditStatus = "DECLINED"
+	// PendingReview identify the credit request status routed to manual review, it is approved or declined by a
+	// reviewer
+	PendingReview CreditStatus = "PENDING_REVIEW"
 )
 
 // CreditStatus type to specify the credit request status
@@ -17,12 +20,20 @@ type CreditLine struct {
 	monthlyRevenue      float64
 	requestedCreditLine float64
 	requestedDate       string
+	contact             *Contact
+	identifiers         *ApplicantIdentifiers
+	applicant           *Applicant
+	history             ApplicationHistory
+	risk                *RiskAssessment
 }
 
 // CreditLineResponse struct that represents the credit line response
 type CreditLineResponse struct {
 	CreditStatus         CreditStatus `json:"creditStatus"`
 	CreditLineAuthorized string       `json:"creditLineAuthorized"`
+	LeadID               string       `json:"leadId,omitempty"`
+	ReviewID             string       `json:"reviewId,omitempty"`
+	DecisionID           string       `json:"decisionId,omitempty"`
 }
 
 // NewCreditLine creates a new pointer of CreditLine struct
@@ -51,6 +62,53 @@ func (cl *CreditLine) RequestedCreditLine() float64 { return cl.requestedCreditL
 // RequestedDate getter for the requestedDate attribute
 func (cl *CreditLine) RequestedDate() string { return cl.requestedDate }
 
+// Contact getter for the contact attribute, nil when the applicant did not send contact data
+func (cl *CreditLine) Contact() *Contact { return cl.contact }
+
+// SetContact setter for the contact attribute
+func (cl *CreditLine) SetContact(contact *Contact) { cl.contact = contact }
+
+// Identifiers getter for the identifiers attribute, nil when the applicant did not send business identifiers
+func (cl *CreditLine) Identifiers() *ApplicantIdentifiers { return cl.identifiers }
+
+// SetIdentifiers setter for the identifiers attribute
+func (cl *CreditLine) SetIdentifiers(identifiers *ApplicantIdentifiers) { cl.identifiers = identifiers }
+
+// Applicant getter for the applicant attribute, nil when the applicant was not identified
+func (cl *CreditLine) Applicant() *Applicant { return cl.applicant }
+
+// History getter for the history attribute
+func (cl *CreditLine) History() ApplicationHistory { return cl.history }
+
+// SetApplicant setter for the applicant and history attributes
+func (cl *CreditLine) SetApplicant(applicant *Applicant, history ApplicationHistory) {
+	cl.applicant, cl.history = applicant, history
+}
+
+// Risk getter for the risk attribute, nil when the request was not assessed
+func (cl *CreditLine) Risk() *RiskAssessment { return cl.risk }
+
+// SetRisk setter for the risk attribute
+func (cl *CreditLine) SetRisk(risk *RiskAssessment) { cl.risk = risk }
+
+// RetryKey retrieves the key that groups the requests of an applicant, the applicant key of an identified
+// applicant or the ip of an anonymous applicant
+func (cl *CreditLine) RetryKey(ip string) string {
+	if cl.applicant == nil {
+		return ip
+	}
+	return cl.applicant.Key()
+}
+
+// RetryKeys retrieves the keys whose decline retries are counted for the request, the ip and the applicant key of
+// an identified applicant, so new identifiers in each request do not reset the retries of the ip
+func (cl *CreditLine) RetryKeys(ip string) []string {
+	if cl.applicant == nil {
+		return []string{ip}
+	}
+	return []string{ip, cl.applicant.Key()}
+}
+
 // NewCreditLineResponse creates a new pointer of CreditLineResponse
 func NewCreditLineResponse(creditStatus CreditStatus, creditLineAuthorized string) *CreditLineResponse {
 	return &CreditLineResponse{
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// Decision struct that represents a credit line decision stored with its events
+type Decision struct {
+	ID                   string          `json:"id"`
+	IP                   string          `json:"ip"`
+	FoundingType         string          `json:"foundingType"`
+	RequestedCreditLine  float64         `json:"requestedCreditLine"`
+	RequestedDate        string          `json:"requestedDate"`
+	CreditStatus         CreditStatus    `json:"creditStatus"`
+	CreditLineAuthorized string          `json:"creditLineAuthorized"`
+	LeadID               string          `json:"leadId,omitempty"`
+	ReviewID             string          `json:"reviewId,omitempty"`
+	ApplicantID          string          `json:"applicantId,omitempty"`
+	RepeatApplication    bool            `json:"repeatApplication,omitempty"`
+	Risk                 *RiskAssessment `json:"risk,omitempty"`
+	DeterminedAt         time.Time       `json:"determinedAt"`
+}
+
+// OutboxEvent struct that represents an event of a decision pending to be published to the broker
+type OutboxEvent struct {
+	Event     Event  `json:"event"`
+	Attempts  uint   `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// NewDecision creates a new pointer of Decision from a credit line and its response
+func NewDecision(id, ip string, creditLine *CreditLine, response *CreditLineResponse, determinedAt time.Time) *Decision {
+	decision := &Decision{
+		ID:                   id,
+		IP:                   ip,
+		FoundingType:         creditLine.FoundingType(),
+		RequestedCreditLine:  creditLine.RequestedCreditLine(),
+		RequestedDate:        creditLine.RequestedDate(),
+		CreditStatus:         response.CreditStatus,
+		CreditLineAuthorized: response.CreditLineAuthorized,
+		LeadID:               response.LeadID,
+		ReviewID:             response.ReviewID,
+		RepeatApplication:    creditLine.History().Repeat,
+		DeterminedAt:         determinedAt,
+	}
+	if applicant := creditLine.Applicant(); applicant != nil {
+		decision.ApplicantID = applicant.ID
+	}
+	// only the requests with a risk action keep their risk assessment
+	if risk := creditLine.Risk(); risk != nil && risk.Action != RiskAllow {
+		decision.Risk = risk
+	}
+	return decision
+}
@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+const (
+	// LeadNew identify the lead that is pending to be sent to the sales team
+	LeadNew LeadStatus = "NEW"
+	// LeadNotified identify the lead that was sent to the sales team
+	LeadNotified LeadStatus = "NOTIFIED"
+	// LeadDeliveryFailed identify the lead that could not be sent to the sales team
+	LeadDeliveryFailed LeadStatus = "DELIVERY_FAILED"
+	// LeadContacted identify the lead of an applicant already contacted by a sales agent
+	LeadContacted LeadStatus = "CONTACTED"
+	// LeadClosed identify the lead that does not require more actions
+	LeadClosed LeadStatus = "CLOSED"
+)
+
+// LeadStatus type to specify the status of a lead
+type LeadStatus string
+
+// Open retrieves true when the lead still requires an action of the sales team
+func (ls LeadStatus) Open() bool {
+	return ls != LeadClosed
+}
+
+// Contact struct that represents the contact data of an applicant
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// LeadRequest struct that represents a credit line request of the applicant of a lead
+type LeadRequest struct {
+	FoundingType         string       `json:"foundingType"`
+	CashBalance          float64      `json:"cashBalance"`
+	MonthlyRevenue       float64      `json:"monthlyRevenue"`
+	RequestedCreditLine  float64      `json:"requestedCreditLine"`
+	RequestedDate        string       `json:"requestedDate"`
+	CreditStatus         CreditStatus `json:"creditStatus"`
+	CreditLineAuthorized string       `json:"creditLineAuthorized"`
+	DeterminedAt         time.Time    `json:"determinedAt"`
+	Contact              *Contact     `json:"-"`
+}
+
+// Lead struct that represents an applicant that exhausted the decline retries and must be contacted by sales
+type Lead struct {
+	ID               string        `json:"id"`
+	IP               string        `json:"ip"`
+	ApplicantID      string        `json:"applicantId,omitempty"`
+	Status           LeadStatus    `json:"status"`
+	Contact          *Contact      `json:"contact,omitempty"`
+	Requests         []LeadRequest `json:"requests"`
+	DeliveryAttempts uint          `json:"deliveryAttempts"`
+	LastError        string        `json:"lastError,omitempty"`
+	CreatedAt        time.Time     `json:"createdAt"`
+	UpdatedAt        time.Time     `json:"updatedAt"`
+}
+
+// LeadStatusResponse struct that represents the status of a lead for the applicant
+type LeadStatusResponse struct {
+	ID        string     `json:"id"`
+	Status    LeadStatus `json:"status"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// NewLeadRequest creates a new LeadRequest from a credit line and its response
+func NewLeadRequest(creditLine *CreditLine, response *CreditLineResponse, determinedAt time.Time) LeadRequest {
+	return LeadRequest{
+		FoundingType:         creditLine.FoundingType(),
+		CashBalance:          creditLine.CashBalance(),
+		MonthlyRevenue:       creditLine.MonthlyRevenue(),
+		RequestedCreditLine:  creditLine.RequestedCreditLine(),
+		RequestedDate:        creditLine.RequestedDate(),
+		CreditStatus:         response.CreditStatus,
+		CreditLineAuthorized: response.CreditLineAuthorized,
+		DeterminedAt:         determinedAt,
+		Contact:              creditLine.Contact(),
+	}
+}
+
+// NewLeadStatusResponse creates a new pointer of LeadStatusResponse without the data of the applicant
+func NewLeadStatusResponse(lead *Lead) *LeadStatusResponse {
+	return &LeadStatusResponse{
+		ID:        lead.ID,
+		Status:    lead.Status,
+		CreatedAt: lead.CreatedAt,
+		UpdatedAt: lead.UpdatedAt,
+	}
+}
+
+// Key retrieves the key of the requests and the open lead of the applicant, the applicant key of an identified
+// applicant or the ip of an anonymous applicant
+func (l *Lead) Key() string {
+	if l.ApplicantID == "" {
+		return l.IP
+	}
+	return ApplicantKeyPrefix + l.ApplicantID
+}
@@ -0,0 +1,9 @@
+package model
+
+// Envelope struct that represents applicant data encrypted with its own data key, the data key is encrypted with
+// a key of the keyring so a key rotation only encrypts again the data key
+type Envelope struct {
+	KeyID      string `json:"keyId"`
+	DataKey    string `json:"dataKey"`
+	Ciphertext string `json:"ciphertext"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+const (
+	// RetentionPurge identify the retention mode that removes the expired records
+	RetentionPurge RetentionMode = "purge"
+	// RetentionAnonymize identify the retention mode that removes the applicant data of the expired records
+	RetentionAnonymize RetentionMode = "anonymize"
+)
+
+// RetentionMode type to specify how the expired records are removed
+type RetentionMode string
+
+// RetentionCounts struct that represents the records of a store affected by a retention run, the retained
+// records expired but they could not be purged or anonymized
+type RetentionCounts struct {
+	Expired    int `json:"expired"`
+	Purged     int `json:"purged"`
+	Anonymized int `json:"anonymized"`
+	Retained   int `json:"retained"`
+}
+
+// RetentionReport struct that represents the result of a retention run, the counts of a dry run are the records
+// that would be affected
+type RetentionReport struct {
+	StartedAt    time.Time       `json:"startedAt"`
+	DryRun       bool            `json:"dryRun"`
+	Mode         RetentionMode   `json:"mode"`
+	Cutoff       *time.Time      `json:"cutoff,omitempty"`
+	CacheCutoff  *time.Time      `json:"cacheCutoff,omitempty"`
+	Decisions    RetentionCounts `json:"decisions"`
+	AuditEntries RetentionCounts `json:"auditEntries"`
+	Leads        RetentionCounts `json:"leads"`
+	LeadRequests RetentionCounts `json:"leadRequests"`
+	CacheEntries RetentionCounts `json:"cacheEntries"`
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+const (
+	// ReviewPending identify the review that is waiting for a reviewer
+	ReviewPending ReviewStatus = "PENDING"
+	// ReviewAssigned identify the review that is assigned to a reviewer
+	ReviewAssigned ReviewStatus = "ASSIGNED"
+	// ReviewApproved identify the review whose credit line was approved by the reviewer
+	ReviewApproved ReviewStatus = "APPROVED"
+	// ReviewDeclined identify the review whose credit line was declined by the reviewer
+	ReviewDeclined ReviewStatus = "DECLINED"
+
+	// ReviewApprove identify the action of a reviewer that approves the calculated credit line, or the requested
+	// credit line when the calculated one is lower
+	ReviewApprove ReviewAction = "approve"
+	// ReviewDecline identify the action of a reviewer that declines the credit line
+	ReviewDecline ReviewAction = "decline"
+	// ReviewAdjust identify the action of a reviewer that approves an adjusted credit line
+	ReviewAdjust ReviewAction = "adjust"
+
+	// ReviewBand identify the review of a calculated credit line within the band below the requested credit line
+	ReviewBand ReviewReason = "band"
+	// ReviewRisk identify the review of a request routed to manual review by its risk assessment
+	ReviewRisk ReviewReason = "risk"
+)
+
+// ReviewStatus type to specify the status of a review
+type ReviewStatus string
+
+// ReviewAction type to specify the action of a reviewer that resolves a review
+type ReviewAction string
+
+// ReviewReason type to specify the reason a request was routed to manual review
+type ReviewReason string
+
+// Resolved retrieves true when the review was approved or declined
+func (rs ReviewStatus) Resolved() bool {
+	return rs == ReviewApproved || rs == ReviewDeclined
+}
+
+// ReviewNote struct that represents a note of a reviewer in a review
+type ReviewNote struct {
+	Reviewer  string    `json:"reviewer"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Review struct that represents a credit line request waiting for the decision of a reviewer, the inputs of the
+// request are kept to decide it and the ip is only kept to record the final decision
+type Review struct {
+	ID                   string          `json:"id"`
+	DecisionID           string          `json:"decisionId"`
+	Reason               ReviewReason    `json:"reason"`
+	Status               ReviewStatus    `json:"status"`
+	IP                   string          `json:"-"`
+	ApplicantID          string          `json:"applicantId,omitempty"`
+	FoundingType         string          `json:"foundingType"`
+	CashBalance          float64         `json:"cashBalance"`
+	MonthlyRevenue       float64         `json:"monthlyRevenue"`
+	RequestedCreditLine  float64         `json:"requestedCreditLine"`
+	RequestedDate        string          `json:"requestedDate"`
+	CalculatedCreditLine string          `json:"calculatedCreditLine"`
+	Risk                 *RiskAssessment `json:"risk,omitempty"`
+	Reviewer             string          `json:"reviewer,omitempty"`
+	Notes                []ReviewNote    `json:"notes,omitempty"`
+	Action               ReviewAction    `json:"action,omitempty"`
+	CreditLineAuthorized string          `json:"creditLineAuthorized,omitempty"`
+	FinalDecisionID      string          `json:"finalDecisionId,omitempty"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	DueAt                time.Time       `json:"dueAt"`
+	AssignedAt           *time.Time      `json:"assignedAt,omitempty"`
+	ResolvedAt           *time.Time      `json:"resolvedAt,omitempty"`
+	BreachedAt           *time.Time      `json:"breachedAt,omitempty"`
+}
+
+// Overdue retrieves true when the review is not resolved and its due time passed
+func (r *Review) Overdue(now time.Time) bool {
+	return !r.Status.Resolved() && now.After(r.DueAt)
+}
+
+// CreditLine retrieves the credit line of the request of the review to record its final decision
+func (r *Review) CreditLine() *CreditLine {
+	creditLine := NewCreditLine(r.FoundingType, r.RequestedDate, r.CashBalance, r.MonthlyRevenue, r.RequestedCreditLine)
+	if r.ApplicantID != "" {
+		creditLine.SetApplicant(&Applicant{ID: r.ApplicantID}, ApplicationHistory{})
+	}
+	creditLine.SetRisk(r.Risk)
+	return creditLine
+}
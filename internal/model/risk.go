@@ -0,0 +1,39 @@
+package model
+
+const (
+	// VelocitySignal identify the signal of an identity or ip that sent too many requests in the velocity window
+	VelocitySignal RiskSignal = "velocity"
+	// ProbingSignal identify the signal of an identity or ip that increased the cash balance or the monthly
+	// revenue in each of its last requests
+	ProbingSignal RiskSignal = "probing"
+	// ImplausibleSignal identify the signal of a monthly revenue far above the cash balance
+	ImplausibleSignal RiskSignal = "implausible"
+
+	// RiskAllow identify the action of a request that is determined without changes
+	RiskAllow RiskAction = "allow"
+	// RiskFlag identify the action of a request that is determined and flagged in the logs and the decision
+	RiskFlag RiskAction = "flag"
+	// RiskDelay identify the action of a flagged request that is delayed before it is determined
+	RiskDelay RiskAction = "delay"
+	// RiskReview identify the action of a request that is not determined and is routed to manual review
+	RiskReview RiskAction = "review"
+)
+
+// RiskSignal type to specify a signal of the risk assessment
+type RiskSignal string
+
+// RiskAction type to specify the action taken with a request after its risk assessment
+type RiskAction string
+
+// RiskAssessment struct with the score, the signals and the action of the risk assessment of a request
+type RiskAssessment struct {
+	Score   uint         `json:"score"`
+	Signals []RiskSignal `json:"signals,omitempty"`
+	Action  RiskAction   `json:"action"`
+}
+
+// RiskRequest struct with the inputs of a request kept to score the following requests of the same identity or ip
+type RiskRequest struct {
+	CashBalance    float64
+	MonthlyRevenue float64
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// ClientThrottling struct that represents the throttling state of a client
+type ClientThrottling struct {
+	IP               string      `json:"ip"`
+	DeclinedRequests uint        `json:"declinedRequests"`
+	RetriesAllowed   uint        `json:"retriesAllowed"`
+	RetriesRemaining uint        `json:"retriesRemaining"`
+	Allowance        *Allowance  `json:"allowance,omitempty"`
+	Access           string      `json:"access,omitempty"`
+	RateLimits       []RateLimit `json:"rateLimits"`
+}
+
+// Allowance struct that represents the extra decline retries granted to a client
+type Allowance struct {
+	Retries   uint      `json:"retries"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RateLimit struct that represents the state of a rate limit policy for a client
+type RateLimit struct {
+	Policy    string    `json:"policy"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	Reached   bool      `json:"reached"`
+}
+
+// AccessEntry struct that represents a network of the access list
+type AccessEntry struct {
+	CIDR   string `json:"cidr"`
+	Action string `json:"action"`
+	Source string `json:"source,omitempty"`
+}
@@ -0,0 +1,97 @@
+package model
+
+import "time"
+
+const (
+	// DecisionApproved event type of an approved credit line
+	DecisionApproved EventType = "decision.approved"
+	// DecisionDeclined event type of a declined credit line
+	DecisionDeclined EventType = "decision.declined"
+	// RetriesExhausted event type of a declined credit line that exhausted the decline retries of the applicant
+	RetriesExhausted EventType = "retries.exhausted"
+	// ReviewRequested event type of a credit line routed to manual review, its final decision is an approved or
+	// declined event with the same review id
+	ReviewRequested EventType = "review.requested"
+)
+
+const (
+	// DeliveryPending status of a delivery that is being sent
+	DeliveryPending DeliveryStatus = "PENDING"
+	// DeliverySucceeded status of a delivery accepted by the subscriber
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	// DeliveryFailed status of a delivery that exhausted its attempts
+	DeliveryFailed DeliveryStatus = "FAILED"
+)
+
+// EventType type to specify the type of a decision event
+type EventType string
+
+// DeliveryStatus type to specify the status of a webhook delivery
+type DeliveryStatus string
+
+// WebhookSubscription struct that represents a subscriber of the decision events
+type WebhookSubscription struct {
+	ID        string      `json:"id"`
+	URL       string      `json:"url"`
+	Events    []EventType `json:"events"`
+	Secret    string      `json:"secret,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// Event struct that represents a decision event sent to the subscribers
+type Event struct {
+	ID         string        `json:"id"`
+	Type       EventType     `json:"type"`
+	DecisionID string        `json:"decisionId,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	Data       DecisionEvent `json:"data"`
+}
+
+// DecisionEvent struct that represents the data of a credit line decision
+type DecisionEvent struct {
+	FoundingType         string       `json:"foundingType"`
+	RequestedCreditLine  float64      `json:"requestedCreditLine"`
+	RequestedDate        string       `json:"requestedDate"`
+	CreditStatus         CreditStatus `json:"creditStatus"`
+	CreditLineAuthorized string       `json:"creditLineAuthorized"`
+	LeadID               string       `json:"leadId,omitempty"`
+	ReviewID             string       `json:"reviewId,omitempty"`
+}
+
+// WebhookDelivery struct that represents the delivery of an event to a subscriber
+type WebhookDelivery struct {
+	ID             string         `json:"id"`
+	SubscriptionID string         `json:"subscriptionId"`
+	EventID        string         `json:"eventId"`
+	EventType      EventType      `json:"eventType"`
+	RedeliveryOf   string         `json:"redeliveryOf,omitempty"`
+	Status         DeliveryStatus `json:"status"`
+	Attempts       uint           `json:"attempts"`
+	ResponseStatus int            `json:"responseStatus,omitempty"`
+	LastError      string         `json:"lastError,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// NewDecisionEvent creates a new DecisionEvent from a credit line and its response
+func NewDecisionEvent(creditLine *CreditLine, response *CreditLineResponse) DecisionEvent {
+	return DecisionEvent{
+		FoundingType:         creditLine.FoundingType(),
+		RequestedCreditLine:  creditLine.RequestedCreditLine(),
+		RequestedDate:        creditLine.RequestedDate(),
+		CreditStatus:         response.CreditStatus,
+		CreditLineAuthorized: response.CreditLineAuthorized,
+		LeadID:               response.LeadID,
+		ReviewID:             response.ReviewID,
+	}
+}
+
+// Subscribed reports if the subscription receives an event type
+func (ws *WebhookSubscription) Subscribed(eventType EventType) bool {
+	for _, subscribed := range ws.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"credit-line/internal/ledger"
+	"credit-line/internal/model"
+	"credit-line/pkg/account"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+	"credit-line/pkg/outbox"
+)
+
+// AccountService services contracts for the credit line accounts and their movements
+type AccountService interface {
+	Open(ctx context.Context, ip, decisionID string) (*model.Account, error)
+	Authorize(ctx context.Context, id, token string) bool
+	Account(ctx context.Context, id string) (*model.Account, error)
+	Accounts(ctx context.Context) []*model.Account
+	Move(ctx context.Context, id string, movementType model.MovementType, amount model.Cents, reference string) (*model.Movement, bool, error)
+	Movements(ctx context.Context, id string) ([]*model.Movement, error)
+	Close(ctx context.Context, id string) (*model.Account, error)
+	Check(ctx context.Context) *model.LedgerCheck
+}
+
+// accounts struct that implement the AccountService interface with the store of the accounts, the decisions
+// store to accept the approved credit lines and the ledger of the movements
+type accounts struct {
+	store     *account.Store
+	decisions outbox.Store
+	ledger    ledger.Ledger
+	cfg       *env.Accounts
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// NewAccounts creates a new pointer of accounts struct
+func NewAccounts(store *account.Store, decisions outbox.Store, ledger ledger.Ledger, cfg *env.Accounts) *accounts {
+	return &accounts{
+		store:     store,
+		decisions: decisions,
+		ledger:    ledger,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+// Open implement the interface AccountService.Open, an approved credit line is accepted once within the
+// acceptance window by the ip that requested it and its authorized credit line is the limit of the account; the
+// decisions of other ips are not found. The account is retrieved with the token of its requests
+func (as *accounts) Open(ctx context.Context, ip, decisionID string) (*model.Account, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	decision, ok := as.decisions.Decision(decisionID)
+	if !ok || decision.IP == "" || decision.IP != ip {
+		return nil, fmt.Errorf("%w %q", errors.ErrDecisionNotFound, decisionID)
+	}
+	if decision.CreditStatus != model.Approved {
+		return nil, fmt.Errorf("%w %q", errors.ErrDecisionNotApproved, decisionID)
+	}
+	now := as.now().UTC()
+	if as.cfg.AcceptanceWindow > 0 && now.After(decision.DeterminedAt.AddDate(0, 0, int(as.cfg.AcceptanceWindow))) {
+		return nil, fmt.Errorf("%w %q", errors.ErrAcceptanceExpired, decisionID)
+	}
+	if _, ok := as.store.DecisionAccount(decisionID); ok {
+		return nil, fmt.Errorf("%w %q", errors.ErrAccountExists, decisionID)
+	}
+	limit, err := strconv.ParseFloat(decision.CreditLineAuthorized, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorized credit line of the decision %q: %w", decisionID, err)
+	}
+
+	token := generateSecret()
+	opened := model.NewAccount(generateID(), decision, model.NewCents(limit), now)
+	opened.TokenHash = hashToken(token)
+	if err := as.store.Save(opened); err != nil {
+		return nil, fmt.Errorf("account not stored: %w", err)
+	}
+	opened.Token = token
+	metrics.ObserveAccountOpened()
+	logger.FromContext(ctx).InfoContext(ctx, "credit line account opened", "account_id", opened.ID,
+		"decision_id", decisionID)
+	return opened, nil
+}
+
+// Authorize implement the interface AccountService.Authorize, it reports if the token is the token of the account
+func (as *accounts) Authorize(_ context.Context, id, token string) bool {
+	opened, ok := as.store.Account(id)
+	if !ok || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(opened.TokenHash)) == 1
+}
+
+// Account implement the interface AccountService.Account
+func (as *accounts) Account(_ context.Context, id string) (*model.Account, error) {
+	opened, ok := as.store.Account(id)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errors.ErrAccountNotFound, id)
+	}
+	return opened, nil
+}
+
+// Accounts implement the interface AccountService.Accounts, newest first
+func (as *accounts) Accounts(_ context.Context) []*model.Account {
+	return as.store.Accounts()
+}
+
+// Move implement the interface AccountService.Move, a drawdown can not exceed the available balance and a
+// repayment can not exceed the outstanding balance; the movement, the balances of the account and the ledger
+// transaction are stored together before the transaction is posted, so the ledger rebuilt from the store posts
+// it again. A movement with the reference of a previous movement of the same type and amount retrieves the
+// previous movement and false without posting it again
+func (as *accounts) Move(ctx context.Context, id string, movementType model.MovementType, amount model.Cents,
+	reference string) (*model.Movement, bool, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	opened, err := as.Account(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if reference != "" {
+		if previous, ok := as.store.ReferenceMovement(id, reference); ok {
+			if previous.Type != movementType || previous.Amount != amount {
+				return nil, false, fmt.Errorf("%w %q", errors.ErrReferenceConflict, reference)
+			}
+			return previous, false, nil
+		}
+	}
+	if opened.Status == model.AccountClosed {
+		return nil, false, fmt.Errorf("%w %q", errors.ErrAccountClosed, id)
+	}
+	switch {
+	case movementType == model.Drawdown && amount > opened.Available:
+		return nil, false, fmt.Errorf("%w: %s of %s", errors.ErrInsufficientAvailable, amount, opened.Available)
+	case movementType == model.Repayment && amount > opened.Outstanding:
+		return nil, false, fmt.Errorf("%w: %s of %s", errors.ErrRepaymentExceedsOutstanding, amount, opened.Outstanding)
+	}
+
+	now := as.now().UTC()
+	movement := &model.Movement{
+		ID:        generateID(),
+		AccountID: id,
+		Type:      movementType,
+		Amount:    amount,
+		Reference: reference,
+		CreatedAt: now,
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, fmt.Errorf("movement not posted: %w", err)
+	}
+	transaction := ledger.NewTransaction(generateID(), movement, now)
+	opened.Apply(movement)
+	movement.Outstanding, movement.TransactionID = opened.Outstanding, transaction.ID
+	if err := as.store.SaveMovement(opened, movement, transaction); err != nil {
+		return nil, false, fmt.Errorf("movement not stored: %w", err)
+	}
+	// the stored movement is posted even when the request is canceled, so the ledger is not behind the balances
+	if err := as.ledger.Post(context.WithoutCancel(ctx), transaction); err != nil {
+		return nil, false, fmt.Errorf("movement not posted: %w", err)
+	}
+	metrics.ObserveMovement(movementType)
+	logger.FromContext(ctx).InfoContext(ctx, "credit line account movement posted", "account_id", id,
+		"movement_id", movement.ID, "type", movementType)
+	return movement, true, nil
+}
+
+// Movements implement the interface AccountService.Movements, from the oldest to the newest
+func (as *accounts) Movements(ctx context.Context, id string) ([]*model.Movement, error) {
+	if _, err := as.Account(ctx, id); err != nil {
+		return nil, err
+	}
+	return as.store.Movements(id), nil
+}
+
+// Close implement the interface AccountService.Close, only an open account without outstanding balance is
+// closed
+func (as *accounts) Close(ctx context.Context, id string) (*model.Account, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	opened, err := as.Account(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if opened.Status == model.AccountClosed {
+		return nil, fmt.Errorf("%w %q", errors.ErrAccountClosed, id)
+	}
+	if opened.Outstanding != 0 {
+		return nil, fmt.Errorf("%w %q", errors.ErrAccountOutstanding, id)
+	}
+
+	now := as.now().UTC()
+	opened.Status, opened.Available, opened.ClosedAt = model.AccountClosed, 0, &now
+	if err := as.store.Save(opened); err != nil {
+		return nil, fmt.Errorf("account not closed: %w", err)
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "credit line account closed", "account_id", id)
+	return opened, nil
+}
+
+// Check implement the interface AccountService.Check, besides the checks of the ledger the outstanding balance
+// of each account must be the balance of its receivable ledger account and must be within its limit
+func (as *accounts) Check(ctx context.Context) *model.LedgerCheck {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	check := as.ledger.Check(ctx)
+	for _, opened := range as.store.Accounts() {
+		check.Accounts++
+		balance := as.ledger.Balance(ledger.ReceivableAccount(opened.ID))
+		if balance != opened.Outstanding || opened.Outstanding < 0 || opened.Outstanding > opened.Limit {
+			check.Mismatches = append(check.Mismatches, model.LedgerMismatch{AccountID: opened.ID,
+				Outstanding: opened.Outstanding, LedgerBalance: balance, Limit: opened.Limit})
+		}
+	}
+	check.Consistent = check.Consistent && len(check.Mismatches) == 0
+	check.CheckedAt = as.now().UTC()
+	return check
+}
+
+// Watch checks the consistency of the ledger every interval until the context is done, an inconsistent ledger
+// is logged and counted
+func (as *accounts) Watch(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if check := as.Check(ctx); !check.Consistent {
+				metrics.ObserveLedgerInconsistency()
+				l.Error("ledger inconsistent", "unbalanced", check.Unbalanced, "drifted", check.Drifted,
+					"mismatches", len(check.Mismatches), "debits", check.Debits.String(), "credits", check.Credits.String())
+			}
+		}
+	}
+}
+
+// hashToken retrieves the hex sha256 hash of the token of an account
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"credit-line/internal/ledger"
+	"credit-line/internal/model"
+	"credit-line/pkg/account"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/outbox"
+)
+
+func newAccountsTest(t *testing.T, decisions ...*model.Decision) (*accounts, time.Time) {
+	t.Helper()
+	store := outbox.NewMemoryStore()
+	for _, decision := range decisions {
+		if err := store.Save(decision, nil); err != nil {
+			t.Fatalf("unexpected error saving decision: %v", err)
+		}
+	}
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	s := NewAccounts(account.NewStore(), store, ledger.NewLedger(), &env.Accounts{AcceptanceWindow: 30, CheckInterval: 300})
+	s.now = func() time.Time { return now }
+	return s, now
+}
+
+func Test_Account_Service_Open(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	decisions := []*model.Decision{
+		{ID: "approved", IP: "192.0.2.10", CreditStatus: model.Approved, CreditLineAuthorized: "145.10", ApplicantID: "a1", DeterminedAt: now.AddDate(0, 0, -1)},
+		{ID: "declined", IP: "192.0.2.10", CreditStatus: model.Declined, CreditLineAuthorized: "0.00", DeterminedAt: now},
+		{ID: "expired", IP: "192.0.2.10", CreditStatus: model.Approved, CreditLineAuthorized: "145.10", DeterminedAt: now.AddDate(0, 0, -31)},
+		{ID: "anonymized", CreditStatus: model.Approved, CreditLineAuthorized: "145.10", DeterminedAt: now},
+	}
+
+	testCases := map[string]struct {
+		ip            string
+		decisionID    string
+		expectedLimit model.Cents
+		expectedError error
+	}{
+		"approved": {
+			decisionID:    "approved",
+			expectedLimit: 14510,
+		},
+		"not_found": {
+			decisionID:    "missing",
+			expectedError: pkgerrors.ErrDecisionNotFound,
+		},
+		"decision_of_other_ip": {
+			ip:            "198.51.100.7",
+			decisionID:    "approved",
+			expectedError: pkgerrors.ErrDecisionNotFound,
+		},
+		"anonymized_decision": {
+			decisionID:    "anonymized",
+			expectedError: pkgerrors.ErrDecisionNotFound,
+		},
+		"declined": {
+			decisionID:    "declined",
+			expectedError: pkgerrors.ErrDecisionNotApproved,
+		},
+		"acceptance_expired": {
+			decisionID:    "expired",
+			expectedError: pkgerrors.ErrAcceptanceExpired,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newAccountsTest(t, decisions...)
+			ip := tc.ip
+			if ip == "" {
+				ip = "192.0.2.10"
+			}
+			opened, err := s.Open(context.Background(), ip, tc.decisionID)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if tc.expectedError != nil {
+				return
+			}
+			if opened.Status != model.AccountOpen || opened.Limit != tc.expectedLimit || opened.Available != tc.expectedLimit ||
+				opened.Outstanding != 0 || opened.ApplicantID != "a1" {
+				t.Errorf("unexpected account, got: %+v", opened)
+			}
+			if _, err := s.Open(context.Background(), ip, tc.decisionID); !errors.Is(err, pkgerrors.ErrAccountExists) {
+				t.Errorf("unexpected error opening the account again, got: %v", err)
+			}
+
+			// only the token retrieved when the account is opened authenticates its requests
+			stored, _ := s.Account(context.Background(), opened.ID)
+			if opened.Token == "" || stored.Token != "" {
+				t.Fatalf("unexpected token, opened: %q, stored: %q", opened.Token, stored.Token)
+			}
+			if !s.Authorize(context.Background(), opened.ID, opened.Token) {
+				t.Errorf("unexpected unauthorized token of the account")
+			}
+			if s.Authorize(context.Background(), opened.ID, "") || s.Authorize(context.Background(), opened.ID, opened.Token+"0") ||
+				s.Authorize(context.Background(), "missing", opened.Token) {
+				t.Errorf("unexpected authorized token")
+			}
+		})
+	}
+}
+
+func Test_Account_Service_Move(t *testing.T) {
+	type move struct {
+		movementType model.MovementType
+		amount       model.Cents
+		reference    string
+	}
+
+	testCases := map[string]struct {
+		moves               []move
+		expectedCreated     bool
+		expectedOutstanding model.Cents
+		expectedMovements   int
+		expectedError       error
+	}{
+		"drawdown": {
+			moves:               []move{{model.Drawdown, 10000, "d1"}},
+			expectedCreated:     true,
+			expectedOutstanding: 10000,
+			expectedMovements:   1,
+		},
+		"drawdown_of_the_whole_limit": {
+			moves:               []move{{model.Drawdown, 14510, ""}},
+			expectedCreated:     true,
+			expectedOutstanding: 14510,
+			expectedMovements:   1,
+		},
+		"drawdown_above_available": {
+			moves:               []move{{model.Drawdown, 10000, ""}, {model.Drawdown, 4511, ""}},
+			expectedOutstanding: 10000,
+			expectedMovements:   1,
+			expectedError:       pkgerrors.ErrInsufficientAvailable,
+		},
+		"repayment": {
+			moves:               []move{{model.Drawdown, 10000, ""}, {model.Repayment, 2550, ""}},
+			expectedCreated:     true,
+			expectedOutstanding: 7450,
+			expectedMovements:   2,
+		},
+		"repayment_above_outstanding": {
+			moves:               []move{{model.Drawdown, 10000, ""}, {model.Repayment, 10001, ""}},
+			expectedOutstanding: 10000,
+			expectedMovements:   1,
+			expectedError:       pkgerrors.ErrRepaymentExceedsOutstanding,
+		},
+		"retried_reference": {
+			moves:               []move{{model.Drawdown, 10000, "d1"}, {model.Drawdown, 10000, "d1"}},
+			expectedOutstanding: 10000,
+			expectedMovements:   1,
+		},
+		"reference_of_another_movement": {
+			moves:               []move{{model.Drawdown, 10000, "d1"}, {model.Repayment, 10000, "d1"}},
+			expectedOutstanding: 10000,
+			expectedMovements:   1,
+			expectedError:       pkgerrors.ErrReferenceConflict,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s, now := newAccountsTest(t, &model.Decision{ID: "approved", IP: "192.0.2.10", CreditStatus: model.Approved,
+				CreditLineAuthorized: "145.10", DeterminedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)})
+			opened, err := s.Open(context.Background(), "192.0.2.10", "approved")
+			if err != nil {
+				t.Fatalf("unexpected error opening the account: %v", err)
+			}
+
+			var created bool
+			for _, m := range tc.moves {
+				_, created, err = s.Move(context.Background(), opened.ID, m.movementType, m.amount, m.reference)
+			}
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if created != tc.expectedCreated {
+				t.Errorf("unexpected created, got: %v, expected: %v", created, tc.expectedCreated)
+			}
+
+			updated, _ := s.Account(context.Background(), opened.ID)
+			if updated.Outstanding != tc.expectedOutstanding || updated.Available != updated.Limit-tc.expectedOutstanding {
+				t.Errorf("unexpected balances, got: %+v, expected outstanding: %v", updated, tc.expectedOutstanding)
+			}
+			movements, _ := s.Movements(context.Background(), opened.ID)
+			if len(movements) != tc.expectedMovements {
+				t.Errorf("unexpected movements, got: %v, expected: %v", len(movements), tc.expectedMovements)
+			}
+			check := s.Check(context.Background())
+			if !check.Consistent || check.Transactions != tc.expectedMovements || !check.CheckedAt.Equal(now) {
+				t.Errorf("unexpected check, got: %+v", check)
+			}
+		})
+	}
+}
+
+func Test_Account_Service_Close(t *testing.T) {
+	testCases := map[string]struct {
+		drawdown      model.Cents
+		repayment     model.Cents
+		expectedError error
+	}{
+		"without_outstanding": {},
+		"repaid": {
+			drawdown:  5000,
+			repayment: 5000,
+		},
+		"with_outstanding": {
+			drawdown:      5000,
+			repayment:     4999,
+			expectedError: pkgerrors.ErrAccountOutstanding,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newAccountsTest(t, &model.Decision{ID: "approved", IP: "192.0.2.10", CreditStatus: model.Approved,
+				CreditLineAuthorized: "145.10", DeterminedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)})
+			opened, _ := s.Open(context.Background(), "192.0.2.10", "approved")
+			if tc.drawdown > 0 {
+				_, _, _ = s.Move(context.Background(), opened.ID, model.Drawdown, tc.drawdown, "")
+				_, _, _ = s.Move(context.Background(), opened.ID, model.Repayment, tc.repayment, "")
+			}
+
+			closed, err := s.Close(context.Background(), opened.ID)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if tc.expectedError != nil {
+				return
+			}
+			if closed.Status != model.AccountClosed || closed.Available != 0 || closed.ClosedAt == nil {
+				t.Errorf("unexpected closed account, got: %+v", closed)
+			}
+			if _, _, err := s.Move(context.Background(), opened.ID, model.Drawdown, 100, ""); !errors.Is(err, pkgerrors.ErrAccountClosed) {
+				t.Errorf("unexpected error drawing a closed account, got: %v", err)
+			}
+			if _, err := s.Close(context.Background(), opened.ID); !errors.Is(err, pkgerrors.ErrAccountClosed) {
+				t.Errorf("unexpected error closing the account again, got: %v", err)
+			}
+		})
+	}
+}
+
+func Test_Account_Service_Check_Mismatch(t *testing.T) {
+	s, _ := newAccountsTest(t, &model.Decision{ID: "approved", IP: "192.0.2.10", CreditStatus: model.Approved,
+		CreditLineAuthorized: "145.10", DeterminedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)})
+	opened, _ := s.Open(context.Background(), "192.0.2.10", "approved")
+	if _, _, err := s.Move(context.Background(), opened.ID, model.Drawdown, 5000, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opened.Outstanding = 4000
+	s.store.Save(opened)
+
+	check := s.Check(context.Background())
+	if check.Consistent || check.Accounts != 1 || len(check.Mismatches) != 1 {
+		t.Fatalf("unexpected check, got: %+v", check)
+	}
+	expected := model.LedgerMismatch{AccountID: opened.ID, Outstanding: 4000, LedgerBalance: 5000, Limit: 14510}
+	if check.Mismatches[0] != expected {
+		t.Errorf("unexpected mismatch, got: %+v, expected: %+v", check.Mismatches[0], expected)
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/applicant"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+)
+
+// ApplicantScreener contract to identify the applicants of the credit line requests before the determination
+type ApplicantScreener interface {
+	Screen(ctx context.Context, ip string, creditLine *model.CreditLine) error
+}
+
+// applicants struct that implement the ApplicantScreener interface with the store of the recent applications
+type applicants struct {
+	store     *applicant.Store
+	cfg       *env.Applicants
+	exhausted func(key string) bool
+	now       func() time.Time
+}
+
+// NewApplicants creates a new pointer of applicants struct, exhausted reports if the key of an applicant
+// exhausted the decline retries
+func NewApplicants(store *applicant.Store, cfg *env.Applicants, exhausted func(key string) bool) *applicants {
+	return &applicants{
+		store:     store,
+		cfg:       cfg,
+		exhausted: exhausted,
+		now:       time.Now,
+	}
+}
+
+// Screen implement the interface ApplicantScreener.Screen, the applicant of a request with identifiers is
+// identified by the hashes of its identifiers and its repeat applications from other ips are detected; it
+// retrieves errors.ErrRetriesExhausted when the applicant exhausted the decline retries from any ip. The
+// identifiers are ignored without a hash key, their unkeyed hashes could be reversed from the public identifiers
+func (as *applicants) Screen(ctx context.Context, ip string, creditLine *model.CreditLine) error {
+	identifiers := creditLine.Identifiers()
+	if identifiers == nil {
+		return nil
+	}
+	if as.cfg.HashKey == "" {
+		logger.FromContext(ctx).DebugContext(ctx, "applicant identifiers ignored without a hash key")
+		return nil
+	}
+	identified := applicant.Identify([]byte(as.cfg.HashKey), *identifiers)
+	if identified == nil {
+		return nil
+	}
+
+	now := as.now().UTC()
+	history := as.store.Record(identified, ip, now, now.Add(-time.Minute*time.Duration(as.cfg.DuplicateWindow)))
+	creditLine.SetApplicant(identified, history)
+	log := logger.FromContext(ctx).With("applicant_id", identified.ID)
+	if history.Repeat {
+		metrics.ObserveRepeatApplication()
+		log.WarnContext(ctx, "repeat application detected", "applications", history.Applications, "ips", history.IPs)
+	}
+
+	if as.exhausted(identified.Key()) {
+		log.InfoContext(ctx, "applicant exhausted the decline retries")
+		return errors.ErrRetriesExhausted
+	}
+	return nil
+}
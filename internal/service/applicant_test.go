@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/applicant"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+)
+
+func Test_Applicant_Service_Screen(t *testing.T) {
+	type application struct {
+		ip          string
+		identifiers *model.ApplicantIdentifiers
+		after       time.Duration
+	}
+
+	testCases := map[string]struct {
+		previous          []application
+		current           application
+		withoutHashKey    bool
+		exhaustedKeys     []string
+		expectedApplicant bool
+		expectedHistory   model.ApplicationHistory
+		expectedError     error
+	}{
+		"anonymous_request": {
+			previous: []application{
+				{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			},
+			current: application{ip: "192.0.2.11"},
+		},
+		"identifiers_without_hash_key": {
+			previous: []application{
+				{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			},
+			current:        application{ip: "192.0.2.11", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			withoutHashKey: true,
+		},
+		"first_application": {
+			current:           application{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			expectedApplicant: true,
+		},
+		"same_ip_is_not_a_repeat": {
+			previous: []application{
+				{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			},
+			current:           application{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			expectedApplicant: true,
+			expectedHistory:   model.ApplicationHistory{Applications: 1},
+		},
+		"repeat_from_other_ips_with_normalized_identifiers": {
+			previous: []application{
+				{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+				{ip: "192.0.2.11", identifiers: &model.ApplicantIdentifiers{LegalName: "Acme, S.A. de C.V.", Email: "Owner@Acme.mx"}},
+			},
+			current: application{ip: "192.0.2.12", identifiers: &model.ApplicantIdentifiers{
+				TaxID: "abc-010203-xy9", LegalName: "ACME", Email: "owner@acme.mx",
+			}},
+			expectedApplicant: true,
+			expectedHistory:   model.ApplicationHistory{Applications: 2, IPs: 2, Repeat: true},
+		},
+		"repeat_outside_the_window": {
+			previous: []application{
+				{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			},
+			current: application{ip: "192.0.2.11", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"},
+				after: 2 * time.Hour},
+			expectedApplicant: true,
+		},
+		"retries_exhausted_from_other_ip": {
+			previous: []application{
+				{ip: "192.0.2.10", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			},
+			current:           application{ip: "192.0.2.11", identifiers: &model.ApplicantIdentifiers{TaxID: "ABC010203XY9"}},
+			exhaustedKeys:     []string{"ABC010203XY9"},
+			expectedApplicant: true,
+			expectedHistory:   model.ApplicationHistory{Applications: 1, IPs: 1, Repeat: true},
+			expectedError:     pkgerrors.ErrRetriesExhausted,
+		},
+	}
+
+	cfg := &env.Applicants{HashKey: "0123456789abcdef", DuplicateWindow: 60}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exhausted := make(map[string]bool)
+			s := NewApplicants(applicant.NewStore(), cfg, func(key string) bool { return exhausted[key] })
+			if tc.withoutHashKey {
+				s.cfg = &env.Applicants{DuplicateWindow: cfg.DuplicateWindow}
+			}
+			start := time.Now()
+			s.now = func() time.Time { return start }
+
+			ctx := context.Background()
+			for _, previous := range tc.previous {
+				creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+				creditLine.SetIdentifiers(previous.identifiers)
+				if err := s.Screen(ctx, previous.ip, creditLine); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			// the retries are exhausted after the previous applications
+			for _, taxID := range tc.exhaustedKeys {
+				identified := applicant.Identify([]byte(cfg.HashKey), model.ApplicantIdentifiers{TaxID: taxID})
+				exhausted[identified.Key()] = true
+			}
+			s.now = func() time.Time { return start.Add(tc.current.after) }
+			creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+			creditLine.SetIdentifiers(tc.current.identifiers)
+			err := s.Screen(ctx, tc.current.ip, creditLine)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+
+			if (creditLine.Applicant() != nil) != tc.expectedApplicant {
+				t.Fatalf("unexpected applicant, got: %+v", creditLine.Applicant())
+			}
+			if history := creditLine.History(); history != tc.expectedHistory {
+				t.Errorf("unexpected history, got: %+v, expected: %+v", history, tc.expectedHistory)
+			}
+			expectedKey, expectedKeys := tc.current.ip, []string{tc.current.ip}
+			if tc.expectedApplicant {
+				expectedKey = creditLine.Applicant().Key()
+				expectedKeys = append(expectedKeys, expectedKey)
+			}
+			if key := creditLine.RetryKey(tc.current.ip); key != expectedKey {
+				t.Errorf("unexpected retry key, got: %v, expected: %v", key, expectedKey)
+			}
+			if keys := creditLine.RetryKeys(tc.current.ip); !reflect.DeepEqual(keys, expectedKeys) {
+				t.Errorf("unexpected retry keys, got: %v, expected: %v", keys, expectedKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/errors"
+)
+
+// AuditService services contracts for the audit log of the decisions
+type AuditService interface {
+	Proof(ctx context.Context, decisionID string) (*model.InclusionProof, error)
+}
+
+// auditService struct that implement the AuditService interface
+type auditService struct {
+	log *audit.Log
+}
+
+// NewAudit creates a new pointer of auditService struct
+func NewAudit(log *audit.Log) *auditService {
+	return &auditService{
+		log: log,
+	}
+}
+
+// Proof implement the interface AuditService.Proof, the proof is built against the current tree of the log
+func (as *auditService) Proof(_ context.Context, decisionID string) (*model.InclusionProof, error) {
+	proof, ok, err := as.log.Proof(decisionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrAuditEntryNotFound, decisionID)
+	}
+	return proof, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/certificate"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/outbox"
+)
+
+// recordDecisions records a number of decisions and retrieves their ids
+func recordDecisions(t *testing.T, ctx context.Context, auditLog *audit.Log, n int) []string {
+	t.Helper()
+	s := NewDecisions(outbox.NewMemoryStore(), auditLog, testPolicy, func(ip string) bool { return false })
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, float64(100*(i+1)))
+		response := model.NewCreditLineResponse(model.Approved, "1305.90")
+		if err := s.Record(ctx, "192.0.2.10", creditLine, response); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, response.DecisionID)
+	}
+	return ids
+}
+
+func Test_Audit_Service_Proof(t *testing.T) {
+	testCases := map[string]struct {
+		decisions int
+	}{
+		"one_decision":       {decisions: 1},
+		"two_decisions":      {decisions: 2},
+		"three_decisions":    {decisions: 3},
+		"seven_decisions":    {decisions: 7},
+		"eight_decisions":    {decisions: 8},
+		"thirteen_decisions": {decisions: 13},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			auditLog := newAuditLog(t)
+			ids := recordDecisions(t, context.Background(), auditLog, tc.decisions)
+			s := NewAudit(auditLog)
+
+			var root string
+			for i, id := range ids {
+				proof, err := s.Proof(context.Background(), id)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if proof.LeafIndex != uint64(i) || proof.TreeSize != uint64(tc.decisions) || proof.Entry.DecisionID != id {
+					t.Fatalf("unexpected proof: %+v", proof)
+				}
+				if root != "" && proof.RootHash != root {
+					t.Fatalf("unexpected root hash, got: %v, expected: %v", proof.RootHash, root)
+				}
+				root = proof.RootHash
+				if err := audit.VerifyProof(proof); err != nil {
+					t.Fatalf("unexpected error verifying the proof of %v: %v", i, err)
+				}
+
+				// a modified entry or path is not included in the tree
+				modified := *proof
+				modified.Entry.Outcome.CreditLineAuthorized = "99999.00"
+				if err := audit.VerifyProof(&modified); !errors.Is(err, audit.ErrInvalidProof) {
+					t.Errorf("unexpected error verifying a modified entry: %v", err)
+				}
+				if len(proof.AuditPath) > 0 {
+					modified = *proof
+					modified.AuditPath = append([]string{strings.Repeat("0", 64)}, proof.AuditPath[1:]...)
+					if err := audit.VerifyProof(&modified); !errors.Is(err, audit.ErrInvalidProof) {
+						t.Errorf("unexpected error verifying a modified path: %v", err)
+					}
+				}
+			}
+
+			if _, err := s.Proof(context.Background(), "unknown"); !errors.Is(err, pkgerrors.ErrAuditEntryNotFound) {
+				t.Errorf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrAuditEntryNotFound)
+			}
+		})
+	}
+}
+
+func Test_Audit_Log_Entry(t *testing.T) {
+	auditLog := newAuditLog(t)
+	ctx := certificate.WithIdentity(context.Background(), &certificate.Identity{Subject: "partner-a", Fingerprint: "ab12"})
+	id := recordDecisions(t, ctx, auditLog, 1)[0]
+
+	proof, err := NewAudit(auditLog).Proof(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := model.AuditClient{IP: "192.0.2.10", CertificateSubject: "partner-a", CertificateFingerprint: "ab12"}
+	if proof.Entry.Client != expected {
+		t.Errorf("unexpected client, got: %+v, expected: %+v", proof.Entry.Client, expected)
+	}
+	if proof.Entry.Policy != testPolicy() || proof.Entry.Policy.Version == "" {
+		t.Errorf("unexpected policy: %+v", proof.Entry.Policy)
+	}
+	if proof.Entry.Inputs.CashBalance != 435.30 || proof.Entry.Outcome.CreditStatus != model.Approved {
+		t.Errorf("unexpected entry: %+v", proof.Entry)
+	}
+	if proof.Entry.PrevHash != strings.Repeat("0", 64) || proof.HeadHash != proof.Entry.Hash {
+		t.Errorf("unexpected chain, prev hash: %v, head hash: %v", proof.Entry.PrevHash, proof.HeadHash)
+	}
+}
+
+func Test_Audit_Log_Tampering(t *testing.T) {
+	testCases := map[string]struct {
+		tamper func(lines [][]byte) [][]byte
+	}{
+		"modified_outcome": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte(`"APPROVED"`), []byte(`"DECLINED"`), 1)
+				return lines
+			},
+		},
+		"modified_entry_with_hash": {
+			tamper: func(lines [][]byte) [][]byte {
+				var entry model.AuditEntry
+				json.Unmarshal(lines[1], &entry)
+				entry.Inputs.MonthlyRevenue = 100000
+				rehashed, _ := audit.EntryHash(entry)
+				entry.Hash = rehashed
+				lines[1], _ = json.Marshal(entry)
+				return lines
+			},
+		},
+		"removed_entry": {
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+		},
+		"reordered_entries": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+		},
+		"truncated_entry": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[2] = lines[2][:len(lines[2])/2]
+				return lines
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			auditLog, err := audit.Open(path, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			recordDecisions(t, context.Background(), auditLog, 4)
+			auditLog.Close()
+
+			content, _ := os.ReadFile(path)
+			if report, err := audit.Verify(bytes.NewReader(content)); err != nil || report.Entries != 4 {
+				t.Fatalf("unexpected verification before the tampering: %v %v", report, err)
+			}
+
+			lines := tc.tamper(bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n")))
+			tampered := append(bytes.Join(lines, []byte("\n")), '\n')
+			if err := os.WriteFile(path, tampered, 0o600); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := audit.Verify(bytes.NewReader(tampered)); !errors.Is(err, audit.ErrChainBroken) {
+				t.Errorf("unexpected error, got: %v, expected: %v", err, audit.ErrChainBroken)
+			}
+			if _, err := audit.Open(path, nil); !errors.Is(err, audit.ErrChainBroken) {
+				t.Errorf("unexpected error opening the log, got: %v, expected: %v", err, audit.ErrChainBroken)
+			}
+		})
+	}
+}
+
+func Test_Audit_Log_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := recordDecisions(t, context.Background(), auditLog, 3)
+	auditLog.Close()
+
+	auditLog, err = audit.Open(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer auditLog.Close()
+	recordDecisions(t, context.Background(), auditLog, 2)
+
+	proof, err := NewAudit(auditLog).Proof(context.Background(), first[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proof.TreeSize != 5 || proof.LeafIndex != 1 {
+		t.Errorf("unexpected proof: %+v", proof)
+	}
+	if err := audit.VerifyProof(proof); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	report, err := audit.Verify(bytes.NewReader(content))
+	if err != nil || report.Entries != 5 || report.RootHash != proof.RootHash || report.HeadHash != proof.HeadHash {
+		t.Errorf("unexpected report: %+v %v", report, err)
+	}
+}
@@ -3,11 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
 	"reflect"
 	"testing"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"credit-line/internal/calculator"
 	"credit-line/internal/model"
+	"credit-line/pkg/cache"
 	"credit-line/pkg/errors"
 )
 
@@ -19,9 +27,83 @@ func (mclc *mockCreditLineCalculator) CalculateCreditLine(ctx context.Context, f
 	return mclc.calculateCreditLine(ctx, foundingType, cashBalance, monthlyRevenue)
 }
 
+type mockApplicantScreener struct {
+	screen func(ctx context.Context, ip string, creditLine *model.CreditLine) error
+}
+
+func (mas *mockApplicantScreener) Screen(ctx context.Context, ip string, creditLine *model.CreditLine) error {
+	if mas.screen == nil {
+		return nil
+	}
+	return mas.screen(ctx, ip, creditLine)
+}
+
+type mockRiskAssessor struct {
+	assess func(ctx context.Context, ip string, creditLine *model.CreditLine) error
+}
+
+func (mra *mockRiskAssessor) Assess(ctx context.Context, ip string, creditLine *model.CreditLine) error {
+	if mra.assess == nil {
+		return nil
+	}
+	return mra.assess(ctx, ip, creditLine)
+}
+
+type mockReviewSubmitter struct {
+	submit func(ctx context.Context, ip string, creditLine *model.CreditLine, amount float64) (*model.CreditLineResponse, bool, error)
+}
+
+func (mrs *mockReviewSubmitter) Submit(ctx context.Context, ip string, creditLine *model.CreditLine, amount float64) (*model.CreditLineResponse, bool, error) {
+	if mrs.submit == nil {
+		return nil, false, nil
+	}
+	return mrs.submit(ctx, ip, creditLine, amount)
+}
+
+type mockLeadTracker struct {
+	capture func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string
+	track   func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead
+}
+
+func (mlt *mockLeadTracker) Capture(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string {
+	if mlt.capture == nil {
+		return ""
+	}
+	return mlt.capture(ctx, ip, creditLine, response)
+}
+
+func (mlt *mockLeadTracker) Release(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) {
+}
+
+func (mlt *mockLeadTracker) Track(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead {
+	if mlt.track == nil {
+		return nil
+	}
+	return mlt.track(ctx, ip, creditLine, response)
+}
+
+type mockDecisionRecorder struct {
+	record func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error
+}
+
+func (mdr *mockDecisionRecorder) Record(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error {
+	if mdr.record == nil {
+		return nil
+	}
+	return mdr.record(ctx, ip, creditLine, response)
+}
+
 func Test_Determine_Credit_Limit_Service(t *testing.T) {
+	timedOutCtx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
 	testCases := map[string]struct {
 		calculator calculator.CreditLineCalculator
+		applicants ApplicantScreener
+		risks      RiskAssessor
+		reviews    ReviewSubmitter
+		leads      LeadTracker
+		decisions  DecisionRecorder
 		params     struct {
 			ctx        context.Context
 			ip         string
@@ -47,6 +129,92 @@ func Test_Determine_Credit_Limit_Service(t *testing.T) {
 			},
 			expectedError: fmt.Errorf("determination failed: %w", errors.ErrInvalidFoundingType),
 		},
+		"applicant_retries_exhausted": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 1450.10, nil
+				},
+			},
+			applicants: &mockApplicantScreener{
+				screen: func(ctx context.Context, ip string, creditLine *model.CreditLine) error {
+					return errors.ErrRetriesExhausted
+				},
+			},
+			params: struct {
+				ctx        context.Context
+				ip         string
+				creditLine *model.CreditLine
+			}{
+				ctx:        context.Background(),
+				ip:         "167.222.20.251",
+				creditLine: model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100),
+			},
+			expectedError: errors.ErrRetriesExhausted,
+		},
+		"routed_to_manual_review": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 1450.10, nil
+				},
+			},
+			reviews: &mockReviewSubmitter{
+				submit: func(ctx context.Context, ip string, creditLine *model.CreditLine, amount float64) (*model.CreditLineResponse, bool, error) {
+					response := model.NewCreditLineResponse(model.PendingReview, "0.00")
+					response.ReviewID = "5b1e"
+					return response, true, nil
+				},
+			},
+			params: struct {
+				ctx        context.Context
+				ip         string
+				creditLine *model.CreditLine
+			}{
+				ctx:        context.Background(),
+				ip:         "167.222.20.251",
+				creditLine: model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100),
+			},
+			expectedResponse: &model.CreditLineResponse{CreditStatus: model.PendingReview, CreditLineAuthorized: "0.00",
+				ReviewID: "5b1e"},
+		},
+		"review_not_stored": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 95.10, nil
+				},
+			},
+			reviews: &mockReviewSubmitter{
+				submit: func(ctx context.Context, ip string, creditLine *model.CreditLine, amount float64) (*model.CreditLineResponse, bool, error) {
+					return nil, true, fmt.Errorf("decision not stored: %w", os.ErrClosed)
+				},
+			},
+			params: struct {
+				ctx        context.Context
+				ip         string
+				creditLine *model.CreditLine
+			}{
+				ctx:        context.Background(),
+				ip:         "167.222.20.251",
+				creditLine: model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100),
+			},
+			expectedError: fmt.Errorf("decision not stored: %w", os.ErrClosed),
+		},
+		"credit_line_determination_timed_out": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 1450.10, nil
+				},
+			},
+			params: struct {
+				ctx        context.Context
+				ip         string
+				creditLine *model.CreditLine
+			}{
+				ctx:        timedOutCtx,
+				ip:         "167.222.20.251",
+				creditLine: model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100),
+			},
+			expectedError: fmt.Errorf("determination cancelled: %w", context.DeadlineExceeded),
+		},
 		"credit_line_approved_SME": {
 			calculator: &mockCreditLineCalculator{
 				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
@@ -115,11 +283,75 @@ func Test_Determine_Credit_Limit_Service(t *testing.T) {
 			},
 			expectedResponse: model.NewCreditLineResponse(model.Declined, "0.00"),
 		},
+		"credit_line_declined_lead_captured": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 847.09, nil
+				},
+			},
+			leads: &mockLeadTracker{
+				capture: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string {
+					return "3f2a"
+				},
+			},
+			params: struct {
+				ctx        context.Context
+				ip         string
+				creditLine *model.CreditLine
+			}{
+				ctx:        context.Background(),
+				ip:         "167.222.20.251",
+				creditLine: model.NewCreditLine("Startup", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000),
+			},
+			expectedResponse: &model.CreditLineResponse{CreditStatus: model.Declined, CreditLineAuthorized: "0.00", LeadID: "3f2a"},
+		},
+		"credit_line_decision_not_stored": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 145.10, nil
+				},
+			},
+			decisions: &mockDecisionRecorder{
+				record: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error {
+					return fmt.Errorf("decision not stored: %w", os.ErrClosed)
+				},
+			},
+			params: struct {
+				ctx        context.Context
+				ip         string
+				creditLine *model.CreditLine
+			}{
+				ctx:        context.Background(),
+				ip:         "167.222.20.251",
+				creditLine: model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100),
+			},
+			expectedError: fmt.Errorf("decision not stored: %w", os.ErrClosed),
+		},
 	}
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			service := NewCreditLine(tc.calculator)
+			leads := tc.leads
+			if leads == nil {
+				leads = &mockLeadTracker{}
+			}
+			decisions := tc.decisions
+			if decisions == nil {
+				decisions = &mockDecisionRecorder{}
+			}
+			applicants := tc.applicants
+			if applicants == nil {
+				applicants = &mockApplicantScreener{}
+			}
+			risks := tc.risks
+			if risks == nil {
+				risks = &mockRiskAssessor{}
+			}
+			reviews := tc.reviews
+			if reviews == nil {
+				reviews = &mockReviewSubmitter{}
+			}
+			service := NewCreditLine(tc.calculator, applicants, risks, reviews, leads, decisions)
 			got, err := service.DetermineCreditLimit(tc.params.ctx, tc.params.ip, tc.params.creditLine)
 
 			if tc.expectedError == nil && err != nil {
@@ -140,3 +372,161 @@ func Test_Determine_Credit_Limit_Service(t *testing.T) {
 		})
 	}
 }
+
+func Test_Determine_Credit_Limit_Service_Decision_Not_Stored(t *testing.T) {
+	calculator := &mockCreditLineCalculator{
+		func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+			return 0, nil
+		},
+	}
+	tracked := 0
+	leads := &mockLeadTracker{
+		capture: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string {
+			return "3f2a"
+		},
+		track: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead {
+			tracked++
+			return nil
+		},
+	}
+	decisions := &mockDecisionRecorder{
+		record: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error {
+			return os.ErrClosed
+		},
+	}
+	service := NewCreditLine(calculator, &mockApplicantScreener{}, &mockRiskAssessor{}, &mockReviewSubmitter{}, leads,
+		decisions)
+
+	ip := "198.51.100.48"
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+	if _, err := service.DetermineCreditLimit(context.Background(), ip, creditLine); err == nil {
+		t.Fatalf("got nil error expecting: %v", os.ErrClosed)
+	}
+
+	// the decline of a decision that was not stored is not counted and its lead is not captured
+	if failures := cache.RetrieveRequestCache().Failures(ip); failures != 0 {
+		t.Errorf("unexpected declines of the ip, got: %v, expected: 0", failures)
+	}
+	if tracked != 0 {
+		t.Errorf("unexpected tracked requests, got: %v, expected: 0", tracked)
+	}
+}
+
+func Test_Determine_Credit_Limit_Service_Retry_Keys(t *testing.T) {
+	calculator := &mockCreditLineCalculator{
+		func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+			return 0, nil
+		},
+	}
+	ip, requests := "198.51.100.47", 0
+	applicants := &mockApplicantScreener{
+		screen: func(ctx context.Context, ip string, creditLine *model.CreditLine) error {
+			// each request sends new identifiers
+			requests++
+			creditLine.SetApplicant(&model.Applicant{ID: fmt.Sprintf("retry-keys-%d", requests)}, model.ApplicationHistory{})
+			return nil
+		},
+	}
+	service := NewCreditLine(calculator, applicants, &mockRiskAssessor{}, &mockReviewSubmitter{}, &mockLeadTracker{},
+		&mockDecisionRecorder{})
+
+	var applicantKeys []string
+	for i := 0; i < 3; i++ {
+		creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+		if _, err := service.DetermineCreditLimit(context.Background(), ip, creditLine); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		applicantKeys = append(applicantKeys, creditLine.Applicant().Key())
+	}
+
+	// the declines of the ip are counted although the applicant changes in each request
+	if failures := cache.RetrieveRequestCache().Failures(ip); failures != 3 {
+		t.Errorf("unexpected declines of the ip, got: %v, expected: 3", failures)
+	}
+	for _, key := range applicantKeys {
+		if failures := cache.RetrieveRequestCache().Failures(key); failures != 1 {
+			t.Errorf("unexpected declines of %s, got: %v, expected: 1", key, failures)
+		}
+		cache.ResetClient(key)
+	}
+	cache.ResetClient(ip)
+}
+
+func Test_Determine_Credit_Limit_Service_Tracing(t *testing.T) {
+	testCases := map[string]struct {
+		calculator         calculator.CreditLineCalculator
+		creditLine         *model.CreditLine
+		expectedAttributes map[attribute.Key]string
+		expectedError      bool
+	}{
+		"approved_span": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 1450.10, nil
+				},
+			},
+			creditLine: model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 4350.30, 4235.45, 100),
+			expectedAttributes: map[attribute.Key]string{
+				"credit.founding_type":            "SME",
+				"credit.status":                   "APPROVED",
+				"credit.requested_amount_bucket":  "0-1k",
+				"credit.authorized_amount_bucket": "1k-10k",
+			},
+		},
+		"declined_span": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 847.09, nil
+				},
+			},
+			creditLine: model.NewCreditLine("Startup", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 25000),
+			expectedAttributes: map[attribute.Key]string{
+				"credit.founding_type":            "Startup",
+				"credit.status":                   "DECLINED",
+				"credit.requested_amount_bucket":  "10k-100k",
+				"credit.authorized_amount_bucket": "0",
+			},
+		},
+		"failed_span": {
+			calculator: &mockCreditLineCalculator{
+				func(ctx context.Context, foundingType string, cashBalance, monthlyRevenue float64) (float64, error) {
+					return 0, errors.ErrInvalidFoundingType
+				},
+			},
+			creditLine: model.NewCreditLine("SMA", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100),
+			expectedAttributes: map[attribute.Key]string{
+				"credit.founding_type":           "SMA",
+				"credit.requested_amount_bucket": "0-1k",
+			},
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+			service := NewCreditLine(tc.calculator, &mockApplicantScreener{}, &mockRiskAssessor{}, &mockReviewSubmitter{},
+				&mockLeadTracker{}, &mockDecisionRecorder{})
+			_, _ = service.DetermineCreditLimit(context.Background(), "167.222.20.251", tc.creditLine)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 || spans[0].Name() != "service.DetermineCreditLimit" {
+				t.Fatalf("unexpected spans, got: %v", spans)
+			}
+
+			got := make(map[attribute.Key]string)
+			for _, attr := range spans[0].Attributes() {
+				got[attr.Key] = attr.Value.AsString()
+			}
+			if !reflect.DeepEqual(tc.expectedAttributes, got) {
+				t.Fatalf("unexpected attributes, got: %v, expected: %v", got, tc.expectedAttributes)
+			}
+
+			if gotError := spans[0].Status().Code == codes.Error; gotError != tc.expectedError {
+				t.Fatalf("unexpected span status, got: %v", spans[0].Status())
+			}
+		})
+	}
+}
@@ -4,9 +4,16 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"credit-line/internal/calculator"
 	"credit-line/internal/model"
 	"credit-line/pkg/cache"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+	"credit-line/pkg/tracing"
 )
 
 // CreditLineService services contracts for the credit line entity
@@ -14,30 +21,134 @@ type CreditLineService interface {
 	DetermineCreditLimit(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error)
 }
 
+// LeadTracker contract to record the requests of the applicants and capture their leads, the id of a lead is
+// captured before the decision is stored and the lead is tracked after, or released when the decision is not stored
+type LeadTracker interface {
+	Capture(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string
+	Release(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse)
+	Track(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead
+}
+
 // creditLine struct that implement the CreditLineService interface
 type creditLine struct {
 	calculator calculator.CreditLineCalculator
+	applicants ApplicantScreener
+	risks      RiskAssessor
+	reviews    ReviewSubmitter
+	leads      LeadTracker
+	decisions  DecisionRecorder
 }
 
-// NewCreditLine creates a new pointer of creditLine struct
-func NewCreditLine(calculator calculator.CreditLineCalculator) *creditLine {
+// NewCreditLine creates a new pointer of creditLine struct, the events of the stored decisions are notified to the
+// subscribers by the outbox relay
+func NewCreditLine(calculator calculator.CreditLineCalculator, applicants ApplicantScreener, risks RiskAssessor,
+	reviews ReviewSubmitter, leads LeadTracker, decisions DecisionRecorder) *creditLine {
 	return &creditLine{
 		calculator: calculator,
+		applicants: applicants,
+		risks:      risks,
+		reviews:    reviews,
+		leads:      leads,
+		decisions:  decisions,
 	}
 }
 
 // DetermineCreditLimit implement the interface CreditLineService.DetermineCreditLimit
 func (cl *creditLine) DetermineCreditLimit(ctx context.Context, ip string, creditLine *model.CreditLine) (*model.CreditLineResponse, error) {
+	ctx, span := tracing.Start(ctx, "service.DetermineCreditLimit",
+		attribute.String("credit.founding_type", creditLine.FoundingType()),
+		attribute.String("credit.requested_amount_bucket", tracing.AmountBucket(creditLine.RequestedCreditLine())))
+	defer span.End()
+
+	// the identified applicants are also limited by applicant, besides the ip limits of the middlewares
+	if err := cl.applicants.Screen(ctx, ip, creditLine); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "applicant rejected")
+		return nil, err
+	}
+	// the declines are counted by ip and by applicant, a request is rejected when any of them is exhausted
+	retryKeys := creditLine.RetryKeys(ip)
+
+	// the risky requests are flagged or delayed before the calculator is reached
+	err := cl.risks.Assess(ctx, ip, creditLine)
+	if risk := creditLine.Risk(); risk != nil {
+		span.SetAttributes(attribute.Int("risk.score", int(risk.Score)), attribute.String("risk.action", string(risk.Action)))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "risk assessment rejected")
+		return nil, err
+	}
+
 	amount, err := cl.calculator.CalculateCreditLine(ctx, creditLine.FoundingType(), creditLine.CashBalance(), creditLine.MonthlyRevenue())
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "determination failed")
+		logger.FromContext(ctx).WarnContext(ctx, "credit line could not be determined",
+			"founding_type", creditLine.FoundingType(), "error", err)
 		return nil, fmt.Errorf("determination failed: %w", err)
 	}
 
+	// the decision is not stored when the request timed out or was cancelled during the determination
+	if err := ctx.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "determination cancelled")
+		logger.FromContext(ctx).WarnContext(ctx, "credit line determination cancelled",
+			"founding_type", creditLine.FoundingType(), "error", err)
+		return nil, fmt.Errorf("determination cancelled: %w", err)
+	}
+
+	// the borderline and the risky requests wait the decision of a reviewer, their retries are not counted yet
+	response, routed, err := cl.reviews.Submit(ctx, ip, creditLine, amount)
+	if err != nil {
+		return nil, cl.recordFailed(ctx, span, err)
+	}
+	if routed {
+		metrics.ObserveDecision(creditLine.FoundingType(), model.PendingReview, 0)
+		span.SetAttributes(attribute.String("credit.status", string(model.PendingReview)))
+		logger.FromContext(ctx).InfoContext(ctx, "credit line determined", "founding_type", creditLine.FoundingType(),
+			"credit_status", model.PendingReview)
+		return response, nil
+	}
+
+	// the decline retries and the leads are only updated once the decision is stored
+	log := logger.FromContext(ctx).With("founding_type", creditLine.FoundingType())
 	if amount > creditLine.RequestedCreditLine() {
-		cache.UpdateRequestCache(model.Approved, ip)
-		return model.NewCreditLineResponse(model.Approved, fmt.Sprintf("%.2f", amount)), nil
+		response := model.NewCreditLineResponse(model.Approved, fmt.Sprintf("%.2f", amount))
+		if err := cl.decisions.Record(ctx, ip, creditLine, response); err != nil {
+			return nil, cl.recordFailed(ctx, span, err)
+		}
+		cache.UpdateRequestCache(model.Approved, retryKeys...)
+		cl.leads.Track(ctx, ip, creditLine, response)
+		metrics.ObserveDecision(creditLine.FoundingType(), model.Approved, amount)
+		span.SetAttributes(
+			attribute.String("credit.status", string(model.Approved)),
+			attribute.String("credit.authorized_amount_bucket", tracing.AmountBucket(amount)))
+		log.InfoContext(ctx, "credit line determined", "credit_status", model.Approved)
+		return response, nil
+	}
+
+	response = model.NewCreditLineResponse(model.Declined, "0.00")
+	// the applicant is contacted by a sales agent when the declined request exhausts the retries
+	response.LeadID = cl.leads.Capture(ctx, ip, creditLine, response)
+	if err := cl.decisions.Record(ctx, ip, creditLine, response); err != nil {
+		cl.leads.Release(ctx, ip, creditLine, response)
+		return nil, cl.recordFailed(ctx, span, err)
 	}
+	cache.UpdateRequestCache(model.Declined, retryKeys...)
+	cl.leads.Track(ctx, ip, creditLine, response)
+	metrics.ObserveDecision(creditLine.FoundingType(), model.Declined, amount)
+	span.SetAttributes(
+		attribute.String("credit.status", string(model.Declined)),
+		attribute.String("credit.authorized_amount_bucket", tracing.AmountBucket(0)))
+	log.InfoContext(ctx, "credit line determined", "credit_status", model.Declined)
+	return response, nil
+}
 
-	cache.UpdateRequestCache(model.Declined, ip)
-	return model.NewCreditLineResponse(model.Declined, "0.00"), nil
+// recordFailed records in the span and the logs a decision that could not be stored
+func (cl *creditLine) recordFailed(ctx context.Context, span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, "decision not stored")
+	logger.FromContext(ctx).ErrorContext(ctx, "credit line decision could not be stored", "error", err)
+	return err
 }
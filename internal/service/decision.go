@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/certificate"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/outbox"
+)
+
+// DecisionRecorder contract to store the credit line decisions with their events
+type DecisionRecorder interface {
+	Record(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error
+}
+
+// decisions struct that implement the DecisionRecorder interface with the outbox of the decision store and the
+// audit log
+type decisions struct {
+	store     outbox.Store
+	auditLog  *audit.Log
+	policy    func() model.Policy
+	exhausted func(key string) bool
+}
+
+// NewDecisions creates a new pointer of decisions struct, policy retrieves the policy applied to the decisions and
+// exhausted reports if a decline of the ip or the applicant key exhausts the decline retries, the decisions are
+// stored before their declines are counted
+func NewDecisions(store outbox.Store, auditLog *audit.Log, policy func() model.Policy,
+	exhausted func(key string) bool) *decisions {
+	return &decisions{
+		store:     store,
+		auditLog:  auditLog,
+		policy:    policy,
+		exhausted: exhausted,
+	}
+}
+
+// Record implement the interface DecisionRecorder.Record, the decision is stored with its events atomically and
+// then appended to the audit log, so the log never holds a decision that was not stored; the events are published
+// later by the outbox relay
+func (ds *decisions) Record(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error {
+	now := time.Now().UTC()
+	response.DecisionID = generateID()
+	decision := model.NewDecision(response.DecisionID, ip, creditLine, response, now)
+	data := model.NewDecisionEvent(creditLine, response)
+
+	eventTypes := decisionEventTypes(response, retriesExhausted(ds.exhausted, creditLine.RetryKeys(ip)))
+	events := make([]model.Event, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		events = append(events, model.Event{ID: generateID(), Type: eventType, DecisionID: decision.ID, CreatedAt: now, Data: data})
+	}
+
+	if err := ds.store.Save(decision, events); err != nil {
+		return fmt.Errorf("decision not stored: %w", err)
+	}
+	entry, err := ds.auditLog.Append(model.NewAuditEntry(decision, creditLine, auditClient(ctx, ip, creditLine.Applicant()), ds.policy()))
+	if err != nil {
+		return fmt.Errorf("decision not audited: %w", err)
+	}
+	logger.FromContext(ctx).DebugContext(ctx, "decision stored", "decision_id", decision.ID, "events", len(events),
+		"audit_index", entry.Index)
+	return nil
+}
+
+// auditClient retrieves the identity of the client of a decision, the certificate is only known with mutual TLS
+// and the applicant when the request has identifiers
+func auditClient(ctx context.Context, ip string, applicant *model.Applicant) model.AuditClient {
+	client := model.AuditClient{IP: ip}
+	if applicant != nil {
+		client.ApplicantID = applicant.ID
+	}
+	if identity := certificate.IdentityFromContext(ctx); identity != nil {
+		client.CertificateSubject = identity.Subject
+		client.CertificateFingerprint = identity.Fingerprint
+	}
+	return client
+}
+
+// retriesExhausted reports if any ip or applicant key of a request exhausted the decline retries
+func retriesExhausted(exhausted func(key string) bool, keys []string) bool {
+	for _, key := range keys {
+		if exhausted(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// decisionEventTypes retrieves the event types of a decision, a declined request that exhausted the decline
+// retries also has the retries.exhausted event
+func decisionEventTypes(response *model.CreditLineResponse, exhausted bool) []model.EventType {
+	switch response.CreditStatus {
+	case model.Approved:
+		return []model.EventType{model.DecisionApproved}
+	case model.PendingReview:
+		return []model.EventType{model.ReviewRequested}
+	}
+	if exhausted {
+		return []model.EventType{model.DecisionDeclined, model.RetriesExhausted}
+	}
+	return []model.EventType{model.DecisionDeclined}
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/outbox"
+)
+
+// flakyStore outbox store that fails to mark the published events a number of times
+type flakyStore struct {
+	outbox.Store
+	failures int
+}
+
+func (fs *flakyStore) MarkPublished(ids []string) error {
+	if fs.failures > 0 {
+		fs.failures--
+		return os.ErrClosed
+	}
+	return fs.Store.MarkPublished(ids)
+}
+
+// failingStore outbox store that fails to save the decisions
+type failingStore struct {
+	outbox.Store
+}
+
+func (fs *failingStore) Save(decision *model.Decision, events []model.Event) error {
+	return os.ErrClosed
+}
+
+// flakyBroker broker that fails to publish a number of messages
+type flakyBroker struct {
+	outbox.Broker
+	failures int
+}
+
+func (fb *flakyBroker) Publish(ctx context.Context, msg outbox.Message) error {
+	if fb.failures > 0 {
+		fb.failures--
+		return errors.New("broker unavailable")
+	}
+	return fb.Broker.Publish(ctx, msg)
+}
+
+// testPolicy policy of the decisions of the tests
+func testPolicy() model.Policy {
+	return model.NewPolicy(3, 5, 3)
+}
+
+// newAuditLog opens an audit log in a temporary directory
+func newAuditLog(t *testing.T) *audit.Log {
+	t.Helper()
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+	return auditLog
+}
+
+func Test_Decision_Service_Record(t *testing.T) {
+	testCases := map[string]struct {
+		response         *model.CreditLineResponse
+		exhausted        bool
+		expectedSubjects []string
+	}{
+		"approved": {
+			response:         model.NewCreditLineResponse(model.Approved, "145.10"),
+			expectedSubjects: []string{"decisions.decision.approved"},
+		},
+		"declined": {
+			response:         model.NewCreditLineResponse(model.Declined, "0.00"),
+			expectedSubjects: []string{"decisions.decision.declined"},
+		},
+		"declined_retries_exhausted": {
+			response:         &model.CreditLineResponse{CreditStatus: model.Declined, CreditLineAuthorized: "0.00", LeadID: "3f2a"},
+			exhausted:        true,
+			expectedSubjects: []string{"decisions.decision.declined", "decisions.retries.exhausted"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			store := outbox.NewMemoryStore()
+			broker := outbox.NewMemoryBroker()
+			s := NewDecisions(store, newAuditLog(t), testPolicy, func(ip string) bool { return tc.exhausted })
+
+			creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+			if err := s.Record(context.Background(), "192.0.2.10", creditLine, tc.response); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pending := store.Pending(10)
+			if len(pending) != len(tc.expectedSubjects) {
+				t.Fatalf("unexpected pending events, got: %v, expected: %v", len(pending), len(tc.expectedSubjects))
+			}
+			decision, ok := store.Decision(pending[0].Event.DecisionID)
+			if !ok || decision.CreditStatus != tc.response.CreditStatus || decision.LeadID != tc.response.LeadID {
+				t.Fatalf("unexpected decision: %v", decision)
+			}
+
+			relay := outbox.NewRelay(store, broker, "decisions", 10)
+			if n, err := relay.Flush(context.Background()); err != nil || n != len(tc.expectedSubjects) {
+				t.Fatalf("unexpected flush, got: %v %v", n, err)
+			}
+			subjects := make([]string, 0)
+			for _, msg := range broker.Messages() {
+				subjects = append(subjects, msg.Subject)
+			}
+			if !reflect.DeepEqual(tc.expectedSubjects, subjects) {
+				t.Errorf("unexpected subjects, got: %v, expected: %v", subjects, tc.expectedSubjects)
+			}
+			if pending := store.Pending(10); len(pending) != 0 {
+				t.Errorf("unexpected pending events after the flush: %v", pending)
+			}
+		})
+	}
+}
+
+func Test_Decision_Service_Record_Not_Stored_Is_Not_Audited(t *testing.T) {
+	auditLog := newAuditLog(t)
+	s := NewDecisions(&failingStore{outbox.NewMemoryStore()}, auditLog, testPolicy, func(ip string) bool { return false })
+
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+	response := model.NewCreditLineResponse(model.Approved, "145.10")
+	if err := s.Record(context.Background(), "192.0.2.10", creditLine, response); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("unexpected error, got: %v, expected: %v", err, os.ErrClosed)
+	}
+	if _, ok, err := auditLog.Proof(response.DecisionID); err != nil || ok {
+		t.Fatalf("unexpected audit entry of a decision that was not stored, got: %v %v", ok, err)
+	}
+
+	// the next decision is the first entry of the log
+	s.store = outbox.NewMemoryStore()
+	if err := s.Record(context.Background(), "192.0.2.10", creditLine, response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proof, ok, err := auditLog.Proof(response.DecisionID)
+	if err != nil || !ok || proof.LeafIndex != 0 || proof.TreeSize != 1 {
+		t.Fatalf("unexpected proof, got: %+v %v %v", proof, ok, err)
+	}
+}
+
+func Test_Decision_Outbox_Relay_At_Least_Once(t *testing.T) {
+	ctx := context.Background()
+	store := &flakyStore{Store: outbox.NewMemoryStore(), failures: 1}
+	broker := outbox.NewMemoryBroker()
+	s := NewDecisions(store, newAuditLog(t), testPolicy, func(ip string) bool { return false })
+	for i := 0; i < 3; i++ {
+		creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, float64(100*(i+1)))
+		if err := s.Record(ctx, "192.0.2.10", creditLine, model.NewCreditLineResponse(model.Approved, "145.10")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the events are published again when they could not be marked as published, the broker dedupes them
+	relay := outbox.NewRelay(store, broker, "decisions", 10)
+	if _, err := relay.Flush(ctx); err == nil {
+		t.Fatalf("got nil error expecting the mark error")
+	}
+	if pending := store.Pending(10); len(pending) != 3 {
+		t.Fatalf("unexpected pending events, got: %v, expected: 3", len(pending))
+	}
+
+	// the relay stops at the first failure to keep the order of the events
+	flaky := &flakyBroker{Broker: broker, failures: 1}
+	relay = outbox.NewRelay(store, flaky, "decisions", 10)
+	if n, err := relay.Flush(ctx); err == nil || n != 0 {
+		t.Fatalf("unexpected flush, got: %v %v", n, err)
+	}
+	if pending := store.Pending(10); len(pending) != 3 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("unexpected pending events after the failure: %v", pending)
+	}
+
+	if n, err := relay.Flush(ctx); err != nil || n != 3 {
+		t.Fatalf("unexpected flush, got: %v %v", n, err)
+	}
+	if messages := broker.Messages(); len(messages) != 3 {
+		t.Fatalf("unexpected messages, got: %v, expected: 3 deduped messages", len(messages))
+	}
+}
+
+func Test_Decision_File_Store_Replay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	store, err := outbox.NewFileStore(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := NewDecisions(store, newAuditLog(t), testPolicy, func(ip string) bool { return false })
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+	for i := 0; i < 2; i++ {
+		if err := s.Record(ctx, "192.0.2.10", creditLine, model.NewCreditLineResponse(model.Approved, "145.10")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := outbox.NewRelay(store, outbox.NewMemoryBroker(), "decisions", 1).Flush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pending := store.Pending(10)
+	if len(pending) != 1 {
+		t.Fatalf("unexpected pending events, got: %v, expected: 1", len(pending))
+	}
+	store.Close()
+
+	// a crash while a decision was written leaves a torn line that is discarded
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	f.WriteString(`{"decision":{"id":"torn"`)
+	f.Close()
+
+	store, err = outbox.NewFileStore(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+	replayed := store.Pending(10)
+	if len(replayed) != 1 || replayed[0].Event.ID != pending[0].Event.ID {
+		t.Fatalf("unexpected replayed events, got: %v, expected: %v", replayed, pending)
+	}
+	if _, ok := store.Decision(pending[0].Event.DecisionID); !ok {
+		t.Fatalf("the decision of the pending event was not replayed")
+	}
+	if _, ok := store.Decision("torn"); ok {
+		t.Fatalf("the torn decision must be discarded")
+	}
+	if err := s.Record(ctx, "192.0.2.10", creditLine, model.NewCreditLineResponse(model.Approved, "145.10")); err == nil {
+		t.Fatalf("got nil error expecting the closed store error")
+	}
+	s = NewDecisions(store, newAuditLog(t), testPolicy, func(ip string) bool { return false })
+	if err := s.Record(ctx, "192.0.2.10", creditLine, model.NewCreditLineResponse(model.Approved, "145.10")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "torn") {
+		t.Errorf("the torn line must be truncated, got: %s", content)
+	}
+}
+
+// fakeNatsMessage message received by the fake NATS server with the connection that sent it
+type fakeNatsMessage struct {
+	conn    int
+	subject string
+	headers string
+	payload string
+}
+
+// fakeNats fake NATS server, each connection is greeted with the INFO and the PING sent after each message is
+// answered with the reply of the message, an empty reply closes the connection
+type fakeNats struct {
+	listener net.Listener
+	headers  bool
+	reply    func(n int) string
+	mu       sync.Mutex
+	connects []map[string]interface{}
+	messages []fakeNatsMessage
+}
+
+func newFakeNats(t *testing.T, headers bool, reply func(n int) string) *fakeNats {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	fn := &fakeNats{listener: listener, headers: headers, reply: reply}
+	go func() {
+		for conn := 0; ; conn++ {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go fn.serve(conn, c)
+		}
+	}()
+	return fn
+}
+
+func (fn *fakeNats) serve(conn int, c net.Conn) {
+	defer c.Close()
+	fmt.Fprintf(c, "INFO {\"server_id\":\"test\",\"headers\":%t}\r\n", fn.headers)
+	reader := bufio.NewReader(c)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[0] == "CONNECT":
+			var options map[string]interface{}
+			_ = json.Unmarshal([]byte(fields[1]), &options)
+			fn.mu.Lock()
+			fn.connects = append(fn.connects, options)
+			fn.mu.Unlock()
+		case len(fields) == 3 && fields[0] == "PUB", len(fields) == 4 && fields[0] == "HPUB":
+			headerSize, totalSize := 0, 0
+			if fields[0] == "HPUB" {
+				headerSize, _ = strconv.Atoi(fields[2])
+			}
+			totalSize, _ = strconv.Atoi(fields[len(fields)-1])
+			msg := make([]byte, totalSize+2)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			fn.mu.Lock()
+			fn.messages = append(fn.messages, fakeNatsMessage{conn: conn, subject: fields[1],
+				headers: string(msg[:headerSize]), payload: string(msg[headerSize:totalSize])})
+			fn.mu.Unlock()
+		case len(fields) == 1 && fields[0] == "PING":
+			fn.mu.Lock()
+			reply := fn.reply(len(fn.messages) - 1)
+			fn.mu.Unlock()
+			if reply == "" {
+				return
+			}
+			fmt.Fprint(c, reply)
+		}
+	}
+}
+
+func Test_Decision_Outbox_Nats_Broker(t *testing.T) {
+	testCases := map[string]struct {
+		headers          bool
+		reply            func(n int) string
+		expectedErrors   []string
+		expectedConnects int
+	}{
+		"headers": {
+			headers:          true,
+			reply:            func(int) string { return "PONG\r\n" },
+			expectedErrors:   []string{"", ""},
+			expectedConnects: 1,
+		},
+		"without_headers": {
+			reply:            func(int) string { return "PONG\r\n" },
+			expectedErrors:   []string{"", ""},
+			expectedConnects: 1,
+		},
+		"ok_and_server_ping_before_the_pong": {
+			headers:          true,
+			reply:            func(int) string { return "+OK\r\nPING\r\nPONG\r\n" },
+			expectedErrors:   []string{"", ""},
+			expectedConnects: 1,
+		},
+		"error_reconnects": {
+			headers: true,
+			reply: func(n int) string {
+				if n == 0 {
+					return "-ERR 'Permissions Violation for Publish'\r\n"
+				}
+				return "PONG\r\n"
+			},
+			expectedErrors:   []string{"nats error: 'Permissions Violation for Publish'", ""},
+			expectedConnects: 2,
+		},
+		"closed_connection_reconnects": {
+			headers: true,
+			reply: func(n int) string {
+				if n == 0 {
+					return ""
+				}
+				return "PONG\r\n"
+			},
+			expectedErrors:   []string{"failed to confirm the nats message", ""},
+			expectedConnects: 2,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := newFakeNats(t, tc.headers, tc.reply)
+			broker, err := outbox.NewNatsBroker("nats://user:secret@" + server.listener.Addr().String())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer broker.Close()
+
+			for i, expectedError := range tc.expectedErrors {
+				id := fmt.Sprintf("e%d", i+1)
+				payload, _ := json.Marshal(model.Event{ID: id, Type: model.DecisionApproved})
+				err := broker.Publish(context.Background(), outbox.Message{ID: id, Subject: "decisions.decision.approved", Payload: payload})
+				if expectedError == "" && err != nil {
+					t.Fatalf("unexpected error publishing %s: %v", id, err)
+				}
+				if expectedError != "" && (err == nil || !strings.Contains(err.Error(), expectedError)) {
+					t.Fatalf("unexpected error publishing %s, got: %v, expected: %s", id, err, expectedError)
+				}
+			}
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if len(server.connects) != tc.expectedConnects {
+				t.Fatalf("unexpected connects, got: %d, expected: %d", len(server.connects), tc.expectedConnects)
+			}
+			for _, options := range server.connects {
+				if options["user"] != "user" || options["pass"] != "secret" || options["headers"] != tc.headers ||
+					options["verbose"] != false {
+					t.Errorf("unexpected connect options, got: %v", options)
+				}
+			}
+			last := server.messages[len(server.messages)-1]
+			if len(server.messages) != len(tc.expectedErrors) || last.conn != tc.expectedConnects-1 ||
+				last.subject != "decisions.decision.approved" || !strings.Contains(last.payload, `"id":"e2"`) {
+				t.Fatalf("unexpected messages, got: %+v", server.messages)
+			}
+			if hasHeader := strings.Contains(last.headers, "Nats-Msg-Id: e2"); hasHeader != tc.headers {
+				t.Errorf("unexpected headers, got: %q", last.headers)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/lead"
+	"credit-line/pkg/logger"
+)
+
+const (
+	// leadDeliveryTimeout max time of each attempt to send a lead
+	leadDeliveryTimeout = 15 * time.Second
+	// leadDeliveryBackoff wait time before the second attempt to send a lead, it is doubled after each attempt
+	leadDeliveryBackoff = time.Second
+)
+
+// LeadService services contracts for the leads of the applicants that exhausted the decline retries
+type LeadService interface {
+	Capture(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string
+	Release(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse)
+	Track(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead
+	Lead(ctx context.Context, id string) (*model.Lead, error)
+	Leads(ctx context.Context) []*model.Lead
+	UpdateLeadStatus(ctx context.Context, id string, status model.LeadStatus) (*model.Lead, error)
+}
+
+// leads struct that implement the LeadService interface
+type leads struct {
+	store     *lead.Store
+	sink      lead.Sink
+	cfg       *env.Leads
+	exhausted func(key string) bool
+	wg        sync.WaitGroup
+	shutdown  context.Context
+	abort     context.CancelFunc
+	backoff   time.Duration
+}
+
+// NewLeads creates a new pointer of leads struct, exhausted reports if a decline of the ip or the applicant key
+// exhausts the decline retries, the leads are captured before the decline is counted, and the leads are only
+// stored when the sink is nil
+func NewLeads(store *lead.Store, sink lead.Sink, cfg *env.Leads, exhausted func(key string) bool) *leads {
+	shutdown, abort := context.WithCancel(context.Background())
+	return &leads{
+		store:     store,
+		sink:      sink,
+		cfg:       cfg,
+		exhausted: exhausted,
+		shutdown:  shutdown,
+		abort:     abort,
+		backoff:   leadDeliveryBackoff,
+	}
+}
+
+// Capture implement the interface LeadService.Capture, it retrieves the id of the lead to capture when the
+// declined request exhausts the decline retries of the ip or of the applicant and the ip or the applicant does not
+// have an open lead, empty otherwise; the id is reserved as the open lead of the key, so the concurrent declines
+// of the key do not capture another lead, and the lead is not stored until the request is tracked
+func (ls *leads) Capture(_ context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) string {
+	if response.CreditStatus != model.Declined || !retriesExhausted(ls.exhausted, creditLine.RetryKeys(ip)) {
+		return ""
+	}
+	id, reserved := ls.store.ReserveOpenLead(creditLine.RetryKey(ip), generateID())
+	if !reserved {
+		return ""
+	}
+	return id
+}
+
+// Release implement the interface LeadService.Release, it releases the lead captured for a decision that was not
+// stored so the next decline of the key can capture it
+func (ls *leads) Release(_ context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) {
+	if response.LeadID != "" {
+		ls.store.ReleaseOpenLead(creditLine.RetryKey(ip), response.LeadID)
+	}
+}
+
+// Track implement the interface LeadService.Track, it records the request of a stored decision and captures the
+// lead of the response, the request of the ip, or of the applicant when it is identified, is recorded even when
+// the response has no lead
+func (ls *leads) Track(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) *model.Lead {
+	now := time.Now().UTC()
+	key := creditLine.RetryKey(ip)
+	ls.store.RecordRequest(key, model.NewLeadRequest(creditLine, response, now), ls.cfg.History)
+	if response.LeadID == "" {
+		return nil
+	}
+
+	captured := &model.Lead{
+		ID:        response.LeadID,
+		IP:        ip,
+		Status:    model.LeadNew,
+		Requests:  ls.store.Requests(key),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if applicant := creditLine.Applicant(); applicant != nil {
+		captured.ApplicantID = applicant.ID
+	}
+	for _, request := range captured.Requests {
+		if request.Contact != nil {
+			captured.Contact = request.Contact
+		}
+	}
+	ls.store.Save(captured)
+	logger.FromContext(ctx).InfoContext(ctx, "lead captured", "lead_id", captured.ID,
+		"with_contact", captured.Contact != nil)
+
+	if ls.sink != nil {
+		ls.wg.Add(1)
+		go ls.deliver(logger.WithLogger(ls.shutdown, logger.FromContext(ctx)), captured.ID)
+	}
+	return captured
+}
+
+// Lead implement the interface LeadService.Lead
+func (ls *leads) Lead(_ context.Context, id string) (*model.Lead, error) {
+	captured, ok := ls.store.Lead(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrLeadNotFound, id)
+	}
+	return captured, nil
+}
+
+// Leads implement the interface LeadService.Leads
+func (ls *leads) Leads(_ context.Context) []*model.Lead {
+	return ls.store.Leads()
+}
+
+// UpdateLeadStatus implement the interface LeadService.UpdateLeadStatus
+func (ls *leads) UpdateLeadStatus(ctx context.Context, id string, status model.LeadStatus) (*model.Lead, error) {
+	captured, ok := ls.store.Update(id, func(lead *model.Lead) {
+		lead.Status = status
+		lead.UpdatedAt = time.Now().UTC()
+	})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrLeadNotFound, id)
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "lead status updated", "lead_id", id, "status", status)
+	return captured, nil
+}
+
+// Wait waits until the leads in delivery are sent or their attempts are exhausted
+func (ls *leads) Wait() {
+	ls.wg.Wait()
+}
+
+// Shutdown waits until the leads in delivery are sent or the context is done, then the remaining deliveries are
+// aborted without more attempts; the leads tracked after it are not sent
+func (ls *leads) Shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		ls.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	ls.abort()
+	<-done
+}
+
+// deliver sends a lead to the sink, the attempts are retried with an exponential backoff until the context is done
+func (ls *leads) deliver(ctx context.Context, id string) {
+	defer ls.wg.Done()
+	log := logger.FromContext(ctx).With("lead_id", id)
+	if ctx.Err() != nil {
+		ls.fail(id, "the delivery was aborted at shutdown before its first attempt")
+		return
+	}
+
+	backoff := ls.backoff
+	for attempt := uint(1); attempt <= ls.cfg.DeliveryAttempts; attempt++ {
+		captured, ok := ls.store.Lead(id)
+		if !ok {
+			return
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, leadDeliveryTimeout)
+		err := ls.sink.Push(attemptCtx, captured)
+		cancel()
+
+		// the status is only changed when it is still a delivery status, a status updated by the admin during the
+		// attempt is kept
+		_, ok = ls.store.Update(id, func(lead *model.Lead) {
+			lead.DeliveryAttempts = attempt
+			lead.UpdatedAt = time.Now().UTC()
+			if err == nil {
+				if lead.Status == model.LeadNew || lead.Status == model.LeadDeliveryFailed {
+					lead.Status = model.LeadNotified
+				}
+				lead.LastError = ""
+				return
+			}
+			lead.LastError = err.Error()
+			if (attempt == ls.cfg.DeliveryAttempts || ctx.Err() != nil) && lead.Status == model.LeadNew {
+				lead.Status = model.LeadDeliveryFailed
+			}
+		})
+		if !ok {
+			return
+		}
+		if err == nil {
+			log.InfoContext(ctx, "lead sent", "attempt", attempt)
+			return
+		}
+		log.WarnContext(ctx, "lead could not be sent", "attempt", attempt, "error", err)
+		if attempt == ls.cfg.DeliveryAttempts || ctx.Err() != nil {
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+			backoff *= 2
+		case <-ctx.Done():
+			timer.Stop()
+			ls.fail(id, "the delivery was aborted at shutdown after: "+err.Error())
+			return
+		}
+	}
+}
+
+// fail marks a lead that is still new as failed to deliver without more attempts
+func (ls *leads) fail(id, reason string) {
+	ls.store.Update(id, func(lead *model.Lead) {
+		lead.LastError = reason
+		lead.UpdatedAt = time.Now().UTC()
+		if lead.Status == model.LeadNew {
+			lead.Status = model.LeadDeliveryFailed
+		}
+	})
+}
+
+// generateID generates a random hex id of 16 bytes
+func generateID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/lead"
+)
+
+func Test_Lead_Service_Track(t *testing.T) {
+	var received atomic.Int32
+	var failures atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var captured model.Lead
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil || captured.ID == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received.Add(1)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	contact := &model.Contact{Name: "Ada", Email: "ada@example.com"}
+
+	testCases := map[string]struct {
+		requests         int
+		exhaustedAfter   int
+		failures         int32
+		attempts         uint
+		expectedLead     bool
+		expectedStatus   model.LeadStatus
+		expectedRequests int
+		expectedAttempts uint
+	}{
+		"retries_not_exhausted": {
+			requests:       2,
+			exhaustedAfter: 3,
+			attempts:       1,
+		},
+		"lead_captured_and_sent": {
+			requests:         4,
+			exhaustedAfter:   4,
+			attempts:         1,
+			expectedLead:     true,
+			expectedStatus:   model.LeadNotified,
+			expectedRequests: 3,
+			expectedAttempts: 1,
+		},
+		"lead_sent_after_retry": {
+			requests:         1,
+			exhaustedAfter:   1,
+			failures:         1,
+			attempts:         3,
+			expectedLead:     true,
+			expectedStatus:   model.LeadNotified,
+			expectedRequests: 1,
+			expectedAttempts: 2,
+		},
+		"lead_delivery_failed": {
+			requests:         1,
+			exhaustedAfter:   1,
+			failures:         5,
+			attempts:         2,
+			expectedLead:     true,
+			expectedStatus:   model.LeadDeliveryFailed,
+			expectedRequests: 1,
+			expectedAttempts: 2,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			failures.Store(tc.failures)
+			received.Store(0)
+			tracked := 0
+			cfg := &env.Leads{History: 3, DeliveryAttempts: tc.attempts}
+			s := NewLeads(lead.NewStore(), lead.NewWebhookSink(receiver.URL), cfg, func(ip string) bool {
+				return tracked >= tc.exhaustedAfter
+			})
+			s.backoff = 0
+
+			var captured *model.Lead
+			for i := 0; i < tc.requests; i++ {
+				tracked++
+				creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+				if i == 0 {
+					creditLine.SetContact(contact)
+				}
+				declined := model.NewCreditLineResponse(model.Declined, "0.00")
+				declined.LeadID = s.Capture(context.Background(), "192.0.2.10", creditLine, declined)
+				if got := s.Track(context.Background(), "192.0.2.10", creditLine, declined); got != nil {
+					captured = got
+				}
+			}
+			s.Wait()
+
+			if !tc.expectedLead {
+				if captured != nil {
+					t.Fatalf("unexpected lead: %v", captured)
+				}
+				return
+			}
+			if captured == nil {
+				t.Fatalf("got nil lead expecting a lead")
+			}
+
+			got, err := s.Lead(context.Background(), captured.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Status != tc.expectedStatus {
+				t.Errorf("unexpected status, got: %v, expected: %v", got.Status, tc.expectedStatus)
+			}
+			if len(got.Requests) != tc.expectedRequests {
+				t.Errorf("unexpected requests, got: %v, expected: %v", len(got.Requests), tc.expectedRequests)
+			}
+			if got.DeliveryAttempts != tc.expectedAttempts {
+				t.Errorf("unexpected delivery attempts, got: %v, expected: %v", got.DeliveryAttempts, tc.expectedAttempts)
+			}
+			if tc.expectedRequests == tc.requests && (got.Contact == nil || *got.Contact != *contact) {
+				t.Errorf("unexpected contact, got: %v, expected: %v", got.Contact, contact)
+			}
+			if tc.expectedStatus == model.LeadNotified && received.Load() != 1 {
+				t.Errorf("unexpected leads received, got: %v, expected: 1", received.Load())
+			}
+		})
+	}
+}
+
+func Test_Lead_Service_Update_Status(t *testing.T) {
+	s := NewLeads(lead.NewStore(), nil, &env.Leads{History: 1, DeliveryAttempts: 1}, func(ip string) bool { return true })
+	ctx := context.Background()
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+
+	track := func() *model.Lead {
+		declined := model.NewCreditLineResponse(model.Declined, "0.00")
+		declined.LeadID = s.Capture(ctx, "192.0.2.20", creditLine, declined)
+		return s.Track(ctx, "192.0.2.20", creditLine, declined)
+	}
+	captured := track()
+	if captured == nil || captured.Status != model.LeadNew {
+		t.Fatalf("unexpected lead: %v", captured)
+	}
+	if got := track(); got != nil {
+		t.Fatalf("unexpected second lead while the first one is open: %v", got)
+	}
+
+	if _, err := s.UpdateLeadStatus(ctx, captured.ID, model.LeadClosed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := track(); got == nil {
+		t.Fatalf("got nil lead expecting a new lead after closing the previous one")
+	}
+	if _, err := s.UpdateLeadStatus(ctx, "unknown", model.LeadClosed); !errors.Is(err, pkgerrors.ErrLeadNotFound) {
+		t.Fatalf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrLeadNotFound)
+	}
+}
+
+// contactingSink sink that updates the status of the lead while it is pushed, as an admin during a delivery
+type contactingSink struct {
+	leads *leads
+}
+
+func (cs *contactingSink) Push(ctx context.Context, captured *model.Lead) error {
+	_, err := cs.leads.UpdateLeadStatus(ctx, captured.ID, model.LeadContacted)
+	return err
+}
+
+func Test_Lead_Service_Deliver_Keeps_Updated_Status(t *testing.T) {
+	sink := &contactingSink{}
+	s := NewLeads(lead.NewStore(), sink, &env.Leads{History: 1, DeliveryAttempts: 1}, func(ip string) bool { return true })
+	sink.leads = s
+	ctx := context.Background()
+
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+	declined := model.NewCreditLineResponse(model.Declined, "0.00")
+	declined.LeadID = s.Capture(ctx, "192.0.2.30", creditLine, declined)
+	captured := s.Track(ctx, "192.0.2.30", creditLine, declined)
+	s.Wait()
+
+	got, err := s.Lead(ctx, captured.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != model.LeadContacted || got.DeliveryAttempts != 1 {
+		t.Errorf("unexpected lead, got: %v %v, expected: %v 1", got.Status, got.DeliveryAttempts, model.LeadContacted)
+	}
+}
+
+// unavailableSink sink that always fails to push the leads
+type unavailableSink struct{}
+
+func (us unavailableSink) Push(ctx context.Context, captured *model.Lead) error {
+	return errors.New("sink unavailable")
+}
+
+func Test_Lead_Service_Shutdown(t *testing.T) {
+	s := NewLeads(lead.NewStore(), unavailableSink{}, &env.Leads{History: 1, DeliveryAttempts: 5}, func(ip string) bool { return true })
+	s.backoff = time.Hour
+	ctx := context.Background()
+
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+	declined := model.NewCreditLineResponse(model.Declined, "0.00")
+	declined.LeadID = s.Capture(ctx, "192.0.2.40", creditLine, declined)
+	captured := s.Track(ctx, "192.0.2.40", creditLine, declined)
+
+	// the lead waits an hour for its second attempt, the shutdown aborts it once its context is done
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	s.Shutdown(shutdownCtx)
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("the shutdown waited for the retries, elapsed: %v", elapsed)
+	}
+
+	got, err := s.Lead(ctx, captured.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != model.LeadDeliveryFailed || got.DeliveryAttempts != 1 {
+		t.Errorf("unexpected lead, got: %v %v, expected: %v 1", got.Status, got.DeliveryAttempts, model.LeadDeliveryFailed)
+	}
+}
+
+func Test_Lead_Service_Capture_Concurrent_Declines(t *testing.T) {
+	s := NewLeads(lead.NewStore(), nil, &env.Leads{History: 1, DeliveryAttempts: 1}, func(ip string) bool { return true })
+	ctx := context.Background()
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+
+	// only one of the concurrent declines of the same key captures the lead
+	var captured atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.Capture(ctx, "192.0.2.50", creditLine, model.NewCreditLineResponse(model.Declined, "0.00")) != "" {
+				captured.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if captured.Load() != 1 {
+		t.Fatalf("unexpected captured leads, got: %v, expected: 1", captured.Load())
+	}
+
+	// the lead of a decision that was not stored is released
+	other := model.NewCreditLineResponse(model.Declined, "0.00")
+	if other.LeadID = s.Capture(ctx, "192.0.2.51", creditLine, other); other.LeadID == "" {
+		t.Fatalf("got empty lead id expecting a captured lead")
+	}
+	s.Release(ctx, "192.0.2.51", creditLine, other)
+	if id := s.Capture(ctx, "192.0.2.51", creditLine, model.NewCreditLineResponse(model.Declined, "0.00")); id == "" || id == other.LeadID {
+		t.Errorf("unexpected lead id after the release, got: %q", id)
+	}
+}
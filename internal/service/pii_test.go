@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/certificate"
+	"credit-line/pkg/outbox"
+	"credit-line/pkg/pii"
+)
+
+func Test_Stored_Applicant_Data_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	keyringPath := filepath.Join(dir, "keyring.json")
+	storePath := filepath.Join(dir, "decisions.jsonl")
+	auditPath := filepath.Join(dir, "audit.jsonl")
+
+	keyring, err := pii.RotateKeyring(keyringPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := outbox.NewFileStore(storePath, keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auditLog, err := audit.Open(auditPath, keyring)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := certificate.WithIdentity(context.Background(), &certificate.Identity{Subject: "partner-a", Fingerprint: "ab12"})
+	s := NewDecisions(store, auditLog, testPolicy, func(ip string) bool { return false })
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 178.25)
+	response := model.NewCreditLineResponse(model.Approved, "1305.90")
+	if err := s.Record(ctx, "192.0.2.10", creditLine, response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Close()
+	auditLog.Close()
+
+	// the applicant data is not written in plain text
+	for _, path := range []string{storePath, auditPath} {
+		content, _ := os.ReadFile(path)
+		for _, value := range []string{"192.0.2.10", "435.3", "4235.45", "178.25", "partner-a"} {
+			if strings.Contains(string(content), value) {
+				t.Errorf("unexpected plain text %v in %v: %s", value, path, content)
+			}
+		}
+	}
+
+	// the keyring is required to replay the encrypted records
+	if _, err := outbox.NewFileStore(storePath, nil); err == nil {
+		t.Fatalf("got nil error expecting the missing keyring error")
+	}
+
+	// the rotation encrypts again the data keys without changing the audit chain
+	content, _ := os.ReadFile(auditPath)
+	before, err := audit.Verify(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rotated, err := pii.RotateKeyring(keyringPath)
+	if err != nil || rotated.Primary() == keyring.Primary() {
+		t.Fatalf("unexpected rotation, primary: %v, error: %v", rotated.Primary(), err)
+	}
+	for path, rewrap := range map[string]func(string, *pii.Keyring) (int, error){storePath: outbox.RewrapFile, auditPath: audit.RewrapFile} {
+		if n, err := rewrap(path, rotated); err != nil || n != 1 {
+			t.Fatalf("unexpected rewrap of %v, got: %v %v", path, n, err)
+		}
+		if n, err := rewrap(path, rotated); err != nil || n != 0 {
+			t.Fatalf("unexpected second rewrap of %v, got: %v %v", path, n, err)
+		}
+		content, _ := os.ReadFile(path)
+		if !strings.Contains(string(content), `"keyId":"`+rotated.Primary()+`"`) {
+			t.Errorf("unexpected key of %v: %s", path, content)
+		}
+	}
+	content, _ = os.ReadFile(auditPath)
+	after, err := audit.Verify(bytes.NewReader(content))
+	if err != nil || after.RootHash != before.RootHash {
+		t.Fatalf("unexpected verification after the rotation: %v %v", after, err)
+	}
+
+	// the previous key is kept in the keyring, a keyring without the key can not decrypt the records
+	if _, err := outbox.NewFileStore(storePath, keyring); !errors.Is(err, pii.ErrUnknownKey) {
+		t.Fatalf("unexpected error, got: %v, expected: %v", err, pii.ErrUnknownKey)
+	}
+	loaded, err := pii.LoadKeyring(keyringPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err = outbox.NewFileStore(storePath, loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer store.Close()
+	decision, ok := store.Decision(response.DecisionID)
+	if !ok || decision.IP != "192.0.2.10" || decision.RequestedCreditLine != 178.25 {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	if pending := store.Pending(10); len(pending) != 1 || pending[0].Event.Data.RequestedCreditLine != 178.25 {
+		t.Fatalf("unexpected pending events: %+v", pending)
+	}
+
+	auditLog, err = audit.Open(auditPath, loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer auditLog.Close()
+	proof, err := NewAudit(auditLog).Proof(context.Background(), response.DecisionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := audit.VerifyProof(proof); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry, err := audit.Unseal(proof.Entry, loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Client.IP != "192.0.2.10" || entry.Client.CertificateSubject != "partner-a" || entry.Inputs.CashBalance != 435.30 ||
+		entry.Inputs.MonthlyRevenue != 4235.45 || entry.Inputs.RequestedCreditLine != 178.25 {
+		t.Errorf("unexpected unsealed entry: %+v", entry)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/cache"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/lead"
+	"credit-line/pkg/outbox"
+)
+
+// RetentionService services contracts for the retention of the applicant data
+type RetentionService interface {
+	Purge(ctx context.Context, dryRun bool) (*model.RetentionReport, error)
+	Report(ctx context.Context) (*model.RetentionReport, error)
+}
+
+// retention struct that implement the RetentionService interface with the stores of the applicant data
+type retention struct {
+	decisions outbox.Store
+	auditLog  *audit.Log
+	leads     *lead.Store
+	cfg       *env.Retention
+	mu        sync.Mutex
+	report    *model.RetentionReport
+	now       func() time.Time
+}
+
+// NewRetention creates a new pointer of retention struct
+func NewRetention(decisions outbox.Store, auditLog *audit.Log, leads *lead.Store, cfg *env.Retention) *retention {
+	return &retention{
+		decisions: decisions,
+		auditLog:  auditLog,
+		leads:     leads,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+// Purge implement the interface RetentionService.Purge, the decisions, audit entries and leads older than the
+// retention days are purged or anonymized and the idle cache entries are expired; a dry run only counts them
+func (rs *retention) Purge(ctx context.Context, dryRun bool) (*model.RetentionReport, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := rs.now().UTC()
+	report := &model.RetentionReport{StartedAt: now, DryRun: dryRun, Mode: model.RetentionMode(rs.cfg.Mode)}
+	if rs.cfg.Days > 0 {
+		cutoff := now.AddDate(0, 0, -int(rs.cfg.Days))
+		report.Cutoff = &cutoff
+
+		counts, err := rs.decisions.Expire(cutoff, report.Mode, dryRun)
+		report.Decisions = counts
+		if err != nil {
+			return nil, fmt.Errorf("decisions not expired: %w", err)
+		}
+		if report.AuditEntries, err = rs.auditLog.Shred(cutoff, dryRun); err != nil {
+			return nil, fmt.Errorf("audit entries not expired: %w", err)
+		}
+		report.Leads, report.LeadRequests = rs.leads.Expire(cutoff, report.Mode, dryRun)
+	}
+	if rs.cfg.CacheIdle > 0 {
+		cacheCutoff := now.Add(-time.Minute * time.Duration(rs.cfg.CacheIdle))
+		report.CacheCutoff = &cacheCutoff
+		expired := cache.ExpireIdle(cacheCutoff, dryRun)
+		report.CacheEntries = model.RetentionCounts{Expired: expired, Purged: expired}
+	}
+
+	rs.report = report
+	return report, nil
+}
+
+// Report implement the interface RetentionService.Report, it retrieves the report of the last run
+func (rs *retention) Report(_ context.Context) (*model.RetentionReport, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.report == nil {
+		return nil, errors.ErrRetentionReportNotFound
+	}
+	report := *rs.report
+	return &report, nil
+}
+
+// Run purges the expired applicant data when it starts and each interval until the context is cancelled, the
+// report of each run is logged
+func (rs *retention) Run(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := rs.Purge(ctx, false)
+		if err != nil {
+			l.ErrorContext(ctx, "retention purge failed", "error", err)
+		} else {
+			l.InfoContext(ctx, "retention purge finished", "decisions", report.Decisions,
+				"audit_entries", report.AuditEntries, "leads", report.Leads, "lead_requests", report.LeadRequests,
+				"cache_entries", report.CacheEntries)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/audit"
+	"credit-line/pkg/cache"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/lead"
+	"credit-line/pkg/outbox"
+	"credit-line/pkg/pii"
+)
+
+// firstAuditEntry reads the first entry of an audit log file
+func firstAuditEntry(t *testing.T, path string) model.AuditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	var entry model.AuditEntry
+	if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return entry
+}
+
+func Test_Retention_Service_Purge(t *testing.T) {
+	testCases := map[string]struct {
+		mode                   model.RetentionMode
+		dryRun                 bool
+		sealed                 bool
+		expectedDecisions      model.RetentionCounts
+		expectedAuditEntries   model.RetentionCounts
+		expectedLeads          model.RetentionCounts
+		expectedStoredDecision bool
+		expectedStoredIP       string
+	}{
+		"purge": {
+			mode:                 model.RetentionPurge,
+			sealed:               true,
+			expectedDecisions:    model.RetentionCounts{Expired: 3, Purged: 3},
+			expectedAuditEntries: model.RetentionCounts{Expired: 3, Anonymized: 3},
+			expectedLeads:        model.RetentionCounts{Expired: 1, Purged: 1},
+		},
+		"anonymize": {
+			mode:                   model.RetentionAnonymize,
+			sealed:                 true,
+			expectedDecisions:      model.RetentionCounts{Expired: 3, Anonymized: 3},
+			expectedAuditEntries:   model.RetentionCounts{Expired: 3, Anonymized: 3},
+			expectedLeads:          model.RetentionCounts{Expired: 1, Anonymized: 1},
+			expectedStoredDecision: true,
+		},
+		"dry_run": {
+			mode:                   model.RetentionPurge,
+			dryRun:                 true,
+			sealed:                 true,
+			expectedDecisions:      model.RetentionCounts{Expired: 3, Purged: 3},
+			expectedAuditEntries:   model.RetentionCounts{Expired: 3, Anonymized: 3},
+			expectedLeads:          model.RetentionCounts{Expired: 1, Purged: 1},
+			expectedStoredDecision: true,
+			expectedStoredIP:       "192.0.2.10",
+		},
+		"audit_entries_not_sealed": {
+			mode:                 model.RetentionPurge,
+			expectedDecisions:    model.RetentionCounts{Expired: 3, Purged: 3},
+			expectedAuditEntries: model.RetentionCounts{Expired: 3, Retained: 3},
+			expectedLeads:        model.RetentionCounts{Expired: 1, Purged: 1},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			storePath := filepath.Join(dir, "decisions.jsonl")
+			auditPath := filepath.Join(dir, "audit.jsonl")
+			var keyring *pii.Keyring
+			if tc.sealed {
+				var err error
+				if keyring, err = pii.RotateKeyring(filepath.Join(dir, "keyring.json")); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			store, err := outbox.NewFileStore(storePath, keyring)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer store.Close()
+			auditLog, err := audit.Open(auditPath, keyring)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer auditLog.Close()
+
+			// two published decisions and a decision with pending events
+			ctx := context.Background()
+			decisions := NewDecisions(store, auditLog, testPolicy, func(ip string) bool { return false })
+			ids := make([]string, 0, 3)
+			for i := 0; i < 3; i++ {
+				if i == 2 {
+					relay := outbox.NewRelay(store, outbox.NewMemoryBroker(), "decisions", 10)
+					if _, err := relay.Flush(ctx); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+				creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+				response := model.NewCreditLineResponse(model.Approved, "145.10")
+				if err := decisions.Record(ctx, "192.0.2.10", creditLine, response); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				ids = append(ids, response.DecisionID)
+			}
+
+			leads := lead.NewStore()
+			leads.Save(&model.Lead{ID: "3f2a", IP: "192.0.2.10", Status: model.LeadNew,
+				Contact: &model.Contact{Email: "ana@example.com"}, CreatedAt: time.Now(), UpdatedAt: time.Now()})
+			leads.RecordRequest("192.0.2.10", model.LeadRequest{CreditStatus: model.Declined, DeterminedAt: time.Now()}, 3)
+			cache.UpdateRequestCache(model.Declined, "198.51.100.7")
+
+			s := NewRetention(store, auditLog, leads, &env.Retention{Days: 30, Mode: string(tc.mode), CacheIdle: 60})
+			s.now = func() time.Time { return time.Now().AddDate(0, 0, 31) }
+			report, err := s.Purge(ctx, tc.dryRun)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if report.DryRun != tc.dryRun || report.Mode != tc.mode || report.Cutoff == nil || report.CacheCutoff == nil {
+				t.Errorf("unexpected report: %+v", report)
+			}
+			if report.Decisions != tc.expectedDecisions {
+				t.Errorf("unexpected decisions, got: %+v, expected: %+v", report.Decisions, tc.expectedDecisions)
+			}
+			if report.AuditEntries != tc.expectedAuditEntries {
+				t.Errorf("unexpected audit entries, got: %+v, expected: %+v", report.AuditEntries, tc.expectedAuditEntries)
+			}
+			if report.Leads != tc.expectedLeads {
+				t.Errorf("unexpected leads, got: %+v, expected: %+v", report.Leads, tc.expectedLeads)
+			}
+			if expected := (model.RetentionCounts{Expired: 1, Purged: 1}); report.LeadRequests != expected {
+				t.Errorf("unexpected lead requests, got: %+v, expected: %+v", report.LeadRequests, expected)
+			}
+			if report.CacheEntries.Expired == 0 {
+				t.Errorf("unexpected cache entries: %+v", report.CacheEntries)
+			}
+			if _, ok := cache.RetrieveRequestCache().Clients()["198.51.100.7"]; ok != tc.dryRun {
+				t.Errorf("unexpected idle cache entry, kept: %v", ok)
+			}
+
+			// the decisions are checked after replaying the compacted journal
+			store.Close()
+			if store, err = outbox.NewFileStore(storePath, keyring); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer store.Close()
+			decision, ok := store.Decision(ids[0])
+			if ok != tc.expectedStoredDecision || (ok && decision.IP != tc.expectedStoredIP) {
+				t.Errorf("unexpected expired decision, got: %+v %v", decision, ok)
+			}
+			// the decision with pending events expires as well, its events are dropped or anonymized
+			pending, ok := store.Decision(ids[2])
+			if ok != tc.expectedStoredDecision || (ok && pending.IP != tc.expectedStoredIP) {
+				t.Errorf("unexpected decision with pending events, got: %+v %v", pending, ok)
+			}
+			events := store.Pending(10)
+			if expectedEvents := map[bool]int{true: 1, false: 0}[tc.expectedStoredDecision]; len(events) != expectedEvents {
+				t.Fatalf("unexpected pending events, got: %v, expected: %v", len(events), expectedEvents)
+			}
+			for _, event := range events {
+				if requested := event.Event.Data.RequestedCreditLine; (requested != 0) != tc.dryRun {
+					t.Errorf("unexpected requested credit line of the pending event, got: %v", requested)
+				}
+			}
+
+			// the shredded entries keep the chain
+			content, _ := os.ReadFile(auditPath)
+			if _, err := audit.Verify(bytes.NewReader(content)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, found, err := auditLog.Proof(ids[0]); err != nil || !found {
+				t.Errorf("unexpected proof after the purge, got: %v %v", found, err)
+			}
+			if tc.sealed {
+				_, err := audit.Unseal(firstAuditEntry(t, auditPath), keyring)
+				if shredded := errors.Is(err, pii.ErrShredded); shredded == tc.dryRun {
+					t.Errorf("unexpected unseal of the expired entry, got: %v", err)
+				}
+			}
+
+			if _, ok := leads.Lead("3f2a"); ok != (tc.dryRun || tc.mode == model.RetentionAnonymize) {
+				t.Errorf("unexpected expired lead, kept: %v", ok)
+			}
+			if _, ok := leads.OpenLead("192.0.2.10"); ok != tc.dryRun {
+				t.Errorf("unexpected open lead, kept: %v", ok)
+			}
+			if requests := leads.Requests("192.0.2.10"); (len(requests) == 1) != tc.dryRun {
+				t.Errorf("unexpected lead requests: %v", requests)
+			}
+		})
+	}
+}
+
+func Test_Retention_Service_Report(t *testing.T) {
+	s := NewRetention(outbox.NewMemoryStore(), newAuditLog(t), lead.NewStore(), &env.Retention{Mode: string(model.RetentionPurge)})
+	if _, err := s.Report(context.Background()); !errors.Is(err, pkgerrors.ErrRetentionReportNotFound) {
+		t.Fatalf("got %v expecting the report not found error", err)
+	}
+
+	// without retention days and cache idle time nothing expires
+	purged, err := s.Purge(context.Background(), false)
+	if err != nil || purged.Cutoff != nil || purged.CacheCutoff != nil {
+		t.Fatalf("unexpected purge, got: %+v %v", purged, err)
+	}
+	report, err := s.Report(context.Background())
+	if err != nil || !report.StartedAt.Equal(purged.StartedAt) {
+		t.Errorf("unexpected report, got: %+v %v", report, err)
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/cache"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+	"credit-line/pkg/review"
+)
+
+// ReviewSubmitter contract to route the borderline and the risky credit line requests to manual review
+type ReviewSubmitter interface {
+	Submit(ctx context.Context, ip string, creditLine *model.CreditLine, amount float64) (*model.CreditLineResponse, bool, error)
+}
+
+// ReviewService services contracts for the manual review queue of the back-office
+type ReviewService interface {
+	Reviews(ctx context.Context, status model.ReviewStatus, reviewer string) []*model.Review
+	Review(ctx context.Context, id string) (*model.Review, error)
+	Assign(ctx context.Context, id, reviewer string) (*model.Review, error)
+	AddNote(ctx context.Context, id, reviewer, text string) (*model.Review, error)
+	Resolve(ctx context.Context, id, reviewer string, action model.ReviewAction, amount float64, note string) (*model.Review, error)
+}
+
+// reviews struct that implement the ReviewSubmitter and ReviewService interfaces with the store of the review
+// queue, the decisions of the reviewers are recorded and notified as the automatic decisions
+type reviews struct {
+	store     *review.Store
+	cfg       *env.Reviews
+	decisions DecisionRecorder
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// NewReviews creates a new pointer of reviews struct
+func NewReviews(store *review.Store, cfg *env.Reviews, decisions DecisionRecorder) *reviews {
+	return &reviews{
+		store:     store,
+		cfg:       cfg,
+		decisions: decisions,
+		now:       time.Now,
+	}
+}
+
+// Submit implement the interface ReviewSubmitter.Submit, a request routed to manual review by its risk assessment
+// or whose calculated credit line is within the band below the requested credit line is recorded as pending
+// review and queued; it retrieves false when the request is not routed to manual review
+func (rs *reviews) Submit(ctx context.Context, ip string, creditLine *model.CreditLine, amount float64) (*model.CreditLineResponse, bool, error) {
+	reason, ok := rs.reason(creditLine, amount)
+	if !ok {
+		return nil, false, nil
+	}
+
+	now := rs.now().UTC()
+	queued := &model.Review{
+		ID:                   generateID(),
+		Reason:               reason,
+		Status:               model.ReviewPending,
+		IP:                   ip,
+		FoundingType:         creditLine.FoundingType(),
+		CashBalance:          creditLine.CashBalance(),
+		MonthlyRevenue:       creditLine.MonthlyRevenue(),
+		RequestedCreditLine:  creditLine.RequestedCreditLine(),
+		RequestedDate:        creditLine.RequestedDate(),
+		CalculatedCreditLine: fmt.Sprintf("%.2f", amount),
+		CreatedAt:            now,
+		DueAt:                now.Add(time.Minute * time.Duration(rs.cfg.SLA)),
+	}
+	if applicant := creditLine.Applicant(); applicant != nil {
+		queued.ApplicantID = applicant.ID
+	}
+	if risk := creditLine.Risk(); risk != nil && risk.Action != model.RiskAllow {
+		queued.Risk = risk
+	}
+
+	response := model.NewCreditLineResponse(model.PendingReview, "0.00")
+	response.ReviewID = queued.ID
+	if err := rs.decisions.Record(ctx, ip, creditLine, response); err != nil {
+		return nil, true, err
+	}
+	queued.DecisionID = response.DecisionID
+	rs.store.Save(queued)
+	metrics.ObserveReview(reason)
+	logger.FromContext(ctx).InfoContext(ctx, "credit line routed to manual review", "review_id", queued.ID,
+		"reason", reason, "due_at", queued.DueAt)
+	return response, true, nil
+}
+
+// Reviews implement the interface ReviewService.Reviews, the reviews are filtered by status and reviewer when
+// they are not empty, the first due first
+func (rs *reviews) Reviews(_ context.Context, status model.ReviewStatus, reviewer string) []*model.Review {
+	queue := rs.store.Reviews()
+	filtered := queue[:0]
+	for _, queued := range queue {
+		if (status == "" || queued.Status == status) && (reviewer == "" || queued.Reviewer == reviewer) {
+			filtered = append(filtered, queued)
+		}
+	}
+	return filtered
+}
+
+// Review implement the interface ReviewService.Review
+func (rs *reviews) Review(_ context.Context, id string) (*model.Review, error) {
+	queued, ok := rs.store.Review(id)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errors.ErrReviewNotFound, id)
+	}
+	return queued, nil
+}
+
+// Assign implement the interface ReviewService.Assign, a review that is not resolved is assigned to the reviewer
+// even when it was assigned to another reviewer
+func (rs *reviews) Assign(ctx context.Context, id, reviewer string) (*model.Review, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	queued, err := rs.Review(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if queued.Status.Resolved() {
+		return nil, fmt.Errorf("%w %q", errors.ErrReviewResolved, id)
+	}
+
+	now := rs.now().UTC()
+	previous := queued.Reviewer
+	assigned, err := rs.update(id, func(review *model.Review) {
+		review.Status, review.Reviewer, review.AssignedAt = model.ReviewAssigned, reviewer, &now
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "review assigned", "review_id", id, "reviewer", reviewer,
+		"previous_reviewer", previous)
+	return assigned, nil
+}
+
+// AddNote implement the interface ReviewService.AddNote
+func (rs *reviews) AddNote(ctx context.Context, id, reviewer, text string) (*model.Review, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	now := rs.now().UTC()
+	return rs.update(id, func(review *model.Review) {
+		review.Notes = append(review.Notes, model.ReviewNote{Reviewer: reviewer, Text: text, CreatedAt: now})
+	})
+}
+
+// Resolve implement the interface ReviewService.Resolve, only the reviewer of an assigned review can resolve it:
+// approve authorizes the calculated credit line, or the requested credit line when the calculated one is lower,
+// adjust authorizes the amount and decline authorizes nothing; the final decision is recorded and notified with
+// the review id
+func (rs *reviews) Resolve(ctx context.Context, id, reviewer string, action model.ReviewAction, amount float64,
+	note string) (*model.Review, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	queued, err := rs.Review(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if queued.Status.Resolved() {
+		return nil, fmt.Errorf("%w %q", errors.ErrReviewResolved, id)
+	}
+	if queued.Status != model.ReviewAssigned || queued.Reviewer != reviewer {
+		return nil, fmt.Errorf("%w %q", errors.ErrReviewNotAssigned, id)
+	}
+
+	status, authorized := model.Approved, amount
+	switch action {
+	case model.ReviewApprove:
+		calculated, _ := strconv.ParseFloat(queued.CalculatedCreditLine, 64)
+		authorized = math.Max(calculated, queued.RequestedCreditLine)
+	case model.ReviewDecline:
+		status, authorized = model.Declined, 0
+	}
+
+	creditLine := queued.CreditLine()
+	response := model.NewCreditLineResponse(status, fmt.Sprintf("%.2f", authorized))
+	response.ReviewID = queued.ID
+	if err := rs.decisions.Record(ctx, queued.IP, creditLine, response); err != nil {
+		return nil, fmt.Errorf("review decision not stored: %w", err)
+	}
+
+	now := rs.now().UTC()
+	resolved, err := rs.update(id, func(review *model.Review) {
+		review.Status, review.Action, review.ResolvedAt = model.ReviewApproved, action, &now
+		if status == model.Declined {
+			review.Status = model.ReviewDeclined
+		}
+		review.CreditLineAuthorized, review.FinalDecisionID = response.CreditLineAuthorized, response.DecisionID
+		if note != "" {
+			review.Notes = append(review.Notes, model.ReviewNote{Reviewer: reviewer, Text: note, CreatedAt: now})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the decisions of the reviewers count in the decline retries as the automatic decisions
+	cache.UpdateRequestCache(status, creditLine.RetryKeys(queued.IP)...)
+	metrics.ObserveDecision(queued.FoundingType, status, authorized)
+	metrics.ObserveReviewResolution(action)
+	logger.FromContext(ctx).InfoContext(ctx, "review resolved", "review_id", id, "reviewer", reviewer,
+		"action", action, "credit_status", status)
+	return resolved, nil
+}
+
+// update applies a change to a stored review, the breach marked by the watcher after the review was read is kept
+func (rs *reviews) update(id string, update func(review *model.Review)) (*model.Review, error) {
+	updated, ok := rs.store.Update(id, update)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errors.ErrReviewNotFound, id)
+	}
+	return updated, nil
+}
+
+// Watch checks the due time of the reviews every interval until the context is done, the reviews that were not
+// resolved in time are logged and counted once
+func (rs *reviews) Watch(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, breached := range rs.store.Breach(rs.now().UTC()) {
+				metrics.ObserveReviewSLABreach()
+				l.Warn("review SLA breached", "review_id", breached.ID, "reviewer", breached.Reviewer,
+					"status", breached.Status, "due_at", breached.DueAt)
+			}
+		}
+	}
+}
+
+// reason retrieves the reason to route a request to manual review, false when the request is decided
+// automatically
+func (rs *reviews) reason(creditLine *model.CreditLine, amount float64) (model.ReviewReason, bool) {
+	if risk := creditLine.Risk(); risk != nil && risk.Action == model.RiskReview {
+		return model.ReviewRisk, true
+	}
+	requested := creditLine.RequestedCreditLine()
+	if rs.cfg.Band > 0 && amount <= requested && amount >= requested*(1-rs.cfg.Band/100) {
+		return model.ReviewBand, true
+	}
+	return "", false
+}
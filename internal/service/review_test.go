@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/review"
+)
+
+func Test_Review_Service_Submit(t *testing.T) {
+	testCases := map[string]struct {
+		requested        float64
+		amount           float64
+		risk             *model.RiskAssessment
+		recordErr        error
+		expectedRouted   bool
+		expectedReason   model.ReviewReason
+		expectedResponse *model.CreditLineResponse
+		expectedError    error
+	}{
+		"approved_not_routed": {
+			requested: 100,
+			amount:    145.10,
+		},
+		"declined_below_the_band_not_routed": {
+			requested: 100,
+			amount:    89.99,
+		},
+		"declined_within_the_band": {
+			requested:      100,
+			amount:         95.10,
+			expectedRouted: true,
+			expectedReason: model.ReviewBand,
+			expectedResponse: &model.CreditLineResponse{CreditStatus: model.PendingReview, CreditLineAuthorized: "0.00",
+				DecisionID: "3f2a"},
+		},
+		"approved_with_review_risk": {
+			requested: 100,
+			amount:    145.10,
+			risk: &model.RiskAssessment{Score: 90, Signals: []model.RiskSignal{model.VelocitySignal, model.ProbingSignal},
+				Action: model.RiskReview},
+			expectedRouted: true,
+			expectedReason: model.ReviewRisk,
+			expectedResponse: &model.CreditLineResponse{CreditStatus: model.PendingReview, CreditLineAuthorized: "0.00",
+				DecisionID: "3f2a"},
+		},
+		"flagged_risk_not_routed": {
+			requested: 100,
+			amount:    145.10,
+			risk:      &model.RiskAssessment{Score: 30, Signals: []model.RiskSignal{model.ImplausibleSignal}, Action: model.RiskFlag},
+		},
+		"decision_not_stored": {
+			requested:      100,
+			amount:         95.10,
+			recordErr:      os.ErrClosed,
+			expectedRouted: true,
+			expectedError:  os.ErrClosed,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			decisions := &mockDecisionRecorder{
+				record: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error {
+					response.DecisionID = "3f2a"
+					return tc.recordErr
+				},
+			}
+			store := review.NewStore()
+			s := NewReviews(store, &env.Reviews{Band: 10, SLA: 240}, decisions)
+
+			creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, tc.requested)
+			creditLine.SetRisk(tc.risk)
+			got, routed, err := s.Submit(context.Background(), "167.222.20.251", creditLine, tc.amount)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if routed != tc.expectedRouted {
+				t.Fatalf("unexpected routing, got: %v, expected: %v", routed, tc.expectedRouted)
+			}
+
+			queue := store.Reviews()
+			if tc.expectedResponse == nil {
+				if got != nil || len(queue) != 0 {
+					t.Fatalf("unexpected review, got: %v, queue: %v", got, queue)
+				}
+				return
+			}
+			if len(queue) != 1 || queue[0].Reason != tc.expectedReason || queue[0].Status != model.ReviewPending {
+				t.Fatalf("unexpected queue, got: %+v", queue)
+			}
+			tc.expectedResponse.ReviewID = queue[0].ID
+			if !reflect.DeepEqual(tc.expectedResponse, got) {
+				t.Fatalf("unexpected response, got: %+v, expected: %+v", got, tc.expectedResponse)
+			}
+			if queue[0].DecisionID != "3f2a" || queue[0].CalculatedCreditLine == "" || queue[0].DueAt.Sub(queue[0].CreatedAt) != 4*time.Hour {
+				t.Errorf("unexpected review, got: %+v", queue[0])
+			}
+		})
+	}
+}
+
+func Test_Review_Service_Resolve(t *testing.T) {
+	type decision struct {
+		reviewer string
+		action   model.ReviewAction
+		amount   float64
+	}
+
+	testCases := map[string]struct {
+		assignees          []string
+		resolved           *decision
+		decision           decision
+		expectedStatus     model.ReviewStatus
+		expectedAuthorized string
+		expectedRecorded   []model.CreditStatus
+		expectedError      error
+	}{
+		"approve_the_requested_credit_line": {
+			assignees:          []string{"ana"},
+			decision:           decision{reviewer: "ana", action: model.ReviewApprove},
+			expectedStatus:     model.ReviewApproved,
+			expectedAuthorized: "100.00",
+			expectedRecorded:   []model.CreditStatus{model.PendingReview, model.Approved},
+		},
+		"adjust_the_credit_line": {
+			assignees:          []string{"ana"},
+			decision:           decision{reviewer: "ana", action: model.ReviewAdjust, amount: 80},
+			expectedStatus:     model.ReviewApproved,
+			expectedAuthorized: "80.00",
+			expectedRecorded:   []model.CreditStatus{model.PendingReview, model.Approved},
+		},
+		"decline_the_credit_line": {
+			assignees:          []string{"ana"},
+			decision:           decision{reviewer: "ana", action: model.ReviewDecline},
+			expectedStatus:     model.ReviewDeclined,
+			expectedAuthorized: "0.00",
+			expectedRecorded:   []model.CreditStatus{model.PendingReview, model.Declined},
+		},
+		"taken_over_review": {
+			assignees:          []string{"ana", "luis"},
+			decision:           decision{reviewer: "luis", action: model.ReviewDecline},
+			expectedStatus:     model.ReviewDeclined,
+			expectedAuthorized: "0.00",
+			expectedRecorded:   []model.CreditStatus{model.PendingReview, model.Declined},
+		},
+		"not_assigned_review": {
+			decision:         decision{reviewer: "ana", action: model.ReviewApprove},
+			expectedRecorded: []model.CreditStatus{model.PendingReview},
+			expectedError:    pkgerrors.ErrReviewNotAssigned,
+		},
+		"review_of_other_reviewer": {
+			assignees:        []string{"ana", "luis"},
+			decision:         decision{reviewer: "ana", action: model.ReviewApprove},
+			expectedRecorded: []model.CreditStatus{model.PendingReview},
+			expectedError:    pkgerrors.ErrReviewNotAssigned,
+		},
+		"resolved_review": {
+			assignees:        []string{"ana"},
+			resolved:         &decision{reviewer: "ana", action: model.ReviewDecline},
+			decision:         decision{reviewer: "ana", action: model.ReviewApprove},
+			expectedRecorded: []model.CreditStatus{model.PendingReview, model.Declined},
+			expectedError:    pkgerrors.ErrReviewResolved,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var recorded []model.CreditStatus
+			decisions := &mockDecisionRecorder{
+				record: func(ctx context.Context, ip string, creditLine *model.CreditLine, response *model.CreditLineResponse) error {
+					recorded = append(recorded, response.CreditStatus)
+					return nil
+				},
+			}
+			s := NewReviews(review.NewStore(), &env.Reviews{Band: 10, SLA: 240}, decisions)
+			ctx := context.Background()
+
+			creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+			response, _, err := s.Submit(ctx, "167.222.20.251", creditLine, 95.10)
+			if err != nil {
+				t.Fatalf("unexpected submit error: %v", err)
+			}
+			for _, assignee := range tc.assignees {
+				if _, err := s.Assign(ctx, response.ReviewID, assignee); err != nil {
+					t.Fatalf("unexpected assign error: %v", err)
+				}
+			}
+			if tc.resolved != nil {
+				if _, err := s.Resolve(ctx, response.ReviewID, tc.resolved.reviewer, tc.resolved.action, tc.resolved.amount, ""); err != nil {
+					t.Fatalf("unexpected resolve error: %v", err)
+				}
+			}
+
+			got, err := s.Resolve(ctx, response.ReviewID, tc.decision.reviewer, tc.decision.action, tc.decision.amount, "checked")
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			// the decisions of the reviewers are published to the subscribers by the outbox relay once recorded
+			if !reflect.DeepEqual(tc.expectedRecorded, recorded) {
+				t.Fatalf("unexpected recorded decisions, got: %v, expected: %v", recorded, tc.expectedRecorded)
+			}
+			if tc.expectedError != nil {
+				return
+			}
+			if got.Status != tc.expectedStatus || got.CreditLineAuthorized != tc.expectedAuthorized || got.ResolvedAt == nil {
+				t.Fatalf("unexpected review, got: %+v", got)
+			}
+			if len(got.Notes) != 1 || got.Notes[0].Reviewer != tc.decision.reviewer {
+				t.Errorf("unexpected notes, got: %+v", got.Notes)
+			}
+		})
+	}
+}
+
+func Test_Review_Service_Breach(t *testing.T) {
+	store := review.NewStore()
+	s := NewReviews(store, &env.Reviews{Band: 10, SLA: 1}, &mockDecisionRecorder{})
+	start := time.Now()
+	s.now = func() time.Time { return start }
+
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+	pending, _, _ := s.Submit(context.Background(), "167.222.20.251", creditLine, 95.10)
+	resolved, _, _ := s.Submit(context.Background(), "167.222.20.252", creditLine, 95.10)
+	_, _ = s.Assign(context.Background(), resolved.ReviewID, "ana")
+	_, _ = s.Resolve(context.Background(), resolved.ReviewID, "ana", model.ReviewDecline, 0, "")
+
+	// only the reviews that are not resolved within the SLA are breached, once
+	if breached := store.Breach(start.Add(time.Minute + time.Second)); len(breached) != 1 || breached[0].ID != pending.ReviewID {
+		t.Fatalf("unexpected breached reviews, got: %+v", breached)
+	}
+	if breached := store.Breach(start.Add(time.Hour)); len(breached) != 0 {
+		t.Fatalf("unexpected breached reviews, got: %+v", breached)
+	}
+
+	// the changes of the reviewers keep the breach, so it is not marked again
+	_, _ = s.Assign(context.Background(), pending.ReviewID, "ana")
+	_, _ = s.AddNote(context.Background(), pending.ReviewID, "ana", "late")
+	updated, _ := s.Resolve(context.Background(), pending.ReviewID, "ana", model.ReviewApprove, 0, "")
+	if updated == nil || updated.BreachedAt == nil || len(updated.Notes) != 1 {
+		t.Fatalf("unexpected resolved review, got: %+v", updated)
+	}
+}
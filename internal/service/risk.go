@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+	"credit-line/pkg/risk"
+)
+
+// RiskAssessor contract to score the credit line requests before the determination
+type RiskAssessor interface {
+	Assess(ctx context.Context, ip string, creditLine *model.CreditLine) error
+}
+
+// risks struct that implement the RiskAssessor interface with the store of the recent requests
+type risks struct {
+	store *risk.Store
+	cfg   *env.Risk
+	now   func() time.Time
+}
+
+// NewRisks creates a new pointer of risks struct
+func NewRisks(store *risk.Store, cfg *env.Risk) *risks {
+	return &risks{
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+// Assess implement the interface RiskAssessor.Assess, the request is scored by the velocity and the probing of
+// its ip and its applicant and by the plausibility of its inputs; a flagged request is logged, a delayed request
+// waits the delay before it is determined and a request with the review action is routed to manual review after
+// its calculation
+func (rs *risks) Assess(ctx context.Context, ip string, creditLine *model.CreditLine) error {
+	now := rs.now().UTC()
+	since := now.Add(-time.Minute * time.Duration(rs.cfg.VelocityWindow))
+	current := model.RiskRequest{CashBalance: creditLine.CashBalance(), MonthlyRevenue: creditLine.MonthlyRevenue()}
+
+	// the requests are kept by ip and by applicant, so changing one of them does not hide the pattern
+	var velocity, probing bool
+	for _, key := range creditLine.RetryKeys(ip) {
+		requests := rs.store.Record(key, current, now, since)
+		velocity = velocity || risk.Velocity(requests, rs.cfg.VelocityLimit)
+		probing = probing || risk.Probing(requests, rs.cfg.ProbingSteps)
+	}
+
+	var signals []model.RiskSignal
+	if velocity {
+		signals = append(signals, model.VelocitySignal)
+	}
+	if probing {
+		signals = append(signals, model.ProbingSignal)
+	}
+	if risk.Implausible(creditLine.CashBalance(), creditLine.MonthlyRevenue(), rs.cfg.RevenueBalanceRatio) {
+		signals = append(signals, model.ImplausibleSignal)
+	}
+	score := risk.Score(signals)
+	assessment := &model.RiskAssessment{Score: score, Signals: signals, Action: rs.action(score)}
+	creditLine.SetRisk(assessment)
+	metrics.ObserveRiskAssessment(assessment)
+	if assessment.Action == model.RiskAllow {
+		return nil
+	}
+
+	logger.FromContext(ctx).WarnContext(ctx, "risky credit line request", "risk_score", assessment.Score,
+		"risk_signals", assessment.Signals, "risk_action", assessment.Action)
+	if assessment.Action != model.RiskDelay {
+		return nil
+	}
+	timer := time.NewTimer(time.Second * time.Duration(rs.cfg.Delay))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("risk delay cancelled: %w", ctx.Err())
+	}
+}
+
+// action retrieves the action of a score, the highest action whose score is reached
+func (rs *risks) action(score uint) model.RiskAction {
+	switch {
+	case score == 0:
+		return model.RiskAllow
+	case rs.cfg.ReviewScore > 0 && score >= rs.cfg.ReviewScore:
+		return model.RiskReview
+	case rs.cfg.DelayScore > 0 && score >= rs.cfg.DelayScore:
+		return model.RiskDelay
+	case rs.cfg.FlagScore > 0 && score >= rs.cfg.FlagScore:
+		return model.RiskFlag
+	default:
+		return model.RiskAllow
+	}
+}
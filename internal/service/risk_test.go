@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	"credit-line/pkg/risk"
+)
+
+func Test_Risk_Service_Assess(t *testing.T) {
+	type request struct {
+		ip             string
+		applicantID    string
+		cashBalance    float64
+		monthlyRevenue float64
+		after          time.Duration
+	}
+
+	testCases := map[string]struct {
+		previous           []request
+		current            request
+		cancelled          bool
+		expectedAssessment model.RiskAssessment
+		expectedError      error
+	}{
+		"allowed_request": {
+			previous: []request{
+				{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+			},
+			current:            request{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+			expectedAssessment: model.RiskAssessment{Action: model.RiskAllow},
+		},
+		"implausible_revenue_flagged": {
+			current: request{ip: "192.0.2.10", cashBalance: 10, monthlyRevenue: 5000},
+			expectedAssessment: model.RiskAssessment{Score: 30, Signals: []model.RiskSignal{model.ImplausibleSignal},
+				Action: model.RiskFlag},
+		},
+		"velocity_flagged": {
+			previous: []request{
+				{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+			},
+			current: request{ip: "192.0.2.10", cashBalance: 435.30, monthlyRevenue: 4235.45},
+			expectedAssessment: model.RiskAssessment{Score: 40, Signals: []model.RiskSignal{model.VelocitySignal},
+				Action: model.RiskFlag},
+		},
+		"balance_probing_delayed": {
+			previous: []request{
+				{ip: "192.0.2.10", cashBalance: 100, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 200, monthlyRevenue: 4235.45},
+			},
+			current: request{ip: "192.0.2.10", cashBalance: 300, monthlyRevenue: 4235.45},
+			expectedAssessment: model.RiskAssessment{Score: 50, Signals: []model.RiskSignal{model.ProbingSignal},
+				Action: model.RiskDelay},
+		},
+		"applicant_probing_from_other_ips": {
+			previous: []request{
+				{ip: "192.0.2.10", applicantID: "0bb703ef", cashBalance: 435.30, monthlyRevenue: 1000},
+				{ip: "192.0.2.11", applicantID: "0bb703ef", cashBalance: 435.30, monthlyRevenue: 2000},
+			},
+			current: request{ip: "192.0.2.12", applicantID: "0bb703ef", cashBalance: 435.30, monthlyRevenue: 3000},
+			expectedAssessment: model.RiskAssessment{Score: 50, Signals: []model.RiskSignal{model.ProbingSignal},
+				Action: model.RiskDelay},
+		},
+		"probing_outside_the_window": {
+			previous: []request{
+				{ip: "192.0.2.10", cashBalance: 100, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 200, monthlyRevenue: 4235.45},
+			},
+			current:            request{ip: "192.0.2.10", cashBalance: 300, monthlyRevenue: 4235.45, after: time.Hour},
+			expectedAssessment: model.RiskAssessment{Action: model.RiskAllow},
+		},
+		"velocity_and_probing_routed_to_review": {
+			previous: []request{
+				{ip: "192.0.2.10", cashBalance: 100, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 200, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 300, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 400, monthlyRevenue: 4235.45},
+				{ip: "192.0.2.10", cashBalance: 500, monthlyRevenue: 4235.45},
+			},
+			current: request{ip: "192.0.2.10", cashBalance: 600, monthlyRevenue: 4235.45},
+			expectedAssessment: model.RiskAssessment{Score: 90,
+				Signals: []model.RiskSignal{model.VelocitySignal, model.ProbingSignal}, Action: model.RiskReview},
+		},
+		"delay_cancelled": {
+			current:   request{ip: "192.0.2.10", cashBalance: 10, monthlyRevenue: 50000},
+			cancelled: true,
+			expectedAssessment: model.RiskAssessment{Score: 30, Signals: []model.RiskSignal{model.ImplausibleSignal},
+				Action: model.RiskDelay},
+			expectedError: context.Canceled,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfg := &env.Risk{VelocityWindow: 10, VelocityLimit: 5, ProbingSteps: 3, RevenueBalanceRatio: 100,
+				FlagScore: 30, DelayScore: 50, ReviewScore: 80}
+			if tc.cancelled {
+				cfg.DelayScore, cfg.Delay = 30, 1
+			}
+			s := NewRisks(risk.NewStore(), cfg)
+			start := time.Now()
+			s.now = func() time.Time { return start }
+
+			newCreditLine := func(r request) *model.CreditLine {
+				creditLine := model.NewCreditLine("Startup", "2021-07-19T16:32:59.860Z", r.cashBalance, r.monthlyRevenue, 100)
+				if r.applicantID != "" {
+					creditLine.SetApplicant(&model.Applicant{ID: r.applicantID}, model.ApplicationHistory{})
+				}
+				return creditLine
+			}
+			for _, previous := range tc.previous {
+				_ = s.Assess(context.Background(), previous.ip, newCreditLine(previous))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancelled {
+				cancel()
+			}
+			s.now = func() time.Time { return start.Add(tc.current.after) }
+			creditLine := newCreditLine(tc.current)
+			err := s.Assess(ctx, tc.current.ip, creditLine)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if got := creditLine.Risk(); got == nil || !reflect.DeepEqual(*got, tc.expectedAssessment) {
+				t.Fatalf("unexpected assessment, got: %+v, expected: %+v", got, tc.expectedAssessment)
+			}
+
+			// only the requests with a risk action keep their assessment in the decision
+			decision := model.NewDecision("3f2a", tc.current.ip, creditLine, model.NewCreditLineResponse(model.Declined, "0.00"), start)
+			if kept := decision.Risk != nil; kept != (tc.expectedAssessment.Action != model.RiskAllow) {
+				t.Errorf("unexpected decision risk, got: %+v", decision.Risk)
+			}
+		})
+	}
+}
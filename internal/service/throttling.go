@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/access"
+	"credit-line/pkg/cache"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/middleware"
+)
+
+// ThrottlingService services contracts for the throttling state of the clients
+type ThrottlingService interface {
+	Clients(ctx context.Context) ([]*model.ClientThrottling, error)
+	Client(ctx context.Context, ip string) (*model.ClientThrottling, error)
+	ResetClient(ctx context.Context, ip string) (*model.ClientThrottling, error)
+	GrantAllowance(ctx context.Context, ip string, retries uint, duration time.Duration) (*model.ClientThrottling, error)
+	AccessList(ctx context.Context) []model.AccessEntry
+	AddAccessEntry(ctx context.Context, cidr, action string) (*model.AccessEntry, error)
+	RemoveAccessEntry(ctx context.Context, cidr string) error
+}
+
+// throttling struct that implement the ThrottlingService interface
+type throttling struct {
+	list *access.List
+}
+
+// NewThrottling creates a new pointer of throttling struct
+func NewThrottling(list *access.List) *throttling {
+	return &throttling{
+		list: list,
+	}
+}
+
+// Clients implement the interface ThrottlingService.Clients
+func (t *throttling) Clients(ctx context.Context) ([]*model.ClientThrottling, error) {
+	ips := make([]string, 0)
+	for ip := range cache.RetrieveRequestCache().Clients() {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+
+	clients := make([]*model.ClientThrottling, 0, len(ips))
+	for _, ip := range ips {
+		client, err := t.Client(ctx, ip)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// Client implement the interface ThrottlingService.Client
+func (t *throttling) Client(ctx context.Context, ip string) (*model.ClientThrottling, error) {
+	ip, err := parseClient(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := middleware.RetrieveRateLimitStates(ctx, ip)
+	if err != nil {
+		return nil, fmt.Errorf("rate limits could not be retrieved: %w", err)
+	}
+
+	requestCache := cache.RetrieveRequestCache()
+	client := &model.ClientThrottling{
+		IP:               ip,
+		DeclinedRequests: requestCache.Failures(ip),
+		RetriesAllowed:   middleware.DeclineRetriesAllowed(),
+		Access:           t.list.Action(ip),
+		RateLimits:       make([]model.RateLimit, 0, len(states)),
+	}
+	if allowance, ok := requestCache.Allowance(ip); ok {
+		client.Allowance = &model.Allowance{Retries: allowance.Retries, ExpiresAt: allowance.ExpiresAt}
+		client.RetriesAllowed += allowance.Retries
+	}
+	if client.RetriesAllowed > client.DeclinedRequests {
+		client.RetriesRemaining = client.RetriesAllowed - client.DeclinedRequests
+	}
+	for _, state := range states {
+		client.RateLimits = append(client.RateLimits, model.RateLimit(state))
+	}
+	return client, nil
+}
+
+// ResetClient implement the interface ThrottlingService.ResetClient
+func (t *throttling) ResetClient(ctx context.Context, ip string) (*model.ClientThrottling, error) {
+	ip, err := parseClient(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.ResetClient(ip)
+	if err := middleware.ResetRateLimits(ctx, ip); err != nil {
+		return nil, fmt.Errorf("rate limits could not be reset: %w", err)
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "client throttling reset", "ip", ip)
+	return t.Client(ctx, ip)
+}
+
+// GrantAllowance implement the interface ThrottlingService.GrantAllowance
+func (t *throttling) GrantAllowance(ctx context.Context, ip string, retries uint, duration time.Duration) (*model.ClientThrottling, error) {
+	ip, err := parseClient(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(duration).UTC()
+	cache.GrantAllowance(ip, retries, expiresAt)
+	logger.FromContext(ctx).InfoContext(ctx, "client allowance granted", "ip", ip,
+		"retries", retries, "expires_at", expiresAt)
+	return t.Client(ctx, ip)
+}
+
+// AccessList implement the interface ThrottlingService.AccessList
+func (t *throttling) AccessList(_ context.Context) []model.AccessEntry {
+	entries := t.list.Entries()
+	accessList := make([]model.AccessEntry, 0, len(entries))
+	for _, entry := range entries {
+		accessList = append(accessList, model.AccessEntry(entry))
+	}
+	return accessList
+}
+
+// AddAccessEntry implement the interface ThrottlingService.AddAccessEntry
+func (t *throttling) AddAccessEntry(ctx context.Context, cidr, action string) (*model.AccessEntry, error) {
+	entry, err := t.list.Add(cidr, action)
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "access list entry added", "cidr", entry.CIDR, "action", entry.Action)
+	accessEntry := model.AccessEntry(entry)
+	return &accessEntry, nil
+}
+
+// RemoveAccessEntry implement the interface ThrottlingService.RemoveAccessEntry
+func (t *throttling) RemoveAccessEntry(ctx context.Context, cidr string) error {
+	removed, err := t.list.Remove(cidr)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("%w: %s", errors.ErrAccessEntryNotFound, cidr)
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "access list entry removed", "cidr", cidr)
+	return nil
+}
+
+// parseClient parses the ip or the applicant key of a client, the IPv4-mapped IPv6 addresses are retrieved as IPv4
+func parseClient(key string) (string, error) {
+	if id, ok := strings.CutPrefix(key, model.ApplicantKeyPrefix); ok && id != "" {
+		return key, nil
+	}
+	addr, err := netip.ParseAddr(key)
+	if err != nil {
+		return "", fmt.Errorf("%w %q", errors.ErrInvalidIP, key)
+	}
+	return addr.Unmap().String(), nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/access"
+	"credit-line/pkg/cache"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/middleware"
+)
+
+func Test_Throttling_Service_Client(t *testing.T) {
+	retriesAllowed := middleware.DeclineRetriesAllowed()
+
+	testCases := map[string]struct {
+		ip                       string
+		declined                 uint
+		allowance                uint
+		allowanceDuration        time.Duration
+		reset                    bool
+		expectedIP               string
+		expectedRetriesAllowed   uint
+		expectedRetriesRemaining uint
+		expectedError            error
+	}{
+		"client_with_declined_requests": {
+			ip:                       "192.0.2.10",
+			declined:                 2,
+			expectedIP:               "192.0.2.10",
+			expectedRetriesAllowed:   retriesAllowed,
+			expectedRetriesRemaining: retriesAllowed - 2,
+		},
+		"client_with_allowance": {
+			ip:                       "192.0.2.11",
+			declined:                 retriesAllowed,
+			allowance:                2,
+			allowanceDuration:        time.Hour,
+			expectedIP:               "192.0.2.11",
+			expectedRetriesAllowed:   retriesAllowed + 2,
+			expectedRetriesRemaining: 2,
+		},
+		"client_with_expired_allowance": {
+			ip:                       "192.0.2.12",
+			declined:                 retriesAllowed,
+			allowance:                2,
+			allowanceDuration:        -time.Second,
+			expectedIP:               "192.0.2.12",
+			expectedRetriesAllowed:   retriesAllowed,
+			expectedRetriesRemaining: 0,
+		},
+		"client_reset": {
+			ip:                       "::ffff:192.0.2.13",
+			declined:                 retriesAllowed,
+			reset:                    true,
+			expectedIP:               "192.0.2.13",
+			expectedRetriesAllowed:   retriesAllowed,
+			expectedRetriesRemaining: retriesAllowed,
+		},
+		"applicant_client": {
+			ip:                       "applicant:9f86d081884c7d659a2feaa0c55ad015",
+			declined:                 1,
+			expectedIP:               "applicant:9f86d081884c7d659a2feaa0c55ad015",
+			expectedRetriesAllowed:   retriesAllowed,
+			expectedRetriesRemaining: retriesAllowed - 1,
+		},
+		"invalid_ip": {
+			ip:            "not-an-ip",
+			expectedError: pkgerrors.ErrInvalidIP,
+		},
+	}
+
+	s := NewThrottling(access.NewList())
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for i := uint(0); i < tc.declined; i++ {
+				cache.UpdateRequestCache(model.Declined, tc.expectedIP)
+			}
+
+			var client *model.ClientThrottling
+			var err error
+			switch {
+			case tc.allowance > 0:
+				client, err = s.GrantAllowance(ctx, tc.ip, tc.allowance, tc.allowanceDuration)
+			case tc.reset:
+				client, err = s.ResetClient(ctx, tc.ip)
+			default:
+				client, err = s.Client(ctx, tc.ip)
+			}
+
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if err != nil {
+				return
+			}
+			if client.IP != tc.expectedIP {
+				t.Errorf("unexpected ip, got: %v, expected: %v", client.IP, tc.expectedIP)
+			}
+			if client.RetriesAllowed != tc.expectedRetriesAllowed {
+				t.Errorf("unexpected retries allowed, got: %v, expected: %v", client.RetriesAllowed, tc.expectedRetriesAllowed)
+			}
+			if client.RetriesRemaining != tc.expectedRetriesRemaining {
+				t.Errorf("unexpected retries remaining, got: %v, expected: %v", client.RetriesRemaining, tc.expectedRetriesRemaining)
+			}
+			if len(client.RateLimits) != 2 {
+				t.Errorf("unexpected rate limits, got: %v, expected: 2 policies", client.RateLimits)
+			}
+		})
+	}
+}
+
+func Test_Throttling_Service_Access_List(t *testing.T) {
+	s := NewThrottling(access.NewList())
+	ctx := context.Background()
+
+	entry, err := s.AddAccessEntry(ctx, "2001:db8::1/32", access.BlockAction)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.CIDR != "2001:db8::/32" {
+		t.Errorf("unexpected network, got: %v, expected: %v", entry.CIDR, "2001:db8::/32")
+	}
+	if _, err := s.AddAccessEntry(ctx, "10.0.0.0/33", access.AllowAction); !errors.Is(err, pkgerrors.ErrInvalidCIDR) {
+		t.Errorf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrInvalidCIDR)
+	}
+	if got := s.AccessList(ctx); len(got) != 1 {
+		t.Errorf("unexpected access list, got: %v", got)
+	}
+	if err := s.RemoveAccessEntry(ctx, "2001:db8::/32"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.RemoveAccessEntry(ctx, "2001:db8::/32"); !errors.Is(err, pkgerrors.ErrAccessEntryNotFound) {
+		t.Errorf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrAccessEntryNotFound)
+	}
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/outbox"
+	"credit-line/pkg/webhook"
+)
+
+const (
+	// webhookDeliveryBackoff wait time before the second attempt of a delivery, it is doubled after each attempt
+	webhookDeliveryBackoff = time.Second
+	// webhookQueueSize max number of deliveries waiting for a worker, the new deliveries wait while it is full
+	webhookQueueSize = 256
+)
+
+// WebhookService services contracts for the webhook subscriptions of the decision events
+type WebhookService interface {
+	Subscriptions(ctx context.Context) []*model.WebhookSubscription
+	CreateSubscription(ctx context.Context, rawURL string, events []model.EventType, secret string) (*model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	Deliveries(ctx context.Context, subscriptionID string) ([]*model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error)
+}
+
+// webhooks struct that implement the WebhookService interface and the outbox.Broker interface, the outbox relay
+// publishes the events of the stored decisions to the subscribers; the deliveries are sent by a fixed number of
+// workers and they are aborted when the service is shut down
+type webhooks struct {
+	store    *webhook.Store
+	sender   *webhook.Sender
+	cfg      *env.Webhooks
+	queue    chan webhookJob
+	mu       sync.RWMutex
+	closed   bool
+	pending  sync.WaitGroup
+	workers  sync.WaitGroup
+	shutdown context.Context
+	abort    context.CancelFunc
+	backoff  time.Duration
+}
+
+// webhookJob struct with a delivery waiting for a worker
+type webhookJob struct {
+	log      *slog.Logger
+	delivery model.WebhookDelivery
+	payload  []byte
+}
+
+// NewWebhooks creates a new pointer of webhooks struct and starts the workers of the deliveries, at least one
+func NewWebhooks(store *webhook.Store, cfg *env.Webhooks) *webhooks {
+	shutdown, abort := context.WithCancel(context.Background())
+	ws := &webhooks{
+		store:    store,
+		sender:   webhook.NewSender(time.Duration(cfg.Timeout) * time.Second),
+		cfg:      cfg,
+		queue:    make(chan webhookJob, webhookQueueSize),
+		shutdown: shutdown,
+		abort:    abort,
+		backoff:  webhookDeliveryBackoff,
+	}
+	for i := uint(0); i < max(cfg.Workers, 1); i++ {
+		ws.workers.Add(1)
+		go ws.work()
+	}
+	return ws
+}
+
+// Publish implement the interface outbox.Broker, it sends an event published by the outbox relay to the
+// subscribers of its type asynchronously; the event is acknowledged once its deliveries are queued, so an event
+// that can not be decoded is logged and skipped instead of blocking the relay, and an event that could not be
+// queued before the context is done is published again by the relay
+func (ws *webhooks) Publish(ctx context.Context, msg outbox.Message) error {
+	var event model.Event
+	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "webhook event could not be decoded", "event_id", msg.ID, "error", err)
+		return nil
+	}
+	for _, subscription := range ws.store.Subscriptions() {
+		if !subscription.Subscribed(event.Type) {
+			continue
+		}
+		if _, err := ws.send(ctx, subscription.ID, event.ID, event.Type, "", msg.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implement the interface outbox.Broker, the deliveries in progress are waited by Wait
+func (ws *webhooks) Close() error {
+	return nil
+}
+
+// Subscriptions implement the interface WebhookService.Subscriptions, the secrets are not retrieved
+func (ws *webhooks) Subscriptions(_ context.Context) []*model.WebhookSubscription {
+	subscriptions := ws.store.Subscriptions()
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions
+}
+
+// CreateSubscription implement the interface WebhookService.CreateSubscription, a random secret is generated
+// when secret is empty and it is only retrieved in the response of the creation
+func (ws *webhooks) CreateSubscription(ctx context.Context, rawURL string, events []model.EventType, secret string) (*model.WebhookSubscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w %q", errors.ErrInvalidWebhookURL, rawURL)
+	}
+	if secret == "" {
+		secret = generateSecret()
+	}
+
+	subscription := &model.WebhookSubscription{
+		ID:        generateID(),
+		URL:       u.String(),
+		Events:    dedupeEventTypes(events),
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	ws.store.SaveSubscription(subscription)
+	logger.FromContext(ctx).InfoContext(ctx, "webhook subscription created", "subscription_id", subscription.ID,
+		"events", subscription.Events)
+	return subscription, nil
+}
+
+// DeleteSubscription implement the interface WebhookService.DeleteSubscription, the pending deliveries of the
+// subscription are not sent again
+func (ws *webhooks) DeleteSubscription(ctx context.Context, id string) error {
+	if !ws.store.DeleteSubscription(id) {
+		return fmt.Errorf("%w: %s", errors.ErrWebhookNotFound, id)
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "webhook subscription deleted", "subscription_id", id)
+	return nil
+}
+
+// Deliveries implement the interface WebhookService.Deliveries
+func (ws *webhooks) Deliveries(_ context.Context, subscriptionID string) ([]*model.WebhookDelivery, error) {
+	if _, ok := ws.store.Subscription(subscriptionID); !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWebhookNotFound, subscriptionID)
+	}
+	return ws.store.Deliveries(subscriptionID), nil
+}
+
+// Redeliver implement the interface WebhookService.Redeliver, the event of the delivery is sent again with
+// the same event id in a new delivery
+func (ws *webhooks) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*model.WebhookDelivery, error) {
+	if _, ok := ws.store.Subscription(subscriptionID); !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWebhookNotFound, subscriptionID)
+	}
+	delivery, payload, ok := ws.store.Delivery(deliveryID)
+	if !ok || delivery.SubscriptionID != subscriptionID {
+		return nil, fmt.Errorf("%w: %s", errors.ErrDeliveryNotFound, deliveryID)
+	}
+	redelivery, _ := ws.send(ctx, subscriptionID, delivery.EventID, delivery.EventType, delivery.ID, payload)
+	return redelivery, nil
+}
+
+// Wait waits until the queued deliveries are sent or their attempts are exhausted
+func (ws *webhooks) Wait() {
+	ws.pending.Wait()
+}
+
+// Shutdown waits until the queued deliveries are sent or the context is done, then the remaining deliveries are
+// aborted without more attempts and the workers are stopped; the service can not send deliveries after it
+func (ws *webhooks) Shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		ws.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	ws.abort()
+
+	ws.mu.Lock()
+	if !ws.closed {
+		ws.closed = true
+		close(ws.queue)
+	}
+	ws.mu.Unlock()
+	ws.workers.Wait()
+}
+
+// send stores a new delivery of an event to a subscription and queues it, the delivery is failed when it could
+// not be queued before the context is done or the service is shut down
+func (ws *webhooks) send(ctx context.Context, subscriptionID, eventID string, eventType model.EventType, redeliveryOf string, payload []byte) (*model.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	delivery := &model.WebhookDelivery{
+		ID:             generateID(),
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		EventType:      eventType,
+		RedeliveryOf:   redeliveryOf,
+		Status:         model.DeliveryPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	ws.store.AddDelivery(delivery, payload)
+
+	// the read lock keeps the queue open while the delivery is queued, the shutdown aborts the waiting sends
+	// before it closes the queue
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	if ws.closed {
+		ws.fail(*delivery, "the delivery was not queued: the service is shut down")
+		return delivery, errors.ErrWebhooksShutDown
+	}
+	ws.pending.Add(1)
+	select {
+	case ws.queue <- webhookJob{log: logger.FromContext(ctx), delivery: *delivery, payload: payload}:
+		return delivery, nil
+	case <-ctx.Done():
+		ws.pending.Done()
+		ws.fail(*delivery, "the delivery was not queued: "+ctx.Err().Error())
+		return delivery, ctx.Err()
+	case <-ws.shutdown.Done():
+		ws.pending.Done()
+		ws.fail(*delivery, "the delivery was not queued: the service is shut down")
+		return delivery, errors.ErrWebhooksShutDown
+	}
+}
+
+// fail stores a delivery as failed without more attempts
+func (ws *webhooks) fail(delivery model.WebhookDelivery, reason string) {
+	delivery.Status = model.DeliveryFailed
+	delivery.LastError = reason
+	delivery.UpdatedAt = time.Now().UTC()
+	ws.store.UpdateDelivery(&delivery)
+}
+
+// work sends the queued deliveries until the queue is closed, the deliveries queued when the service is shut
+// down are failed without attempts
+func (ws *webhooks) work() {
+	defer ws.workers.Done()
+	for job := range ws.queue {
+		if ws.shutdown.Err() != nil {
+			ws.fail(job.delivery, "the delivery was aborted at shutdown before its first attempt")
+		} else {
+			ws.deliver(logger.WithLogger(ws.shutdown, job.log), job.delivery, job.payload)
+		}
+		ws.pending.Done()
+	}
+}
+
+// deliver posts a delivery to its subscriber, the attempts are retried with an exponential backoff until the
+// context is done
+func (ws *webhooks) deliver(ctx context.Context, delivery model.WebhookDelivery, payload []byte) {
+	log := logger.FromContext(ctx).With("delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID,
+		"event_type", delivery.EventType)
+
+	backoff := ws.backoff
+	for attempt := uint(1); attempt <= ws.cfg.DeliveryAttempts; attempt++ {
+		subscription, ok := ws.store.Subscription(delivery.SubscriptionID)
+		if !ok {
+			delivery.Status = model.DeliveryFailed
+			delivery.LastError = "the subscription was deleted"
+			delivery.UpdatedAt = time.Now().UTC()
+			ws.store.UpdateDelivery(&delivery)
+			return
+		}
+
+		status, err := ws.sender.Send(ctx, subscription, &delivery, payload)
+		delivery.Attempts = attempt
+		delivery.ResponseStatus = status
+		delivery.UpdatedAt = time.Now().UTC()
+		if err == nil {
+			delivery.Status = model.DeliverySucceeded
+			delivery.LastError = ""
+			ws.store.UpdateDelivery(&delivery)
+			log.InfoContext(ctx, "webhook delivered", "attempt", attempt)
+			return
+		}
+
+		delivery.LastError = err.Error()
+		if attempt == ws.cfg.DeliveryAttempts || ctx.Err() != nil {
+			delivery.Status = model.DeliveryFailed
+		}
+		ws.store.UpdateDelivery(&delivery)
+		log.WarnContext(ctx, "webhook could not be delivered", "attempt", attempt, "error", err)
+		if delivery.Status == model.DeliveryFailed {
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+			backoff *= 2
+		case <-ctx.Done():
+			timer.Stop()
+			ws.fail(delivery, "the delivery was aborted at shutdown after: "+delivery.LastError)
+			return
+		}
+	}
+}
+
+// dedupeEventTypes removes the repeated event types keeping the order
+func dedupeEventTypes(events []model.EventType) []model.EventType {
+	seen := make(map[model.EventType]bool, len(events))
+	deduped := make([]model.EventType, 0, len(events))
+	for _, event := range events {
+		if !seen[event] {
+			seen[event] = true
+			deduped = append(deduped, event)
+		}
+	}
+	return deduped
+}
+
+// generateSecret generates a random secret of 32 bytes to sign the events of a subscription
+func generateSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
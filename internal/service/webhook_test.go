@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	pkgerrors "credit-line/pkg/errors"
+	"credit-line/pkg/outbox"
+	"credit-line/pkg/webhook"
+)
+
+// webhookReceiver local subscriber that verifies the signature of the events it receives
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	failures atomic.Int32
+	events   []model.Event
+	invalid  int
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if wr.failures.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	payload, _ := io.ReadAll(r.Body)
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	if err := webhook.Verify(wr.secret, r.Header.Get(webhook.SignatureHeader), payload, time.Minute, time.Now()); err != nil {
+		wr.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var event model.Event
+	if err := json.Unmarshal(payload, &event); err != nil || r.Header.Get(webhook.EventIDHeader) != event.ID {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	wr.events = append(wr.events, event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// eventTypes retrieves the sorted types of the received events
+func (wr *webhookReceiver) eventTypes() []model.EventType {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	types := make([]model.EventType, 0, len(wr.events))
+	for _, event := range wr.events {
+		types = append(types, event.Type)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// publishDecision publishes the events of a decision to the webhooks as the outbox relay does
+func publishDecision(t *testing.T, s *webhooks, creditLine *model.CreditLine, response *model.CreditLineResponse, exhausted bool) {
+	t.Helper()
+	data := model.NewDecisionEvent(creditLine, response)
+	for _, eventType := range decisionEventTypes(response, exhausted) {
+		event := model.Event{ID: generateID(), Type: eventType, DecisionID: "d1", CreatedAt: time.Now().UTC(), Data: data}
+		payload, _ := json.Marshal(event)
+		if err := s.Publish(context.Background(), outbox.Message{ID: event.ID, Payload: payload}); err != nil {
+			t.Fatalf("unexpected publish error: %v", err)
+		}
+	}
+}
+
+func Test_Webhook_Service_Publish(t *testing.T) {
+	const secret = "0123456789abcdef0123"
+
+	testCases := map[string]struct {
+		events             []model.EventType
+		response           *model.CreditLineResponse
+		exhausted          bool
+		failures           int32
+		signingSecret      string
+		expectedEvents     []model.EventType
+		expectedStatus     model.DeliveryStatus
+		expectedAttempts   uint
+		expectedDeliveries int
+	}{
+		"approved": {
+			events:             []model.EventType{model.DecisionApproved, model.DecisionDeclined},
+			response:           model.NewCreditLineResponse(model.Approved, "145.10"),
+			expectedEvents:     []model.EventType{model.DecisionApproved},
+			expectedStatus:     model.DeliverySucceeded,
+			expectedAttempts:   1,
+			expectedDeliveries: 1,
+		},
+		"declined_not_subscribed": {
+			events:   []model.EventType{model.DecisionApproved},
+			response: model.NewCreditLineResponse(model.Declined, "0.00"),
+		},
+		"declined_retries_exhausted": {
+			events:             []model.EventType{model.DecisionDeclined, model.RetriesExhausted},
+			response:           &model.CreditLineResponse{CreditStatus: model.Declined, CreditLineAuthorized: "0.00", LeadID: "3f2a"},
+			exhausted:          true,
+			expectedEvents:     []model.EventType{model.DecisionDeclined, model.RetriesExhausted},
+			expectedStatus:     model.DeliverySucceeded,
+			expectedAttempts:   1,
+			expectedDeliveries: 2,
+		},
+		"delivered_after_retry": {
+			events:             []model.EventType{model.DecisionApproved},
+			response:           model.NewCreditLineResponse(model.Approved, "145.10"),
+			failures:           2,
+			expectedEvents:     []model.EventType{model.DecisionApproved},
+			expectedStatus:     model.DeliverySucceeded,
+			expectedAttempts:   3,
+			expectedDeliveries: 1,
+		},
+		"delivery_failed": {
+			events:             []model.EventType{model.DecisionApproved},
+			response:           model.NewCreditLineResponse(model.Approved, "145.10"),
+			failures:           5,
+			expectedEvents:     []model.EventType{},
+			expectedStatus:     model.DeliveryFailed,
+			expectedAttempts:   3,
+			expectedDeliveries: 1,
+		},
+		"invalid_signature": {
+			events:             []model.EventType{model.DecisionApproved},
+			response:           model.NewCreditLineResponse(model.Approved, "145.10"),
+			signingSecret:      "another-secret-value",
+			expectedEvents:     []model.EventType{},
+			expectedStatus:     model.DeliveryFailed,
+			expectedAttempts:   3,
+			expectedDeliveries: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			receiver := &webhookReceiver{secret: secret}
+			receiver.failures.Store(tc.failures)
+			srv := httptest.NewServer(receiver)
+			defer srv.Close()
+
+			s := NewWebhooks(webhook.NewStore(10), &env.Webhooks{DeliveryAttempts: 3, Timeout: 1})
+			s.backoff = 0
+			signingSecret := secret
+			if tc.signingSecret != "" {
+				signingSecret = tc.signingSecret
+			}
+			subscription, err := s.CreateSubscription(context.Background(), srv.URL, tc.events, signingSecret)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 100)
+			publishDecision(t, s, creditLine, tc.response, tc.exhausted)
+			s.Wait()
+
+			if got := receiver.eventTypes(); len(tc.expectedEvents) > 0 && !reflect.DeepEqual(tc.expectedEvents, got) {
+				t.Errorf("unexpected events, got: %v, expected: %v", got, tc.expectedEvents)
+			} else if len(tc.expectedEvents) == 0 && len(got) > 0 {
+				t.Errorf("unexpected events, got: %v, expected none", got)
+			}
+
+			deliveries, err := s.Deliveries(context.Background(), subscription.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(deliveries) != tc.expectedDeliveries {
+				t.Fatalf("unexpected deliveries, got: %v, expected: %v", len(deliveries), tc.expectedDeliveries)
+			}
+			for _, delivery := range deliveries {
+				if delivery.Status != tc.expectedStatus || delivery.Attempts != tc.expectedAttempts {
+					t.Errorf("unexpected delivery, got: %v %v, expected: %v %v", delivery.Status, delivery.Attempts,
+						tc.expectedStatus, tc.expectedAttempts)
+				}
+			}
+		})
+	}
+}
+
+func Test_Webhook_Service_Redeliver(t *testing.T) {
+	const secret = "0123456789abcdef0123"
+	receiver := &webhookReceiver{secret: secret}
+	receiver.failures.Store(1)
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := NewWebhooks(webhook.NewStore(10), &env.Webhooks{DeliveryAttempts: 1, Timeout: 1})
+	subscription, err := s.CreateSubscription(ctx, srv.URL, []model.EventType{model.DecisionDeclined}, secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+	publishDecision(t, s, creditLine, model.NewCreditLineResponse(model.Declined, "0.00"), false)
+	s.Wait()
+
+	deliveries, _ := s.Deliveries(ctx, subscription.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != model.DeliveryFailed {
+		t.Fatalf("unexpected deliveries: %v", deliveries)
+	}
+
+	redelivery, err := s.Redeliver(ctx, subscription.ID, deliveries[0].ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Wait()
+
+	deliveries, _ = s.Deliveries(ctx, subscription.ID)
+	if len(deliveries) != 2 || deliveries[0].ID != redelivery.ID || deliveries[0].Status != model.DeliverySucceeded {
+		t.Fatalf("unexpected deliveries after the redelivery: %v", deliveries)
+	}
+	if deliveries[0].EventID != deliveries[1].EventID || deliveries[0].RedeliveryOf != deliveries[1].ID {
+		t.Errorf("the redelivery must send the same event, got: %v", deliveries)
+	}
+
+	if _, err := s.Redeliver(ctx, subscription.ID, "unknown"); !errors.Is(err, pkgerrors.ErrDeliveryNotFound) {
+		t.Errorf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrDeliveryNotFound)
+	}
+	if err := s.DeleteSubscription(ctx, subscription.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Redeliver(ctx, subscription.ID, redelivery.ID); !errors.Is(err, pkgerrors.ErrWebhookNotFound) {
+		t.Errorf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrWebhookNotFound)
+	}
+}
+
+func Test_Webhook_Service_Shutdown(t *testing.T) {
+	const secret = "0123456789abcdef0123"
+	receiver := &webhookReceiver{secret: secret}
+	receiver.failures.Store(10)
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := NewWebhooks(webhook.NewStore(10), &env.Webhooks{DeliveryAttempts: 5, Timeout: 1, Workers: 1})
+	s.backoff = time.Hour
+	subscription, err := s.CreateSubscription(ctx, srv.URL, []model.EventType{model.DecisionDeclined}, secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	creditLine := model.NewCreditLine("SME", "2021-07-19T16:32:59.860Z", 435.30, 4235.45, 1000)
+	publishDecision(t, s, creditLine, model.NewCreditLineResponse(model.Declined, "0.00"), false)
+
+	// the delivery waits an hour for its second attempt, the shutdown aborts it once its context is done
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	s.Shutdown(shutdownCtx)
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("the shutdown waited for the retries, elapsed: %v", elapsed)
+	}
+
+	deliveries, _ := s.Deliveries(ctx, subscription.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != model.DeliveryFailed || deliveries[0].Attempts != 1 {
+		t.Fatalf("unexpected deliveries: %v", deliveries)
+	}
+
+	// the events published after the shutdown are not acknowledged, so the relay publishes them again
+	msg := outbox.Message{ID: "e1", Payload: []byte(`{"id":"e1","type":"decision.declined"}`)}
+	if err := s.Publish(ctx, msg); !errors.Is(err, pkgerrors.ErrWebhooksShutDown) {
+		t.Errorf("unexpected error, got: %v, expected: %v", err, pkgerrors.ErrWebhooksShutDown)
+	}
+}
+
+func Test_Webhook_Service_Create_Subscription(t *testing.T) {
+	testCases := map[string]struct {
+		url           string
+		secret        string
+		expectedError error
+	}{
+		"generated_secret": {
+			url: "https://crm.example.com/hooks",
+		},
+		"given_secret": {
+			url:    "http://localhost:8080/hooks",
+			secret: "0123456789abcdef",
+		},
+		"relative_url": {
+			url:           "/hooks",
+			expectedError: pkgerrors.ErrInvalidWebhookURL,
+		},
+		"unsupported_scheme": {
+			url:           "ftp://crm.example.com/hooks",
+			expectedError: pkgerrors.ErrInvalidWebhookURL,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := NewWebhooks(webhook.NewStore(10), &env.Webhooks{DeliveryAttempts: 1, Timeout: 1})
+			got, err := s.CreateSubscription(context.Background(), tc.url,
+				[]model.EventType{model.DecisionApproved, model.DecisionApproved}, tc.secret)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("unexpected error, got: %v, expected: %v", err, tc.expectedError)
+			}
+			if tc.expectedError != nil {
+				return
+			}
+			if got.Secret == "" || (tc.secret != "" && got.Secret != tc.secret) {
+				t.Errorf("unexpected secret, got: %q", got.Secret)
+			}
+			if !reflect.DeepEqual(got.Events, []model.EventType{model.DecisionApproved}) {
+				t.Errorf("unexpected events, got: %v", got.Events)
+			}
+			for _, listed := range s.Subscriptions(context.Background()) {
+				if listed.Secret != "" {
+					t.Errorf("the listed subscriptions must not expose the secret")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package access
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// listFile struct with the content of the list file, a YAML or JSON document
+type listFile struct {
+	Allow []string `yaml:"allow"`
+	Block []string `yaml:"block"`
+}
+
+// FileLoader struct that loads the entries of a list file in the file source of an access list
+type FileLoader struct {
+	file string
+	list *List
+}
+
+// NewFileLoader creates a new pointer of FileLoader struct
+func NewFileLoader(file string, list *List) *FileLoader {
+	return &FileLoader{
+		file: file,
+		list: list,
+	}
+}
+
+// Load reads the list file and replaces the entries of the file source, the current entries are kept
+// when the file is invalid; it retrieves the number of entries loaded
+func (fl *FileLoader) Load() (int, error) {
+	content, err := os.ReadFile(fl.file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read access list file %s, %w", fl.file, err)
+	}
+
+	var document listFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&document); err != nil && err != io.EOF {
+		return 0, fmt.Errorf("failed to parse access list file %s, %w", fl.file, err)
+	}
+
+	entries := append(ParseEntries(document.Allow, AllowAction), ParseEntries(document.Block, BlockAction)...)
+	if err := fl.list.Replace(FileSource, entries); err != nil {
+		return 0, fmt.Errorf("invalid access list file %s, %w", fl.file, err)
+	}
+	return len(entries), nil
+}
+
+// Watch reloads the list file when it is modified or when the process receives a SIGHUP signal,
+// until the context is done; an invalid file is logged and the current entries are kept
+func (fl *FileLoader) Watch(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	// the file is not polled when the interval is zero, only the SIGHUP reloads the list
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	modTime := fl.retrieveModTime()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-tick:
+			current := fl.retrieveModTime()
+			if current.Equal(modTime) {
+				continue
+			}
+			modTime = current
+		}
+
+		entries, err := fl.Load()
+		if err != nil {
+			l.Error("access list reload rejected", "error", err)
+			continue
+		}
+		l.Info("access list reloaded", "file", fl.file, "entries", entries)
+	}
+}
+
+// retrieveModTime retrieves the modification time of the list file, zero when it does not exist
+func (fl *FileLoader) retrieveModTime() time.Time {
+	info, err := os.Stat(fl.file)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,184 @@
+package access
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+
+	"credit-line/pkg/errors"
+)
+
+const (
+	// AllowAction action of the entries that skip the rate limits and the decline retries
+	AllowAction = "allow"
+	// BlockAction action of the entries that reject every request
+	BlockAction = "block"
+)
+
+const (
+	// ConfigSource source of the entries of the ACCESS_ALLOW_LIST and ACCESS_BLOCK_LIST variables
+	ConfigSource = "config"
+	// FileSource source of the entries of the ACCESS_LIST_FILE file
+	FileSource = "file"
+	// AdminSource source of the entries added with the admin API
+	AdminSource = "admin"
+)
+
+// Entry struct with a network of the access list and its action
+type Entry struct {
+	CIDR   string `json:"cidr"`
+	Action string `json:"action"`
+	Source string `json:"source,omitempty"`
+}
+
+// List struct with the allowed and blocked networks by source, the blocked networks have priority
+type List struct {
+	mu      sync.RWMutex
+	sources map[string]map[netip.Prefix]string
+}
+
+// sharedList variable with the access list shared by all the transports
+var sharedList = NewList()
+
+// NewList creates a new pointer of List struct without entries
+func NewList() *List {
+	return &List{
+		sources: make(map[string]map[netip.Prefix]string),
+	}
+}
+
+// RetrieveList retrieves the access list shared by all the transports
+func RetrieveList() *List {
+	return sharedList
+}
+
+// ParsePrefix parses an ip or a network in CIDR notation, an ip is a network with a single address
+func ParsePrefix(cidr string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(cidr); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w %q", errors.ErrInvalidCIDR, cidr)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Add adds a network with an action to the admin source, it replaces the action of an existing network
+func (l *List) Add(cidr, action string) (Entry, error) {
+	if action != AllowAction && action != BlockAction {
+		return Entry{}, fmt.Errorf("invalid action %q", action)
+	}
+	prefix, err := ParsePrefix(cidr)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sources[AdminSource] == nil {
+		l.sources[AdminSource] = make(map[netip.Prefix]string)
+	}
+	l.sources[AdminSource][prefix] = action
+	return Entry{CIDR: prefix.String(), Action: action, Source: AdminSource}, nil
+}
+
+// Remove removes a network of the admin source, it retrieves false when the network was not in the source
+func (l *List) Remove(cidr string) (bool, error) {
+	prefix, err := ParsePrefix(cidr)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.sources[AdminSource][prefix]
+	delete(l.sources[AdminSource], prefix)
+	return ok, nil
+}
+
+// Replace replaces all the entries of a source, the source is not modified when an entry is invalid
+func (l *List) Replace(source string, entries []Entry) error {
+	replacement := make(map[netip.Prefix]string, len(entries))
+	for _, entry := range entries {
+		if entry.Action != AllowAction && entry.Action != BlockAction {
+			return fmt.Errorf("invalid action %q for %s", entry.Action, entry.CIDR)
+		}
+		prefix, err := ParsePrefix(entry.CIDR)
+		if err != nil {
+			return err
+		}
+		replacement[prefix] = entry.Action
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sources[source] = replacement
+	return nil
+}
+
+// Entries retrieves the entries of all the sources sorted by network and source
+func (l *List) Entries() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := make([]Entry, 0)
+	for source, prefixes := range l.sources {
+		for prefix, action := range prefixes {
+			entries = append(entries, Entry{CIDR: prefix.String(), Action: action, Source: source})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CIDR != entries[j].CIDR {
+			return entries[i].CIDR < entries[j].CIDR
+		}
+		return entries[i].Source < entries[j].Source
+	})
+	return entries
+}
+
+// Action retrieves the action applied to an ip, an empty action when the ip is not in the list
+func (l *List) Action(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	action := ""
+	for _, prefixes := range l.sources {
+		for prefix, a := range prefixes {
+			if !prefix.Contains(addr) {
+				continue
+			}
+			if a == BlockAction {
+				return BlockAction
+			}
+			action = a
+		}
+	}
+	return action
+}
+
+// IsAllowed retrieves true when the ip is in an allowed network and not in a blocked one
+func (l *List) IsAllowed(ip string) bool {
+	return l.Action(ip) == AllowAction
+}
+
+// IsBlocked retrieves true when the ip is in a blocked network
+func (l *List) IsBlocked(ip string) bool {
+	return l.Action(ip) == BlockAction
+}
+
+// ParseEntries builds the entries of a list of ips or networks with the same action
+func ParseEntries(cidrs []string, action string) []Entry {
+	entries := make([]Entry, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		entries = append(entries, Entry{CIDR: cidr, Action: action})
+	}
+	return entries
+}
@@ -0,0 +1,77 @@
+package access
+
+import (
+	"net/netip"
+	"strings"
+	"sync/atomic"
+)
+
+// TrustedProxies struct with the networks of the proxies allowed to forward the ip of the clients
+type TrustedProxies struct {
+	prefixes atomic.Pointer[[]netip.Prefix]
+}
+
+// sharedProxies variable with the trusted proxies shared by all the transports
+var sharedProxies = new(TrustedProxies)
+
+// RetrieveTrustedProxies retrieves the trusted proxies shared by all the transports
+func RetrieveTrustedProxies() *TrustedProxies {
+	return sharedProxies
+}
+
+// Replace replaces the networks of the trusted proxies, they are not modified when a network is invalid
+func (tp *TrustedProxies) Replace(cidrs []string) error {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := ParsePrefix(cidr)
+		if err != nil {
+			return err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	tp.prefixes.Store(&prefixes)
+	return nil
+}
+
+// Trusts retrieves true when the ip belongs to a trusted proxy
+func (tp *TrustedProxies) Trusts(addr netip.Addr) bool {
+	prefixes := tp.prefixes.Load()
+	if prefixes == nil {
+		return false
+	}
+	for _, prefix := range *prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP retrieves the ip of the client from the ip of the peer and the X-Forwarded-For values,
+// the values are only used when the peer is a trusted proxy and they are read from right to left until
+// the first ip that is not a trusted proxy, so the values added by the client are ignored; the peer ip is
+// retrieved when a value is not an ip
+func (tp *TrustedProxies) ClientIP(peerIP string, forwardedFor []string) string {
+	peer, err := netip.ParseAddr(peerIP)
+	if err != nil || !tp.Trusts(peer.Unmap()) {
+		return peerIP
+	}
+
+	ips := strings.Split(strings.Join(forwardedFor, ","), ",")
+	client := peerIP
+	for i := len(ips) - 1; i >= 0; i-- {
+		value := strings.TrimSpace(ips[i])
+		if value == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return peerIP
+		}
+		client = addr.Unmap().String()
+		if !tp.Trusts(addr.Unmap()) {
+			return client
+		}
+	}
+	return client
+}
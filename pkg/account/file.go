@@ -0,0 +1,98 @@
+package account
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"credit-line/internal/model"
+)
+
+// record struct with a line of the journal, an account with the movement and the ledger transaction that
+// updated its balances; the hash of the token is kept since it is not rendered with the account
+type record struct {
+	Account     *model.Account           `json:"account,omitempty"`
+	TokenHash   string                   `json:"tokenHash,omitempty"`
+	Movement    *model.Movement          `json:"movement,omitempty"`
+	Transaction *model.LedgerTransaction `json:"transaction,omitempty"`
+}
+
+// journal struct with the file where each change of the accounts is written in a single synced line, so a crash
+// can not store a movement without its account balances and its ledger transaction
+type journal struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// NewFileStore opens the journal file and replays it to retrieve the accounts, their movements and the ledger
+// transactions, a last line torn by a crash is discarded
+func NewFileStore(path string) (*Store, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open accounts file %s, %w", path, err)
+	}
+	s := NewStore()
+	s.journal = &journal{path: path, file: f}
+	if err := s.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay applies the lines of the journal, the file is truncated after the last complete line
+func (s *Store) replay() error {
+	reader := bufio.NewReader(s.journal.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a line without the new line character was torn by a crash while it was written
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read accounts file %s, %w", s.journal.path, err)
+		}
+
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return fmt.Errorf("corrupted accounts file %s at offset %d, %w", s.journal.path, s.journal.size, err)
+		}
+		s.apply(r)
+		s.journal.size += int64(len(line))
+	}
+	return s.journal.file.Truncate(s.journal.size)
+}
+
+// append writes and syncs a line of the journal, a partial line is truncated so the next line is not corrupted;
+// the changes are only kept in memory without journal
+func (j *journal) append(r record) error {
+	if j == nil {
+		return nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.WriteAt(append(line, '\n'), j.size); err != nil {
+		_ = j.file.Truncate(j.size)
+		return fmt.Errorf("failed to write accounts file %s, %w", j.path, err)
+	}
+	if err := j.file.Sync(); err != nil {
+		_ = j.file.Truncate(j.size)
+		return fmt.Errorf("failed to sync accounts file %s, %w", j.path, err)
+	}
+	j.size += int64(len(line) + 1)
+	return nil
+}
+
+// close closes the file of the journal, if any
+func (j *journal) close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
@@ -0,0 +1,169 @@
+package account
+
+import (
+	"sort"
+	"sync"
+
+	"credit-line/internal/model"
+)
+
+// Store struct with the credit line accounts, the account of each decision, the movements of each account and
+// the ledger transactions of the movements; the changes are written to the journal before they are stored in
+// memory when the store has one
+type Store struct {
+	mu           sync.RWMutex
+	accounts     map[string]*model.Account
+	decisions    map[string]string
+	movements    map[string][]*model.Movement
+	transactions []*model.LedgerTransaction
+	journal      *journal
+}
+
+// NewStore creates a new pointer of Store struct without accounts that keeps them in memory
+func NewStore() *Store {
+	return &Store{
+		accounts:  make(map[string]*model.Account),
+		decisions: make(map[string]string),
+		movements: make(map[string][]*model.Movement),
+	}
+}
+
+// Save stores a copy of an account, the token of the account is not stored
+func (s *Store) Save(account *model.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := record{Account: withoutToken(account), TokenHash: account.TokenHash}
+	if err := s.journal.append(r); err != nil {
+		return err
+	}
+	s.apply(r)
+	return nil
+}
+
+// SaveMovement stores a copy of an account with the movement that updated its balances and the ledger
+// transaction that posted the movement, all of them or none
+func (s *Store) SaveMovement(account *model.Account, movement *model.Movement, transaction *model.LedgerTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := record{Account: withoutToken(account), TokenHash: account.TokenHash, Movement: movement,
+		Transaction: transaction}
+	if err := s.journal.append(r); err != nil {
+		return err
+	}
+	s.apply(r)
+	return nil
+}
+
+// Transactions retrieves the ledger transactions of the movements in the order they were stored, the ledger is
+// rebuilt with them when the store is opened
+func (s *Store) Transactions() []*model.LedgerTransaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	transactions := make([]*model.LedgerTransaction, 0, len(s.transactions))
+	for _, transaction := range s.transactions {
+		stored := *transaction
+		stored.Entries = append([]model.LedgerEntry(nil), transaction.Entries...)
+		transactions = append(transactions, &stored)
+	}
+	return transactions
+}
+
+// Close closes the journal of the store, if any
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.journal.close()
+}
+
+// Account retrieves a copy of an account, false when the account does not exist
+func (s *Store) Account(id string) (*model.Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		return nil, false
+	}
+	stored := *account
+	return &stored, true
+}
+
+// DecisionAccount retrieves a copy of the account opened for a decision, false when the decision was not accepted
+func (s *Store) DecisionAccount(decisionID string) (*model.Account, bool) {
+	s.mu.RLock()
+	id, ok := s.decisions[decisionID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return s.Account(id)
+}
+
+// Accounts retrieves a copy of the accounts, newest first
+func (s *Store) Accounts() []*model.Account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	accounts := make([]*model.Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		stored := *account
+		accounts = append(accounts, &stored)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].OpenedAt.Equal(accounts[j].OpenedAt) {
+			return accounts[i].ID < accounts[j].ID
+		}
+		return accounts[i].OpenedAt.After(accounts[j].OpenedAt)
+	})
+	return accounts
+}
+
+// Movements retrieves a copy of the movements of an account from the oldest to the newest
+func (s *Store) Movements(accountID string) []*model.Movement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	movements := make([]*model.Movement, 0, len(s.movements[accountID]))
+	for _, movement := range s.movements[accountID] {
+		stored := *movement
+		movements = append(movements, &stored)
+	}
+	return movements
+}
+
+// ReferenceMovement retrieves a copy of the movement of an account with a reference, false when the reference
+// was not used in the account
+func (s *Store) ReferenceMovement(accountID, reference string) (*model.Movement, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, movement := range s.movements[accountID] {
+		if movement.Reference == reference {
+			stored := *movement
+			return &stored, true
+		}
+	}
+	return nil, false
+}
+
+// apply stores a copy of the values of a record in memory, the caller must hold the lock
+func (s *Store) apply(r record) {
+	if r.Account != nil {
+		stored := *r.Account
+		stored.TokenHash = r.TokenHash
+		s.accounts[stored.ID] = &stored
+		s.decisions[stored.DecisionID] = stored.ID
+	}
+	if r.Movement != nil {
+		stored := *r.Movement
+		s.movements[stored.AccountID] = append(s.movements[stored.AccountID], &stored)
+	}
+	if r.Transaction != nil {
+		stored := *r.Transaction
+		stored.Entries = append([]model.LedgerEntry(nil), r.Transaction.Entries...)
+		s.transactions = append(s.transactions, &stored)
+	}
+}
+
+// withoutToken retrieves a copy of an account without its token, so the token is never written to the journal
+func withoutToken(account *model.Account) *model.Account {
+	stored := *account
+	stored.Token = ""
+	return &stored
+}
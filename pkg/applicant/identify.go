@@ -0,0 +1,91 @@
+package applicant
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"credit-line/internal/model"
+)
+
+// hashLength length of the hex hashes of the identifiers
+const hashLength = 32
+
+var (
+	// taxIDPattern matches a normalized RFC, 3 letters of a company or 4 of a person, the date and the homoclave
+	taxIDPattern = regexp.MustCompile(`^[A-ZÑ&]{3,4}[0-9]{2}(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])[A-Z0-9]{2}[0-9A]$`)
+	// accents replacer of the accented letters of the legal names
+	accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+	// legalSuffixes company type suffixes removed from the normalized legal names, the longest first
+	legalSuffixes = []string{"s de rl de cv", "sapi de cv", "sab de cv", "sa de cv", "s de rl", "sapi", "sas",
+		"sa", "sc", "inc", "llc", "ltd"}
+)
+
+// NormalizeTaxID retrieves a tax id in upper case without spaces, dashes and dots
+func NormalizeTaxID(taxID string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.TrimSpace(taxID)))
+}
+
+// ValidTaxID reports if a tax id is a valid RFC once normalized
+func ValidTaxID(taxID string) bool {
+	return taxIDPattern.MatchString(NormalizeTaxID(taxID))
+}
+
+// NormalizeLegalName retrieves a legal name in lower case without accents, punctuation, repeated spaces and
+// company type suffix, so "Acme, S.A. de C.V." and "ACME" are the same name
+func NormalizeLegalName(legalName string) string {
+	name := accents.Replace(strings.ToLower(legalName))
+	name = strings.NewReplacer(".", "", ",", "").Replace(name)
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+	for _, suffix := range legalSuffixes {
+		if trimmed := strings.TrimSuffix(name, " "+suffix); trimmed != name {
+			return trimmed
+		}
+	}
+	return name
+}
+
+// NormalizeEmail retrieves an email in lower case without spaces
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Identify retrieves the applicant of the identifiers with the HMAC-SHA256 hashes of the normalized identifiers,
+// nil when no identifier was sent
+func Identify(key []byte, identifiers model.ApplicantIdentifiers) *model.Applicant {
+	applicant := &model.Applicant{
+		TaxIDHash:     hash(key, "taxId", NormalizeTaxID(identifiers.TaxID)),
+		LegalNameHash: hash(key, "legalName", NormalizeLegalName(identifiers.LegalName)),
+		EmailHash:     hash(key, "email", NormalizeEmail(identifiers.Email)),
+	}
+	hashes := applicant.Hashes()
+	if len(hashes) == 0 {
+		return nil
+	}
+	// the tax id identifies the applicant over the email and the email over the legal name
+	switch {
+	case applicant.TaxIDHash != "":
+		applicant.ID = applicant.TaxIDHash
+	case applicant.EmailHash != "":
+		applicant.ID = applicant.EmailHash
+	default:
+		applicant.ID = applicant.LegalNameHash
+	}
+	return applicant
+}
+
+// hash retrieves the keyed hash of a normalized identifier, the kind separates the hashes of each identifier;
+// empty when the identifier is empty
+func hash(key []byte, kind, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
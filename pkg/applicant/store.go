@@ -0,0 +1,87 @@
+package applicant
+
+import (
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+// application struct with an application of an applicant, only the ip and the time are kept
+type application struct {
+	ip string
+	at time.Time
+}
+
+// indexed struct with an identifier hash of an application, in the order of the applications
+type indexed struct {
+	hash string
+	at   time.Time
+}
+
+// Store struct with the recent applications of the identified applicants by identifier hash
+type Store struct {
+	mu           sync.Mutex
+	applications map[string][]*application
+	order        []indexed
+}
+
+// NewStore creates a new pointer of Store struct without applications
+func NewStore() *Store {
+	return &Store{
+		applications: make(map[string][]*application),
+	}
+}
+
+// Record stores an application of an applicant from an ip and retrieves the history of the applications that
+// share an identifier with it since a time, the older applications are removed
+func (s *Store) Record(applicant *model.Applicant, ip string, at, since time.Time) model.ApplicationHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(since)
+
+	var history model.ApplicationHistory
+	seen := make(map[*application]bool)
+	ips := make(map[string]bool)
+	for _, hash := range applicant.Hashes() {
+		for _, previous := range s.applications[hash] {
+			if seen[previous] || previous.at.Before(since) {
+				continue
+			}
+			seen[previous] = true
+			history.Applications++
+			if previous.ip != ip && !ips[previous.ip] {
+				ips[previous.ip] = true
+				history.IPs++
+			}
+		}
+	}
+	history.Repeat = history.IPs > 0
+
+	current := &application{ip: ip, at: at}
+	for _, hash := range applicant.Hashes() {
+		s.applications[hash] = append(s.applications[hash], current)
+		s.order = append(s.order, indexed{hash: hash, at: at})
+	}
+	return history
+}
+
+// expire removes the applications before a time, the caller must hold the lock
+func (s *Store) expire(before time.Time) {
+	n := 0
+	for ; n < len(s.order) && s.order[n].at.Before(before); n++ {
+		hash := s.order[n].hash
+		kept := s.applications[hash][:0]
+		for _, previous := range s.applications[hash] {
+			if !previous.at.Before(before) {
+				kept = append(kept, previous)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.applications, hash)
+			continue
+		}
+		s.applications[hash] = kept
+	}
+	s.order = s.order[n:]
+}
@@ -0,0 +1,346 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/pii"
+)
+
+// genesisHash previous hash of the first entry of the log
+var genesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// ErrChainBroken is returned when an entry of the log does not match its hash or the hash of the previous entry
+var ErrChainBroken = errors.New("audit log chain broken")
+
+// Report struct with the result of the verification of an audit log
+type Report struct {
+	Entries  uint64 `json:"entries"`
+	HeadHash string `json:"headHash"`
+	RootHash string `json:"rootHash"`
+}
+
+// Log struct that appends the audit entries to a file, the file is only appended and each line is synced
+type Log struct {
+	mu        sync.Mutex
+	path      string
+	file      *os.File
+	leaves    [][]byte
+	entries   map[string]uint64
+	positions []int64
+	head      string
+	keyring   *pii.Keyring
+}
+
+// sealedFields struct with the applicant data of an entry encrypted in the log
+type sealedFields struct {
+	Client              model.AuditClient `json:"client"`
+	CashBalance         float64           `json:"cashBalance"`
+	MonthlyRevenue      float64           `json:"monthlyRevenue"`
+	RequestedCreditLine float64           `json:"requestedCreditLine"`
+}
+
+// Open opens the audit log file and verifies its chain, the log is not opened when an entry was modified; the
+// applicant data of the new entries is encrypted with the keyring when it is not nil
+func Open(path string, keyring *pii.Keyring) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s, %w", path, err)
+	}
+	l := &Log{path: path, file: f, entries: make(map[string]uint64), head: genesisHash, keyring: keyring}
+	if err := l.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Append chains an entry to the head of the log and writes it, it retrieves the entry with its index and hashes
+func (l *Log) Append(entry model.AuditEntry) (model.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.Index = uint64(len(l.leaves))
+	entry.PrevHash = l.head
+	if err := l.seal(&entry); err != nil {
+		return model.AuditEntry{}, fmt.Errorf("failed to encrypt the audit entry, %w", err)
+	}
+	hash, err := EntryHash(entry)
+	if err != nil {
+		return model.AuditEntry{}, err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return model.AuditEntry{}, err
+	}
+	position, err := l.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return model.AuditEntry{}, fmt.Errorf("failed to write audit log %s, %w", l.path, err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		_ = l.file.Truncate(position)
+		return model.AuditEntry{}, fmt.Errorf("failed to write audit log %s, %w", l.path, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		_ = l.file.Truncate(position)
+		return model.AuditEntry{}, fmt.Errorf("failed to sync audit log %s, %w", l.path, err)
+	}
+	l.add(entry, position)
+	return entry, nil
+}
+
+// Proof retrieves the inclusion proof of the entry of a decision in the current tree of the log, false when the
+// decision is not in the log
+func (l *Log) Proof(decisionID string) (*model.InclusionProof, bool, error) {
+	l.mu.Lock()
+	index, ok := l.entries[decisionID]
+	if !ok {
+		l.mu.Unlock()
+		return nil, false, nil
+	}
+	leaves, head := l.leaves, l.head
+	// the entry is read with the lock because the retention can replace the file
+	entry, err := l.readEntry(l.positions[index])
+	l.mu.Unlock()
+	if err != nil {
+		return nil, true, err
+	}
+	path := auditPath(int(index), leaves)
+	encoded := make([]string, 0, len(path))
+	for _, hash := range path {
+		encoded = append(encoded, hex.EncodeToString(hash))
+	}
+	return &model.InclusionProof{
+		Entry:     entry,
+		LeafIndex: index,
+		TreeSize:  uint64(len(leaves)),
+		RootHash:  hex.EncodeToString(rootHash(leaves)),
+		AuditPath: encoded,
+		HeadHash:  head,
+	}, true, nil
+}
+
+// Shred removes the data keys of the sealed entries appended before a time, the client and the amounts of the
+// entries can not be decrypted anymore and the chain is not changed; the entries that are not sealed are retained
+// because they can not be changed without breaking the chain
+func (l *Log) Shred(before time.Time, dryRun bool) (model.RetentionCounts, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var counts model.RetentionCounts
+	changed, err := pii.RewriteLines(l.path, func(line []byte) ([]byte, bool, error) {
+		var entry model.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, false, err
+		}
+		if !entry.Timestamp.Before(before) {
+			return nil, false, nil
+		}
+		counts.Expired++
+		switch {
+		case entry.Sealed == nil:
+			counts.Retained++
+			return nil, false, nil
+		case pii.Shredded(entry.Sealed):
+			return nil, false, nil
+		}
+		counts.Anonymized++
+		if dryRun {
+			return nil, false, nil
+		}
+		entry.Sealed = pii.Shred(entry.Sealed)
+		shredded, err := json.Marshal(entry)
+		return shredded, true, err
+	})
+	if err != nil || changed == 0 {
+		return counts, err
+	}
+	return counts, l.reopen()
+}
+
+// Close closes the file of the log
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Verify reads an audit log and checks the hash chain of all its entries, it retrieves the error of the first
+// modified, removed or reordered entry
+func Verify(r io.Reader) (*Report, error) {
+	l := &Log{entries: make(map[string]uint64), head: genesisHash}
+	if err := l.read(r); err != nil {
+		return nil, err
+	}
+	return &Report{
+		Entries:  uint64(len(l.leaves)),
+		HeadHash: l.head,
+		RootHash: hex.EncodeToString(rootHash(l.leaves)),
+	}, nil
+}
+
+// reopen opens again the file of the log after it was replaced and replays it, the caller must hold the lock
+func (l *Log) reopen() error {
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s, %w", l.path, err)
+	}
+	l.file.Close()
+	l.file = f
+	l.leaves, l.entries, l.positions, l.head = nil, make(map[string]uint64), nil, genesisHash
+	return l.replay()
+}
+
+// replay reads and verifies the file of the log
+func (l *Log) replay() error {
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := l.read(l.file); err != nil {
+		return fmt.Errorf("failed to verify audit log %s, %w", l.path, err)
+	}
+	return nil
+}
+
+// read applies the lines of a log checking that each entry is chained to the previous one
+func (l *Log) read(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	var position int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		var entry model.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("%w: invalid entry %d, %v", ErrChainBroken, len(l.leaves), err)
+		}
+		hash, err := EntryHash(entry)
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.Index != uint64(len(l.leaves)):
+			return fmt.Errorf("%w: entry %d has the index %d", ErrChainBroken, len(l.leaves), entry.Index)
+		case entry.PrevHash != l.head:
+			return fmt.Errorf("%w: entry %d is not chained to the previous entry", ErrChainBroken, entry.Index)
+		case entry.Hash != hash:
+			return fmt.Errorf("%w: entry %d does not match its hash", ErrChainBroken, entry.Index)
+		}
+		l.add(entry, position)
+		position += int64(len(line))
+	}
+}
+
+// add indexes an entry, the caller must hold the lock
+func (l *Log) add(entry model.AuditEntry, position int64) {
+	hash, _ := hex.DecodeString(entry.Hash)
+	l.leaves = append(l.leaves, leafHash(hash))
+	l.entries[entry.DecisionID] = entry.Index
+	l.positions = append(l.positions, position)
+	l.head = entry.Hash
+}
+
+// readEntry reads the entry of the line that starts at position
+func (l *Log) readEntry(position int64) (model.AuditEntry, error) {
+	reader := bufio.NewReader(io.NewSectionReader(l.file, position, 1<<20))
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return model.AuditEntry{}, fmt.Errorf("failed to read audit log %s, %w", l.path, err)
+	}
+	var entry model.AuditEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return model.AuditEntry{}, fmt.Errorf("failed to read audit log %s, %w", l.path, err)
+	}
+	return entry, nil
+}
+
+// EntryHash computes the hash of an entry, it covers the json of the entry without its hash and the hash of the
+// previous entry is part of the json; the encrypted data key of the envelope is not covered so a key rotation
+// does not break the chain
+func EntryHash(entry model.AuditEntry) (string, error) {
+	entry.Hash = ""
+	if entry.Sealed != nil {
+		entry.Sealed = &model.Envelope{Ciphertext: entry.Sealed.Ciphertext}
+	}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// seal moves the client and the amounts of the inputs of an entry to its envelope, the entry is not changed
+// without keyring
+func (l *Log) seal(entry *model.AuditEntry) error {
+	if l.keyring == nil {
+		return nil
+	}
+	sealed, err := l.keyring.Seal(sealedFields{
+		Client:              entry.Client,
+		CashBalance:         entry.Inputs.CashBalance,
+		MonthlyRevenue:      entry.Inputs.MonthlyRevenue,
+		RequestedCreditLine: entry.Inputs.RequestedCreditLine,
+	}, entry.DecisionID)
+	if err != nil {
+		return err
+	}
+	entry.Client = model.AuditClient{}
+	entry.Inputs.CashBalance, entry.Inputs.MonthlyRevenue, entry.Inputs.RequestedCreditLine = 0, 0, 0
+	entry.Sealed = sealed
+	return nil
+}
+
+// Unseal retrieves a copy of an entry with the client and the amounts of the inputs decrypted with the keyring,
+// the hash of the copy does not match its content
+func Unseal(entry model.AuditEntry, keyring *pii.Keyring) (model.AuditEntry, error) {
+	if entry.Sealed == nil {
+		return entry, nil
+	}
+	var fields sealedFields
+	if err := keyring.Open(entry.Sealed, &fields, entry.DecisionID); err != nil {
+		return model.AuditEntry{}, err
+	}
+	entry.Client = fields.Client
+	entry.Inputs.CashBalance, entry.Inputs.MonthlyRevenue = fields.CashBalance, fields.MonthlyRevenue
+	entry.Inputs.RequestedCreditLine = fields.RequestedCreditLine
+	entry.Sealed = nil
+	return entry, nil
+}
+
+// RewrapFile encrypts again with the primary key of the keyring the data keys of the sealed entries of an audit
+// log file, the chain is not changed; it retrieves the number of entries encrypted again and the application
+// must be stopped
+func RewrapFile(path string, keyring *pii.Keyring) (int, error) {
+	return pii.RewriteLines(path, func(line []byte) ([]byte, bool, error) {
+		var entry model.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, false, err
+		}
+		if entry.Sealed == nil {
+			return nil, false, nil
+		}
+		sealed, ok, err := keyring.Rewrap(entry.Sealed)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		entry.Sealed = sealed
+		rewritten, err := json.Marshal(entry)
+		return rewritten, true, err
+	})
+}
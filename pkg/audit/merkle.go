@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"credit-line/internal/model"
+)
+
+// ErrInvalidProof is returned when an inclusion proof does not match the root hash
+var ErrInvalidProof = errors.New("invalid inclusion proof")
+
+// leafHash computes the RFC 6962 hash of a leaf
+func leafHash(data []byte) []byte {
+	sum := sha256.Sum256(append([]byte{0x00}, data...))
+	return sum[:]
+}
+
+// nodeHash computes the RFC 6962 hash of an inner node
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// splitPoint retrieves the largest power of two smaller than n
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// rootHash computes the Merkle tree hash of the leaf hashes
+func rootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return nodeHash(rootHash(leaves[:k]), rootHash(leaves[k:]))
+}
+
+// auditPath computes the RFC 6962 audit path of the leaf m, from the leaf to the root
+func auditPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if m < k {
+		return append(auditPath(m, leaves[:k]), rootHash(leaves[k:]))
+	}
+	return append(auditPath(m-k, leaves[k:]), rootHash(leaves[:k]))
+}
+
+// VerifyProof checks that the entry of an inclusion proof is included in the tree of the root hash, the hash of
+// the entry is computed again so a modified entry is rejected
+func VerifyProof(proof *model.InclusionProof) error {
+	hash, err := EntryHash(proof.Entry)
+	if err != nil {
+		return err
+	}
+	if hash != proof.Entry.Hash {
+		return fmt.Errorf("%w: the entry does not match its hash", ErrInvalidProof)
+	}
+	if proof.LeafIndex >= proof.TreeSize {
+		return fmt.Errorf("%w: leaf index out of the tree", ErrInvalidProof)
+	}
+
+	entryHash, err := hex.DecodeString(proof.Entry.Hash)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	// RFC 9162 section 2.1.3.2 verification of an inclusion proof
+	fn, sn := proof.LeafIndex, proof.TreeSize-1
+	r := leafHash(entryHash)
+	for _, encoded := range proof.AuditPath {
+		p, err := hex.DecodeString(encoded)
+		if err != nil || sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	root, err := hex.DecodeString(proof.RootHash)
+	if err != nil || sn != 0 || !bytes.Equal(r, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
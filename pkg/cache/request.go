@@ -1,19 +1,33 @@
 package cache
 
 import (
+	"sync"
+	"time"
+
 	"credit-line/internal/model"
 )
 
 // cache the store cache struct
 type cache struct {
+	mu                  sync.RWMutex
 	CurrentCreditStatus string
 	RequestFailed       map[string]uint
+	Allowances          map[string]Allowance
+	LastSeen            map[string]time.Time
+}
+
+// Allowance struct with the extra decline retries granted to an ip until the expiration
+type Allowance struct {
+	Retries   uint
+	ExpiresAt time.Time
 }
 
 // cacheRequest variable with the cache
 var cacheRequest *cache = &cache{
 	CurrentCreditStatus: "",
 	RequestFailed:       make(map[string]uint),
+	Allowances:          make(map[string]Allowance),
+	LastSeen:            make(map[string]time.Time),
 }
 
 // RetrieveRequestCache retrieves the current cache state
@@ -21,13 +35,102 @@ func RetrieveRequestCache() *cache {
 	return cacheRequest
 }
 
-// UpdateRequestCache set a new values in the cache store
-func UpdateRequestCache(cs model.CreditStatus, ip string) {
+// UpdateRequestCache set a new values in the cache store for each ip or applicant key of the request
+func UpdateRequestCache(cs model.CreditStatus, ips ...string) {
+	cacheRequest.mu.Lock()
+	defer cacheRequest.mu.Unlock()
+
 	cacheRequest.CurrentCreditStatus = string(cs)
+	for _, ip := range ips {
+		cacheRequest.LastSeen[ip] = time.Now()
+		if model.Approved == cs {
+			cacheRequest.RequestFailed[ip] = 0
+			continue
+		}
+		cacheRequest.RequestFailed[ip] = cacheRequest.RequestFailed[ip] + 1
+	}
+}
+
+// ResetClient removes the declined requests and the allowance of an ip
+func ResetClient(ip string) {
+	cacheRequest.mu.Lock()
+	defer cacheRequest.mu.Unlock()
+	delete(cacheRequest.RequestFailed, ip)
+	delete(cacheRequest.Allowances, ip)
+	delete(cacheRequest.LastSeen, ip)
+}
+
+// ExpireIdle removes the declined requests and the allowances of the ips without requests or allowances since
+// before, the ips with an allowance that did not expire are kept; it retrieves the number of ips removed, or
+// that would be removed in a dry run
+func ExpireIdle(before time.Time, dryRun bool) int {
+	cacheRequest.mu.Lock()
+	defer cacheRequest.mu.Unlock()
+	now, expired := time.Now(), 0
+	for ip, lastSeen := range cacheRequest.LastSeen {
+		if !lastSeen.Before(before) {
+			continue
+		}
+		if allowance, ok := cacheRequest.Allowances[ip]; ok && now.Before(allowance.ExpiresAt) {
+			continue
+		}
+		expired++
+		if !dryRun {
+			delete(cacheRequest.RequestFailed, ip)
+			delete(cacheRequest.Allowances, ip)
+			delete(cacheRequest.LastSeen, ip)
+		}
+	}
+	return expired
+}
+
+// GrantAllowance grants extra decline retries to an ip until the expiration
+func GrantAllowance(ip string, retries uint, expiresAt time.Time) {
+	cacheRequest.mu.Lock()
+	defer cacheRequest.mu.Unlock()
+	cacheRequest.Allowances[ip] = Allowance{Retries: retries, ExpiresAt: expiresAt}
+	cacheRequest.LastSeen[ip] = time.Now()
+}
+
+// CreditStatus retrieves the credit status of the last request
+func (c *cache) CreditStatus() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.CurrentCreditStatus
+}
+
+// Failures retrieves the declined requests of an ip
+func (c *cache) Failures(ip string) uint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.RequestFailed[ip]
+}
+
+// Size retrieves the number of ips stored in the cache
+func (c *cache) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.RequestFailed)
+}
+
+// Allowance retrieves the current allowance of an ip, false when the ip has no allowance or it expired
+func (c *cache) Allowance(ip string) (Allowance, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	allowance, ok := c.Allowances[ip]
+	if !ok || time.Now().After(allowance.ExpiresAt) {
+		return Allowance{}, false
+	}
+	return allowance, true
+}
 
-	if model.Approved == cs {
-		cacheRequest.RequestFailed[ip] = 0
-		return
+// Clients retrieves the declined requests of all the ips stored in the cache
+func (c *cache) Clients() map[string]uint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	clients := make(map[string]uint, len(c.RequestFailed))
+	for ip, failures := range c.RequestFailed {
+		clients[ip] = failures
 	}
-	cacheRequest.RequestFailed[ip] = cacheRequest.RequestFailed[ip] + 1
+	return clients
 }
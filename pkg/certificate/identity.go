@@ -0,0 +1,65 @@
+package certificate
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+)
+
+// identityKey context key of the client certificate identity
+type identityKey struct{}
+
+// Identity struct with the identity of a verified client certificate
+type Identity struct {
+	Subject        string
+	Organization   []string
+	DNSNames       []string
+	URIs           []string
+	EmailAddresses []string
+	SerialNumber   string
+	Fingerprint    string
+}
+
+// Key retrieves the key that identifies the client in the rate limits, the certificates
+// of the same subject share the key so the limits survive the certificate rotation
+func (i *Identity) Key() string {
+	if i.Subject != "" {
+		return "cn:" + i.Subject
+	}
+	return "sha256:" + i.Fingerprint
+}
+
+// WithIdentity retrieves a copy of the context with the client certificate identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext retrieves the client certificate identity of the context,
+// nil when the client did not present a verified certificate
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// retrieveIdentity retrieves the identity of the verified client certificate of a connection
+func retrieveIdentity(state *tls.ConnectionState) *Identity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	leaf := state.VerifiedChains[0][0]
+	fingerprint := sha256.Sum256(leaf.Raw)
+
+	identity := &Identity{
+		Subject:        leaf.Subject.CommonName,
+		Organization:   leaf.Subject.Organization,
+		DNSNames:       leaf.DNSNames,
+		EmailAddresses: leaf.EmailAddresses,
+		SerialNumber:   leaf.SerialNumber.String(),
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+	}
+	for _, uri := range leaf.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
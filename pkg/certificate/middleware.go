@@ -0,0 +1,36 @@
+package certificate
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// HTTPClientCertificate middleware that adds the identity of the verified client certificate to the request context
+func HTTPClientCertificate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if identity := retrieveIdentity(c.Request().TLS); identity != nil {
+				c.SetRequest(c.Request().WithContext(WithIdentity(c.Request().Context(), identity)))
+			}
+			return next(c)
+		}
+	}
+}
+
+// UnaryClientCertificate interceptor that adds the identity of the verified client certificate to the request context
+func UnaryClientCertificate() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if p, ok := peer.FromContext(ctx); ok {
+			if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+				if identity := retrieveIdentity(&tlsInfo.State); identity != nil {
+					ctx = WithIdentity(ctx, identity)
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
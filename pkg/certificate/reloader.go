@@ -0,0 +1,139 @@
+package certificate
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"credit-line/pkg/env"
+)
+
+// tlsVersions the supported values of the minimum TLS version
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuthTypes the supported values of the client certificate verification modes
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// Reloader struct that holds the server certificate and the client CAs and reloads them when the files change
+type Reloader struct {
+	cfg         *env.Server
+	certificate atomic.Pointer[tls.Certificate]
+	clientCAs   atomic.Pointer[x509.CertPool]
+}
+
+// NewReloader creates a new pointer of Reloader struct with the certificate and the client CAs loaded
+func NewReloader(cfg *env.Server) (*Reloader, error) {
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the certificate and the client CAs files, the current ones are kept when the files are invalid
+func (r *Reloader) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.cfg.TLSCertFile, r.cfg.TLSKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the server certificate, %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.TLSClientCAFile != "" {
+		content, err := os.ReadFile(r.cfg.TLSClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the client CAs, %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(content) {
+			return fmt.Errorf("failed to load the client CAs, no certificates found in %s", r.cfg.TLSClientCAFile)
+		}
+	}
+
+	r.certificate.Store(&certificate)
+	r.clientCAs.Store(clientCAs)
+	return nil
+}
+
+// TLSConfig builds the TLS config of the servers, each handshake uses the last certificate and client CAs loaded
+func (r *Reloader) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tlsVersions[r.cfg.TLSMinVersion],
+		ClientAuth: clientAuthTypes[r.cfg.TLSClientAuth],
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	return &tls.Config{
+		MinVersion: base.MinVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := base.Clone()
+			cfg.Certificates = []tls.Certificate{*r.certificate.Load()}
+			cfg.ClientCAs = r.clientCAs.Load()
+			return cfg, nil
+		},
+	}
+}
+
+// Watch reloads the certificate and the client CAs when the files are modified or when the process receives
+// a SIGHUP, it blocks until the context is done
+func (r *Reloader) Watch(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	// the files are not polled when the interval is zero, only the SIGHUP reloads the certificates
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	modTimes := r.retrieveModTimes()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-tick:
+			current := r.retrieveModTimes()
+			if current == modTimes {
+				continue
+			}
+			modTimes = current
+		}
+
+		if err := r.Reload(); err != nil {
+			l.Error("certificates reload rejected", "error", err)
+			continue
+		}
+		l.Info("certificates reloaded", "cert_file", r.cfg.TLSCertFile, "client_ca_file", r.cfg.TLSClientCAFile)
+	}
+}
+
+// retrieveModTimes retrieves the modification times of the certificate, key and client CAs files
+func (r *Reloader) retrieveModTimes() [3]time.Time {
+	var modTimes [3]time.Time
+	for i, file := range []string{r.cfg.TLSCertFile, r.cfg.TLSKeyFile, r.cfg.TLSClientCAFile} {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
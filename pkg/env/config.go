@@ -0,0 +1,345 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	pv "github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFileVariable variable with the path of the config file
+	configFileVariable = "CONFIG_FILE"
+	// redactedValue value printed instead of a secret
+	redactedValue = "******"
+)
+
+// ConfigErrors list of the invalid values of the environment
+type ConfigErrors []string
+
+// Error implement the error interface
+func (ce ConfigErrors) Error() string {
+	return "invalid config:\n  - " + strings.Join(ce, "\n  - ")
+}
+
+// field struct that describes a variable of the environment
+type field struct {
+	// name the name of the environment variable
+	name string
+	// key the key in the config file and the name of the flag, "section.key"
+	key string
+	// secret true when the value must be redacted
+	secret bool
+	// value the value of the variable in an environment
+	value reflect.Value
+}
+
+// retrieveFields retrieves the variables of the environment in the declaration order,
+// the values are only retrieved when the section is loaded
+func retrieveFields(conf *Environment) []field {
+	var fields []field
+	ev := reflect.ValueOf(conf).Elem()
+	for i := 0; i < ev.NumField(); i++ {
+		section := ev.Type().Field(i)
+		st := section.Type.Elem()
+		for j := 0; j < st.NumField(); j++ {
+			sf := st.Field(j)
+			f := field{
+				name:   sf.Tag.Get("envconfig"),
+				key:    section.Tag.Get("config") + "." + sf.Tag.Get("config"),
+				secret: sf.Tag.Get("secret") == "true",
+			}
+			if !ev.Field(i).IsNil() {
+				f.value = ev.Field(i).Elem().Field(j)
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// retrieveVariableNames retrieves the environment variable names by the config file keys
+func retrieveVariableNames() map[string]string {
+	names := make(map[string]string)
+	for _, f := range retrieveFields(new(Environment)) {
+		names[f.key] = f.name
+	}
+	return names
+}
+
+// readConfigFile reads a config file and retrieves its values by environment variable name,
+// the format is selected by the extension: .yaml, .yml, .toml or a dotenv file otherwise
+func readConfigFile(file string) (map[string]string, error) {
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s, %w", file, err)
+		}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s, %w", file, err)
+		}
+	case ".toml":
+		if _, err := toml.DecodeFile(file, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s, %w", file, err)
+		}
+	default:
+		values, err := godotenv.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s, %w", file, err)
+		}
+		return values, nil
+	}
+
+	names := retrieveVariableNames()
+	values := make(map[string]string)
+	var errs ConfigErrors
+	for section, content := range document {
+		keys, ok := content.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: must be a section", section))
+			continue
+		}
+		for key, value := range keys {
+			name, ok := names[section+"."+key]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Sprintf("%s.%s: unknown key", section, key))
+			case value == nil:
+			default:
+				scalar, ok := retrieveScalarValue(value)
+				if !ok {
+					errs = append(errs, fmt.Sprintf("%s.%s: must be a scalar value or a list of scalar values", section, key))
+					continue
+				}
+				values[name] = scalar
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, errs
+	}
+	return values, nil
+}
+
+// retrieveScalarValue retrieves the value of a config file key, the lists of scalar values are
+// retrieved as comma-separated values
+func retrieveScalarValue(value interface{}) (string, bool) {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map:
+		return "", false
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", false
+		}
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			switch reflect.ValueOf(item).Kind() {
+			case reflect.Map, reflect.Slice:
+				return "", false
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ","), true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// splitList retrieves the trimmed values of a comma-separated list, the empty values are skipped
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// validateNetworks validates that a field is a comma-separated list of ips or networks in CIDR notation
+func validateNetworks(fl pv.FieldLevel) bool {
+	for _, value := range splitList(fl.Field().String()) {
+		if _, err := netip.ParsePrefix(value); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(value); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// validateReviewers validates that a field is a comma-separated list of name:token pairs, the tokens must have at
+// least 16 characters and be unique
+func validateReviewers(fl pv.FieldLevel) bool {
+	tokens := make(map[string]bool)
+	for _, value := range splitList(fl.Field().String()) {
+		name, token, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" || len(token) < 16 || tokens[token] {
+			return false
+		}
+		tokens[token] = true
+	}
+	return true
+}
+
+// parseFlags parses the command line flags, there is a flag for each key of the config file,
+// it retrieves the values of the flags set by environment variable name
+func parseFlags(args []string) (map[string]string, error) {
+	fs := flag.NewFlagSet("credit-line-api", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	names := make(map[string]string)
+	for _, f := range retrieveFields(new(Environment)) {
+		names[f.key] = f.name
+		fs.String(f.key, "", fmt.Sprintf("overrides the %s variable", f.name))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, ConfigErrors{err.Error()}
+	}
+
+	values := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		values[names[f.Name]] = f.Value.String()
+	})
+	return values, nil
+}
+
+// validateEnvironment validates the values of the environment, it retrieves all the invalid values
+func validateEnvironment(conf *Environment) ConfigErrors {
+	v := pv.New()
+	v.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		return sf.Tag.Get("envconfig")
+	})
+	v.RegisterStructValidation(validateServer, Server{})
+	if err := v.RegisterValidation("cidrs", validateNetworks); err != nil {
+		return ConfigErrors{err.Error()}
+	}
+	if err := v.RegisterValidation("reviewers", validateReviewers); err != nil {
+		return ConfigErrors{err.Error()}
+	}
+
+	var errs ConfigErrors
+	ev := reflect.ValueOf(conf).Elem()
+	for i := 0; i < ev.NumField(); i++ {
+		if ev.Field(i).IsNil() {
+			continue
+		}
+		err := v.Struct(ev.Field(i).Interface())
+		validationErrors, ok := err.(pv.ValidationErrors)
+		if !ok {
+			continue
+		}
+		for _, fe := range validationErrors {
+			section := ev.Field(i).Elem().Type()
+			message := retrieveRuleMessage(fe, section)
+			// the secrets are not echoed in the errors
+			value := fmt.Sprint(fe.Value())
+			if sf, ok := section.FieldByName(fe.StructField()); ok && sf.Tag.Get("secret") == "true" {
+				value = redactedValue
+			}
+			errs = append(errs, fmt.Sprintf("%s: %s, got %q", fe.Field(), message, value))
+		}
+	}
+	return errs
+}
+
+// validateServer validates the rules between the TLS values of the server
+func validateServer(sl pv.StructLevel) {
+	s := sl.Current().Interface().(Server)
+	if s.TLSCertFile == "" && s.TLSClientAuth != "none" {
+		sl.ReportError(s.TLSCertFile, "SERVER_TLS_CERT_FILE", "TLSCertFile", "required_unless", "TLSClientAuth none")
+	}
+	if s.TLSClientCAFile == "" && (s.TLSClientAuth == "verify-if-given" || s.TLSClientAuth == "require-and-verify") {
+		sl.ReportError(s.TLSClientCAFile, "SERVER_TLS_CLIENT_CA_FILE", "TLSClientCAFile", "required_if", "TLSClientAuth "+s.TLSClientAuth)
+	}
+}
+
+// retrieveRuleMessage retrieves a readable message of a failed validation rule,
+// the fields of the rule params are replaced by their environment variable names
+func retrieveRuleMessage(fe pv.FieldError, section reflect.Type) string {
+	param := fe.Param()
+	if name, _, _ := strings.Cut(param, " "); name != "" {
+		if sf, ok := section.FieldByName(name); ok {
+			param = strings.Replace(param, sf.Name, sf.Tag.Get("envconfig"), 1)
+		}
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return fmt.Sprintf("is required when %s", strings.Replace(param, " ", " is ", 1))
+	case "required_unless":
+		return fmt.Sprintf("is required unless %s", strings.Replace(param, " ", " is ", 1))
+	case "required_with":
+		return fmt.Sprintf("is required when %s is set", param)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "gte", "min":
+		return fmt.Sprintf("must be greater than or equal to %s", param)
+	case "lte", "max":
+		return fmt.Sprintf("must be less than or equal to %s", param)
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", param)
+	case "nefield":
+		return fmt.Sprintf("must be different from %s", param)
+	case "gtfield":
+		return fmt.Sprintf("must be greater than %s", param)
+	case "url":
+		return "must be a valid URL"
+	case "cidrs":
+		return "must be a comma-separated list of ips or CIDRs"
+	case "reviewers":
+		return "must be a comma-separated list of name:token pairs with unique tokens of at least 16 characters"
+	default:
+		return fmt.Sprintf("must satisfy the %s rule", fe.Tag())
+	}
+}
+
+// PrintEnvironment writes the environment as a YAML config file, the secrets are redacted
+func PrintEnvironment(conf *Environment, w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := make(map[string]*yaml.Node)
+	for _, f := range retrieveFields(conf) {
+		if !f.value.IsValid() {
+			continue
+		}
+		sectionKey, key, _ := strings.Cut(f.key, ".")
+		section, ok := sections[sectionKey]
+		if !ok {
+			section = &yaml.Node{Kind: yaml.MappingNode}
+			sections[sectionKey] = section
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: sectionKey}, section)
+		}
+
+		value := &yaml.Node{}
+		if err := value.Encode(f.value.Interface()); err != nil {
+			return err
+		}
+		if f.secret {
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: redactedValue}
+		}
+		section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
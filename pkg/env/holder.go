@@ -0,0 +1,171 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Holder struct that holds the current environment and swaps it when the config file changes, the values of the
+// command line flags keep their priority over the config file after each reload
+type Holder struct {
+	current     atomic.Pointer[Environment]
+	file        string
+	flagValues  map[string]string
+	mu          sync.Mutex
+	subscribers []func(conf *Environment)
+}
+
+// NewHolder creates a new pointer of Holder struct with the loaded environment and the command line flags it was
+// loaded with
+func NewHolder(file string, conf *Environment, args ...string) *Holder {
+	flagValues, _ := parseFlags(args)
+	h := &Holder{
+		file:       file,
+		flagValues: flagValues,
+	}
+	h.current.Store(conf)
+	return h
+}
+
+// Environment retrieves the current environment
+func (h *Holder) Environment() *Environment {
+	return h.current.Load()
+}
+
+// Ratios retrieves the current ratios
+func (h *Holder) Ratios() *Ratios {
+	return h.current.Load().Ratio
+}
+
+// Middlewares retrieves the current middlewares values
+func (h *Holder) Middlewares() *Middlewares {
+	return h.current.Load().Middlewares
+}
+
+// Subscribe registers a function invoked with the new environment after each reload
+func (h *Holder) Subscribe(fn func(conf *Environment)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers = append(h.subscribers, fn)
+}
+
+// Reload reads the config file again, validates the new values and swaps the current environment,
+// it retrieves the changed variables; the current environment is kept when the new values are invalid
+func (h *Holder) Reload() ([]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	values, err := readConfigFile(h.file)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := processEnvironment(h.flagValues, values)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffEnvironment(h.current.Load(), conf)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	h.current.Store(conf)
+	for _, fn := range h.subscribers {
+		fn(conf)
+	}
+	return changes, nil
+}
+
+// Check reads the config file and validates its values without swapping the current environment, it does not fail
+// when the environment is not loaded from a config file
+func (h *Holder) Check() error {
+	if h.file == "" {
+		return nil
+	}
+	values, err := readConfigFile(h.file)
+	if err != nil {
+		return err
+	}
+	_, err = processEnvironment(h.flagValues, values)
+	return err
+}
+
+// Watch reloads the environment when the config file is modified or when the process receives a SIGHUP,
+// it blocks until the context is done
+func (h *Holder) Watch(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	// the file is not polled when the interval is zero, only the SIGHUP reloads the environment
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	modTime := h.retrieveModTime()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			l.Info("reloading config", "reason", "SIGHUP", "file", h.file)
+		case <-tick:
+			current := h.retrieveModTime()
+			if current.Equal(modTime) {
+				continue
+			}
+			modTime = current
+			l.Info("reloading config", "reason", "file modified", "file", h.file)
+		}
+
+		changes, err := h.Reload()
+		switch {
+		case err != nil:
+			l.Error("config reload rejected", "error", err)
+		case len(changes) == 0:
+			l.Info("config reloaded without changes")
+		default:
+			l.Info("config reloaded", "changes", changes)
+		}
+	}
+}
+
+// retrieveModTime retrieves the modification time of the config file
+func (h *Holder) retrieveModTime() time.Time {
+	info, err := os.Stat(h.file)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// diffEnvironment retrieves the variables with different values between two environments,
+// the values of the secrets are not retrieved
+func diffEnvironment(previous, current *Environment) []string {
+	var changes []string
+	previousFields := retrieveFields(previous)
+	for i, f := range retrieveFields(current) {
+		if !f.value.IsValid() || !previousFields[i].value.IsValid() {
+			continue
+		}
+		before := previousFields[i].value.Interface()
+		after := f.value.Interface()
+		switch {
+		case before == after:
+		case f.secret:
+			changes = append(changes, fmt.Sprintf("%s: changed", f.name))
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", f.name, before, after))
+		}
+	}
+	return changes
+}
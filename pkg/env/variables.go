@@ -1,56 +1,400 @@
 package env
 
 import (
+	"fmt"
 	"log"
-
-	"github.com/joho/godotenv"
-	"github.com/kelseyhightower/envconfig"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 // Middlewares struct with middlewares values
 type Middlewares struct {
-	ApprovedRateLimitTime    uint   `envconfig:"APPROVED_RATE_LIMIT_TIME" default:"120"`
-	ApprovedRateLimitRequest int64  `envconfig:"APPROVED_RATE_LIMIT_REQUEST" default:"2"`
-	DeclineRateLimitTime     uint   `envconfig:"DECLINE_RATE_LIMIT_TIME" default:"30"`
-	DeclineRateLimitRequest  int64  `envconfig:"DECLINE_RATE_LIMIT_REQUEST" default:"1"`
-	DeclineRetriesAllowed    uint   `envconfig:"DECLINE_RETRIES_ALLOWED" default:"3"`
-	DeclineRetriesMessage    string `envconfig:"DECLINE_RETRIES_MESSAGE" default:"A sales agent will contact you"`
+	ApprovedRateLimitTime    uint   `envconfig:"APPROVED_RATE_LIMIT_TIME" default:"120" config:"approvedRateLimitTime" validate:"min=1"`
+	ApprovedRateLimitRequest int64  `envconfig:"APPROVED_RATE_LIMIT_REQUEST" default:"2" config:"approvedRateLimitRequest" validate:"min=1"`
+	DeclineRateLimitTime     uint   `envconfig:"DECLINE_RATE_LIMIT_TIME" default:"30" config:"declineRateLimitTime" validate:"min=1"`
+	DeclineRateLimitRequest  int64  `envconfig:"DECLINE_RATE_LIMIT_REQUEST" default:"1" config:"declineRateLimitRequest" validate:"min=1"`
+	DeclineRetriesAllowed    uint   `envconfig:"DECLINE_RETRIES_ALLOWED" default:"3" config:"declineRetriesAllowed" validate:"min=1"`
+	DeclineRetriesMessage    string `envconfig:"DECLINE_RETRIES_MESSAGE" default:"A sales agent will contact you" config:"declineRetriesMessage" validate:"required"`
 }
 
 // Server struct with server values
 type Server struct {
-	Port            uint16 `envconfig:"SERVER_PORT" default:"3000"`
-	ShutdownTimeOut uint16 `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"10"`
+	Port              uint16 `envconfig:"SERVER_PORT" default:"3000" config:"port" validate:"min=1"`
+	ShutdownTimeOut   uint16 `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"10" config:"shutdownTimeout" validate:"min=1"`
+	ShutdownDelay     uint16 `envconfig:"SERVER_SHUTDOWN_DELAY" default:"0" config:"shutdownDelay"`
+	ReadHeaderTimeout uint16 `envconfig:"SERVER_READ_HEADER_TIMEOUT" default:"5" config:"readHeaderTimeout" validate:"min=1"`
+	ReadTimeout       uint16 `envconfig:"SERVER_READ_TIMEOUT" default:"10" config:"readTimeout" validate:"min=1"`
+	WriteTimeout      uint16 `envconfig:"SERVER_WRITE_TIMEOUT" default:"15" config:"writeTimeout" validate:"min=1,gtfield=HandlerTimeout"`
+	IdleTimeout       uint16 `envconfig:"SERVER_IDLE_TIMEOUT" default:"60" config:"idleTimeout" validate:"min=1"`
+	HandlerTimeout    uint16 `envconfig:"SERVER_HANDLER_TIMEOUT" default:"5" config:"handlerTimeout" validate:"min=1"`
+	MaxHeaderBytes    int    `envconfig:"SERVER_MAX_HEADER_BYTES" default:"16384" config:"maxHeaderBytes" validate:"min=1024"`
+	BodyLimit         int64  `envconfig:"SERVER_BODY_LIMIT" default:"65536" config:"bodyLimit" validate:"min=1"`
+	GRPCEnabled       bool   `envconfig:"GRPC_SERVER_ENABLED" default:"false" config:"grpcEnabled"`
+	GRPCPort          uint16 `envconfig:"GRPC_SERVER_PORT" default:"50051" config:"grpcPort" validate:"min=1,nefield=Port"`
+	TLSCertFile       string `envconfig:"SERVER_TLS_CERT_FILE" config:"tlsCertFile" validate:"required_with=TLSKeyFile"`
+	TLSKeyFile        string `envconfig:"SERVER_TLS_KEY_FILE" config:"tlsKeyFile" validate:"required_with=TLSCertFile"`
+	TLSClientCAFile   string `envconfig:"SERVER_TLS_CLIENT_CA_FILE" config:"tlsClientCaFile"`
+	TLSMinVersion     string `envconfig:"SERVER_TLS_MIN_VERSION" default:"1.2" config:"tlsMinVersion" validate:"oneof=1.0 1.1 1.2 1.3"`
+	TLSClientAuth     string `envconfig:"SERVER_TLS_CLIENT_AUTH" default:"none" config:"tlsClientAuth" validate:"oneof=none request require verify-if-given require-and-verify"`
+}
+
+// TLSEnabled retrieves true when the servers must serve TLS
+func (s *Server) TLSEnabled() bool {
+	return s.TLSCertFile != ""
 }
 
 // Ratios struct with ratios values
 type Ratios struct {
-	CashBalance    float64 `envconfig:"CASH_BALANCE_RATIO" default:"3"`
-	MonthlyRevenue float64 `envconfig:"MONTHLY_REVENUE_RATIO" default:"5"`
+	CashBalance    float64 `envconfig:"CASH_BALANCE_RATIO" default:"3" config:"cashBalance" validate:"gt=0"`
+	MonthlyRevenue float64 `envconfig:"MONTHLY_REVENUE_RATIO" default:"5" config:"monthlyRevenue" validate:"gt=0"`
+}
+
+// Tracing struct with tracing values
+type Tracing struct {
+	Exporter    string  `envconfig:"TRACING_EXPORTER" default:"none" config:"exporter" validate:"oneof=none stdout otlp-file"`
+	FilePath    string  `envconfig:"TRACING_FILE_PATH" default:"traces.jsonl" config:"filePath" validate:"required_if=Exporter otlp-file"`
+	ServiceName string  `envconfig:"TRACING_SERVICE_NAME" default:"credit-line-api" config:"serviceName" validate:"required"`
+	SampleRatio float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1" config:"sampleRatio" validate:"gte=0,lte=1"`
+}
+
+// Logger struct with logger values
+type Logger struct {
+	Level     string `envconfig:"LOG_LEVEL" default:"info" config:"level" validate:"oneof=debug info warn error DEBUG INFO WARN ERROR"`
+	Format    string `envconfig:"LOG_FORMAT" default:"json" config:"format" validate:"oneof=json text"`
+	RedactPII bool   `envconfig:"LOG_REDACT_PII" default:"true" config:"redactPii"`
+}
+
+// Config struct with the config file values
+type Config struct {
+	File          string `envconfig:"CONFIG_FILE" default:".env" config:"file"`
+	WatchInterval uint16 `envconfig:"CONFIG_WATCH_INTERVAL" default:"5" config:"watchInterval"`
+}
+
+// Admin struct with the admin API values
+type Admin struct {
+	Token string `envconfig:"ADMIN_TOKEN" config:"token" secret:"true" validate:"omitempty,min=16"`
+}
+
+// Access struct with the access list and trusted proxies values, the lists are comma-separated ips or CIDRs
+type Access struct {
+	AllowList      string `envconfig:"ACCESS_ALLOW_LIST" config:"allowList" validate:"cidrs"`
+	BlockList      string `envconfig:"ACCESS_BLOCK_LIST" config:"blockList" validate:"cidrs"`
+	ListFile       string `envconfig:"ACCESS_LIST_FILE" config:"listFile"`
+	TrustedProxies string `envconfig:"ACCESS_TRUSTED_PROXIES" config:"trustedProxies" validate:"cidrs"`
+}
+
+// AllowNetworks retrieves the ips and networks of the allow list
+func (a *Access) AllowNetworks() []string {
+	return splitList(a.AllowList)
+}
+
+// BlockNetworks retrieves the ips and networks of the block list
+func (a *Access) BlockNetworks() []string {
+	return splitList(a.BlockList)
+}
+
+// TrustedProxyNetworks retrieves the ips and networks of the trusted proxies
+func (a *Access) TrustedProxyNetworks() []string {
+	return splitList(a.TrustedProxies)
+}
+
+// Leads struct with the lead capture values
+type Leads struct {
+	Sink             string `envconfig:"LEADS_SINK" default:"none" config:"sink" validate:"oneof=none webhook file queue"`
+	WebhookURL       string `envconfig:"LEADS_WEBHOOK_URL" config:"webhookUrl" validate:"required_if=Sink webhook,omitempty,url"`
+	FilePath         string `envconfig:"LEADS_FILE_PATH" default:"leads.jsonl" config:"filePath" validate:"required_if=Sink file"`
+	QueueDir         string `envconfig:"LEADS_QUEUE_DIR" default:"leads" config:"queueDir" validate:"required_if=Sink queue"`
+	History          uint   `envconfig:"LEADS_HISTORY" default:"5" config:"history" validate:"min=1,max=50"`
+	DeliveryAttempts uint   `envconfig:"LEADS_DELIVERY_ATTEMPTS" default:"3" config:"deliveryAttempts" validate:"min=1,max=10"`
+}
+
+// Webhooks struct with the delivery values of the decision webhooks
+type Webhooks struct {
+	DeliveryAttempts uint `envconfig:"WEBHOOKS_DELIVERY_ATTEMPTS" default:"5" config:"deliveryAttempts" validate:"min=1,max=10"`
+	Timeout          uint `envconfig:"WEBHOOKS_TIMEOUT" default:"10" config:"timeout" validate:"min=1,max=60"`
+	DeliveryLog      uint `envconfig:"WEBHOOKS_DELIVERY_LOG" default:"1000" config:"deliveryLog" validate:"min=10,max=100000"`
+	Workers          uint `envconfig:"WEBHOOKS_WORKERS" default:"4" config:"workers" validate:"min=1,max=100"`
+}
+
+// Outbox struct with the decision store and the relay values of the decision events
+type Outbox struct {
+	Store          string `envconfig:"OUTBOX_STORE" default:"memory" config:"store" validate:"oneof=memory file"`
+	FilePath       string `envconfig:"OUTBOX_FILE_PATH" default:"decisions.jsonl" config:"filePath" validate:"required_if=Store file"`
+	Broker         string `envconfig:"OUTBOX_BROKER" default:"none" config:"broker" validate:"oneof=none memory file nats"`
+	BrokerFilePath string `envconfig:"OUTBOX_BROKER_FILE_PATH" default:"events.jsonl" config:"brokerFilePath" validate:"required_if=Broker file"`
+	NatsURL        string `envconfig:"OUTBOX_NATS_URL" default:"nats://127.0.0.1:4222" config:"natsUrl" secret:"true" validate:"required_if=Broker nats,omitempty,url"`
+	Subject        string `envconfig:"OUTBOX_SUBJECT" default:"credit-line.decisions" config:"subject" validate:"required"`
+	RelayInterval  uint   `envconfig:"OUTBOX_RELAY_INTERVAL" default:"1" config:"relayInterval" validate:"min=1"`
+	RelayBatch     uint   `envconfig:"OUTBOX_RELAY_BATCH" default:"100" config:"relayBatch" validate:"min=1,max=10000"`
+}
+
+// Audit struct with the audit log values
+type Audit struct {
+	FilePath string `envconfig:"AUDIT_FILE_PATH" default:"audit.jsonl" config:"filePath" validate:"required"`
+}
+
+// Pii struct with the encryption values of the stored applicant data
+type Pii struct {
+	KeyringFile string `envconfig:"PII_KEYRING_FILE" config:"keyringFile"`
+}
+
+// Retention struct with the retention values of the applicant data, the decisions, audit entries and leads are
+// kept while Days is 0 and the idle cache entries are kept while CacheIdle is 0
+type Retention struct {
+	Days      uint   `envconfig:"RETENTION_DAYS" default:"0" config:"days"`
+	Mode      string `envconfig:"RETENTION_MODE" default:"purge" config:"mode" validate:"oneof=purge anonymize"`
+	Interval  uint   `envconfig:"RETENTION_INTERVAL" default:"60" config:"interval" validate:"min=1"`
+	CacheIdle uint   `envconfig:"RETENTION_CACHE_IDLE" default:"1440" config:"cacheIdle"`
+}
+
+// Applicants struct with the identification values of the applicants, the identifiers are hashed with the hash
+// key, or ignored without it, and the applications of the same applicant from other ips in the duplicate window
+// are repeats
+type Applicants struct {
+	HashKey         string `envconfig:"APPLICANTS_HASH_KEY" config:"hashKey" secret:"true" validate:"omitempty,min=16"`
+	DuplicateWindow uint   `envconfig:"APPLICANTS_DUPLICATE_WINDOW" default:"1440" config:"duplicateWindow" validate:"min=1"`
+}
+
+// Risk struct with the values of the risk assessment of the requests before the determination, a request is
+// flagged, delayed or routed to manual review when its score reaches the score of the action, 0 disables an action
+type Risk struct {
+	VelocityWindow      uint    `envconfig:"RISK_VELOCITY_WINDOW" default:"10" config:"velocityWindow" validate:"min=1"`
+	VelocityLimit       uint    `envconfig:"RISK_VELOCITY_LIMIT" default:"5" config:"velocityLimit"`
+	ProbingSteps        uint    `envconfig:"RISK_PROBING_STEPS" default:"3" config:"probingSteps" validate:"omitempty,min=2"`
+	RevenueBalanceRatio float64 `envconfig:"RISK_REVENUE_BALANCE_RATIO" default:"100" config:"revenueBalanceRatio" validate:"gte=0"`
+	FlagScore           uint    `envconfig:"RISK_FLAG_SCORE" default:"30" config:"flagScore"`
+	DelayScore          uint    `envconfig:"RISK_DELAY_SCORE" default:"50" config:"delayScore"`
+	ReviewScore         uint    `envconfig:"RISK_REVIEW_SCORE" default:"80" config:"reviewScore"`
+	Delay               uint    `envconfig:"RISK_DELAY" default:"2" config:"delay"`
+}
+
+// Reviews struct with the manual review values, the declined requests whose calculated credit line is within the
+// band percentage below the requested credit line are reviewed and a band of 0 disables it; the reviewers are
+// comma-separated name:token pairs of the back-office API
+type Reviews struct {
+	Band        float64 `envconfig:"REVIEWS_BAND" default:"0" config:"band" validate:"gte=0,lt=100"`
+	SLA         uint    `envconfig:"REVIEWS_SLA" default:"240" config:"sla" validate:"min=1"`
+	SLAInterval uint    `envconfig:"REVIEWS_SLA_INTERVAL" default:"60" config:"slaInterval" validate:"min=1"`
+	Reviewers   string  `envconfig:"REVIEWS_REVIEWERS" config:"reviewers" secret:"true" validate:"reviewers"`
+}
+
+// ReviewerTokens retrieves the names of the reviewers by token
+func (r *Reviews) ReviewerTokens() map[string]string {
+	tokens := make(map[string]string)
+	for _, reviewer := range splitList(r.Reviewers) {
+		name, token, _ := strings.Cut(reviewer, ":")
+		tokens[token] = name
+	}
+	return tokens
+}
+
+// Accounts struct with the credit line account values, an approved credit line can be accepted until the
+// acceptance window days after its decision and a window of 0 does not expire it; the ledger is checked every
+// check interval seconds, and the accounts are stored in the file path when the decisions are stored in a file
+type Accounts struct {
+	AcceptanceWindow uint   `envconfig:"ACCOUNTS_ACCEPTANCE_WINDOW" default:"30" config:"acceptanceWindow"`
+	CheckInterval    uint   `envconfig:"ACCOUNTS_CHECK_INTERVAL" default:"300" config:"checkInterval" validate:"min=1"`
+	FilePath         string `envconfig:"ACCOUNTS_FILE_PATH" default:"accounts.jsonl" config:"filePath" validate:"required"`
 }
 
 // Environment struct with the environment values
 type Environment struct {
-	Server      *Server
-	Ratio       *Ratios
-	Middlewares *Middlewares
+	Server      *Server      `config:"server"`
+	Ratio       *Ratios      `config:"ratio"`
+	Middlewares *Middlewares `config:"middlewares"`
+	Tracing     *Tracing     `config:"tracing"`
+	Logger      *Logger      `config:"logger"`
+	Config      *Config      `config:"config"`
+	Admin       *Admin       `config:"admin"`
+	Access      *Access      `config:"access"`
+	Leads       *Leads       `config:"leads"`
+	Webhooks    *Webhooks    `config:"webhooks"`
+	Outbox      *Outbox      `config:"outbox"`
+	Audit       *Audit       `config:"audit"`
+	Pii         *Pii         `config:"pii"`
+	Retention   *Retention   `config:"retention"`
+	Applicants  *Applicants  `config:"applicants"`
+	Risk        *Risk        `config:"risk"`
+	Reviews     *Reviews     `config:"reviews"`
+	Accounts    *Accounts    `config:"accounts"`
 }
 
-// LoadEnvironment loads a .env file and set the environment variables
-func LoadEnvironment() *Environment {
-	err := godotenv.Load()
+// defaultConfigFile the config file loaded when CONFIG_FILE is not set
+const defaultConfigFile = ".env"
+
+// LoadEnvironment loads the layered environment: the default values, the config file, the environment variables
+// and the command line flags, each layer overrides the previous one; the layers are only read, the process
+// environment is not changed. It retrieves ConfigErrors with all the invalid values
+func LoadEnvironment(args []string) (*Environment, error) {
+	flagValues, err := parseFlags(args)
 	if err != nil {
+		return nil, err
+	}
+
+	file, explicit := retrieveConfigFile(flagValues)
+	fileValues, err := readConfigFile(file)
+	switch {
+	case err != nil && explicit:
+		return nil, err
+	case err != nil:
 		log.Println("config file not found")
 	}
+	return processEnvironment(flagValues, fileValues)
+}
 
-	conf := new(Environment)
-	envconfig.Process("", conf)
-	return conf
+// RetrieveConfigFile retrieves the path of the config file of the command line flags, or of the CONFIG_FILE
+// variable when the flags do not set it
+func RetrieveConfigFile(args ...string) string {
+	flagValues, _ := parseFlags(args)
+	file, _ := retrieveConfigFile(flagValues)
+	return file
 }
 
-// RetrieveEnvVariables retrieve the env variables
+// RetrieveEnvVariables retrieve the env variables over the default values
 func RetrieveEnvVariables() *Environment {
-	conf := new(Environment)
-	envconfig.Process("", conf)
+	conf := newEnvironment()
+	processSections(conf, nil, nil)
 	return conf
 }
+
+// CheckEnvironment checks that the environment was loaded with valid values, it retrieves ConfigErrors
+// with all the invalid values
+func CheckEnvironment(conf *Environment) error {
+	if conf == nil || conf.Server == nil || conf.Ratio == nil || conf.Middlewares == nil {
+		return ConfigErrors{"environment not loaded"}
+	}
+	if errs := validateEnvironment(conf); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// retrieveConfigFile retrieves the path of the config file and true when it was set by a flag or by the
+// CONFIG_FILE variable
+func retrieveConfigFile(flagValues map[string]string) (string, bool) {
+	if file, ok := flagValues[configFileVariable]; ok {
+		return file, true
+	}
+	if file, ok := os.LookupEnv(configFileVariable); ok {
+		if file == "" {
+			file = defaultConfigFile
+		}
+		return file, true
+	}
+	return defaultConfigFile, false
+}
+
+// newEnvironment creates an environment with all its sections
+func newEnvironment() *Environment {
+	return &Environment{
+		Server:      new(Server),
+		Ratio:       new(Ratios),
+		Middlewares: new(Middlewares),
+		Tracing:     new(Tracing),
+		Logger:      new(Logger),
+		Config:      new(Config),
+		Admin:       new(Admin),
+		Access:      new(Access),
+		Leads:       new(Leads),
+		Webhooks:    new(Webhooks),
+		Outbox:      new(Outbox),
+		Audit:       new(Audit),
+		Pii:         new(Pii),
+		Retention:   new(Retention),
+		Applicants:  new(Applicants),
+		Risk:        new(Risk),
+		Reviews:     new(Reviews),
+		Accounts:    new(Accounts),
+	}
+}
+
+// processEnvironment builds the environment from the values of the flags, the environment variables and the
+// config file, by variable name, and validates it
+func processEnvironment(flagValues, fileValues map[string]string) (*Environment, error) {
+	conf := newEnvironment()
+	errs := processSections(conf, flagValues, fileValues)
+	errs = append(errs, validateEnvironment(conf)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return conf, nil
+}
+
+// processSections sets the variables of each section with the value of the first layer that has it: the flags,
+// the environment variables, the config file and the default value; each section is processed independently to
+// report the errors of all the sections, and a section with an invalid value is removed
+func processSections(conf *Environment, flagValues, fileValues map[string]string) ConfigErrors {
+	var errs ConfigErrors
+	cv := reflect.ValueOf(conf).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		section := cv.Field(i).Elem()
+		sectionErrs := len(errs)
+		for j := 0; j < section.NumField(); j++ {
+			sf := section.Type().Field(j)
+			name := sf.Tag.Get("envconfig")
+			value, ok := lookupLayers(name, flagValues, fileValues)
+			if !ok {
+				if value, ok = sf.Tag.Lookup("default"); !ok {
+					continue
+				}
+			}
+			if err := setValue(section.Field(j), value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: invalid value %q, expected %s", name, value, sf.Type))
+			}
+		}
+		if len(errs) > sectionErrs {
+			cv.Field(i).Set(reflect.Zero(cv.Field(i).Type()))
+		}
+	}
+	return errs
+}
+
+// lookupLayers retrieves the value of a variable of the flags, the environment variables or the config file, in
+// this order, false when no layer has it
+func lookupLayers(name string, flagValues, fileValues map[string]string) (string, bool) {
+	if value, ok := flagValues[name]; ok {
+		return value, true
+	}
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := fileValues[name]
+	return value, ok
+}
+
+// setValue decodes a value into a variable of the environment
+func setValue(fv reflect.Value, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 0, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
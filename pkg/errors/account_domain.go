@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrAccountNotFound is returned when a credit line account does not exist
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrDecisionNotFound is returned when the decision of an accepted credit line does not exist
+	ErrDecisionNotFound = errors.New("decision not found")
+	// ErrDecisionNotApproved is returned when an accepted credit line was not approved
+	ErrDecisionNotApproved = errors.New("decision not approved")
+	// ErrAcceptanceExpired is returned when an approved credit line is accepted after the acceptance window
+	ErrAcceptanceExpired = errors.New("acceptance window expired")
+	// ErrAccountExists is returned when an approved credit line was already accepted
+	ErrAccountExists = errors.New("account already opened for the decision")
+	// ErrAccountClosed is returned when a movement is applied to a closed account
+	ErrAccountClosed = errors.New("account closed")
+	// ErrAccountOutstanding is returned when an account with outstanding balance is closed
+	ErrAccountOutstanding = errors.New("account with outstanding balance")
+	// ErrInsufficientAvailable is returned when a drawdown exceeds the available balance
+	ErrInsufficientAvailable = errors.New("insufficient available balance")
+	// ErrRepaymentExceedsOutstanding is returned when a repayment exceeds the outstanding balance
+	ErrRepaymentExceedsOutstanding = errors.New("repayment exceeds the outstanding balance")
+	// ErrReferenceConflict is returned when the reference of a movement was used by another movement
+	ErrReferenceConflict = errors.New("reference used by another movement")
+	// ErrUnbalancedTransaction is returned when a ledger transaction is not balanced
+	ErrUnbalancedTransaction = errors.New("unbalanced ledger transaction")
+)
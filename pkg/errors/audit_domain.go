@@ -0,0 +1,10 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrAuditEntryNotFound is returned when a decision is not in the audit log
+	ErrAuditEntryNotFound = errors.New("audit entry not found")
+)
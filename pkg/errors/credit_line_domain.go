@@ -7,4 +7,8 @@ import (
 var (
 	// ErrInvalidFoundingType is returned when the foundingType is invalid
 	ErrInvalidFoundingType = errors.New("invalid foundingType")
+	// ErrRateLimitExceeded is returned when a client reached a rate limit
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	// ErrRetriesExhausted is returned when a client exhausted the decline retries allowed
+	ErrRetriesExhausted = errors.New("decline retries exhausted")
 )
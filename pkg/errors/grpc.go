@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+
+	ut "github.com/go-playground/universal-translator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"credit-line/pkg/i18n"
+)
+
+// grpcErrorDomain domain of the error details of the gRPC errors
+const grpcErrorDomain = "credit-line"
+
+// MapGRPCError transform an error into a gRPC status error with the messages in the translator locale
+func MapGRPCError(err error, errType ErrorType, trans ut.Translator) error {
+	response, statusCode := MapError(err, errType, trans)
+	return newGRPCStatusError(retrieveGRPCCode(statusCode), response, trans)
+}
+
+// MapGRPCPolicyError transform an error of the rate limits or retries policies into a gRPC status error
+func MapGRPCPolicyError(err error, trans ut.Translator) error {
+	switch {
+	case errors.Is(err, ErrRetriesExhausted):
+		return newGRPCStatusError(codes.ResourceExhausted, &ApiResponse{
+			Message: i18n.Translate(trans, i18n.RetriesExhaustedKey),
+			Code:    retriesExhaustedCode,
+		}, trans)
+	case errors.Is(err, ErrRateLimitExceeded):
+		return newGRPCStatusError(codes.ResourceExhausted, &ApiResponse{
+			Message: i18n.Translate(trans, i18n.RateLimitExceededKey),
+			Code:    rateLimitExceededCode,
+		}, trans)
+	case errors.Is(err, ErrIPBlocked):
+		return newGRPCStatusError(codes.PermissionDenied, &ApiResponse{
+			Message: i18n.Translate(trans, i18n.ForbiddenKey),
+			Code:    forbiddenCode,
+		}, trans)
+	default:
+		return newGRPCStatusError(codes.Internal, &ApiResponse{
+			Message: i18n.Translate(trans, i18n.InternalServerErrorKey),
+			Code:    internalServerErrorCode,
+		}, trans)
+	}
+}
+
+// newGRPCStatusError builds a gRPC status error with the error code and the offending fields as details
+func newGRPCStatusError(code codes.Code, response *ApiResponse, trans ut.Translator) error {
+	st := status.New(code, response.Message)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: response.Code, Domain: grpcErrorDomain},
+		&errdetails.LocalizedMessage{Locale: trans.Locale(), Message: response.Message},
+	}
+	if len(response.Errors) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(response.Errors))
+		for _, fe := range response.Errors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	if dst, err := st.WithDetails(details...); err == nil {
+		st = dst
+	}
+	return st.Err()
+}
+
+// retrieveGRPCCode retrieves the gRPC code of one HTTP status code
+func retrieveGRPCCode(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
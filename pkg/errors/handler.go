@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/labstack/echo/v4"
+
+	"credit-line/pkg/i18n"
+	"credit-line/pkg/logger"
+)
+
+const (
+	// MIMEApplicationProblemJSON media type for the RFC 7807 problem details responses
+	MIMEApplicationProblemJSON = "application/problem+json"
+
+	// problemTypeBase base reference for the problem types
+	problemTypeBase = "/problems/"
+)
+
+// codeMessageKeys variable to identify the message key of the error codes
+var codeMessageKeys = map[string]string{
+	unauthorizedCode:        i18n.UnauthorizedKey,
+	forbiddenCode:           i18n.ForbiddenKey,
+	notFoundCode:            i18n.NotFoundKey,
+	methodNotAllowedCode:    i18n.MethodNotAllowedKey,
+	rateLimitExceededCode:   i18n.RateLimitExceededKey,
+	retriesExhaustedCode:    i18n.RetriesExhaustedKey,
+	requestTimeoutCode:      i18n.RequestTimeoutKey,
+	payloadTooLargeCode:     i18n.PayloadTooLargeKey,
+	serviceUnavailableCode:  i18n.ServiceUnavailableKey,
+	internalServerErrorCode: i18n.InternalServerErrorKey,
+}
+
+// ProblemDetails struct for the RFC 7807 error responses in the API
+type ProblemDetails struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// HTTPErrorHandler echo error handler that renders every error of the API as problem details,
+// the legacy ApiResponse shape is rendered when the client only accepts application/json
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	trans := i18n.RetrieveTranslator(c.Request().Header.Get(i18n.HeaderAcceptLanguage))
+	c.Response().Header().Set(i18n.HeaderContentLanguage, trans.Locale())
+
+	statusCode, response := retrieveHTTPErrorResponse(err, trans)
+	ctx := c.Request().Context()
+	if statusCode >= http.StatusInternalServerError {
+		logger.FromContext(ctx).ErrorContext(ctx, "request failed", "uri", c.Request().RequestURI, "error", err)
+	}
+	response.RequestID = logger.RequestID(ctx)
+
+	var rerr error
+	switch {
+	case c.Request().Method == http.MethodHead:
+		rerr = c.NoContent(statusCode)
+	case acceptsLegacyResponse(c.Request().Header.Get(echo.HeaderAccept)):
+		rerr = c.JSON(statusCode, response)
+	default:
+		c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+		rerr = c.JSON(statusCode, NewProblemDetails(statusCode, response, c.Request().URL.Path))
+	}
+	if rerr != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "could not write the error response", "error", rerr)
+	}
+}
+
+// NewProblemDetails creates a new pointer of ProblemDetails from an ApiResponse
+func NewProblemDetails(statusCode int, response *ApiResponse, instance string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:      problemTypeBase + strings.ReplaceAll(strings.ToLower(response.Code), "_", "-"),
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    response.Message,
+		Instance:  instance,
+		Code:      response.Code,
+		RequestID: response.RequestID,
+		Errors:    response.Errors,
+	}
+}
+
+// retrieveHTTPErrorResponse retrieves the status code and the ApiResponse of any error,
+// the messages of the known error codes are retrieved in the translator locale
+func retrieveHTTPErrorResponse(err error, trans ut.Translator) (int, *ApiResponse) {
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		return http.StatusInternalServerError, &ApiResponse{
+			Message: i18n.Translate(trans, i18n.InternalServerErrorKey),
+			Code:    internalServerErrorCode,
+		}
+	}
+
+	if response, ok := he.Message.(*ApiResponse); ok {
+		return he.Code, response
+	}
+
+	code := retrieveStatusErrorCode(he.Code, he.Internal)
+	msg := fmt.Sprint(he.Message)
+	if key, ok := codeMessageKeys[code]; ok {
+		msg = i18n.Translate(trans, key)
+	}
+	return he.Code, &ApiResponse{Message: msg, Code: code}
+}
+
+// retrieveStatusErrorCode retrieves the error code of one HTTP status code
+func retrieveStatusErrorCode(statusCode int, internal error) string {
+	switch {
+	case errors.Is(internal, ErrRetriesExhausted):
+		return retriesExhaustedCode
+	case statusCode == http.StatusBadRequest:
+		return invalidRequestCode
+	case statusCode == http.StatusUnauthorized:
+		return unauthorizedCode
+	case statusCode == http.StatusForbidden:
+		return forbiddenCode
+	case statusCode == http.StatusNotFound:
+		return notFoundCode
+	case statusCode == http.StatusMethodNotAllowed:
+		return methodNotAllowedCode
+	case statusCode == http.StatusRequestTimeout:
+		return requestTimeoutCode
+	case statusCode == http.StatusRequestEntityTooLarge:
+		return payloadTooLargeCode
+	case statusCode == http.StatusTooManyRequests:
+		return rateLimitExceededCode
+	case statusCode == http.StatusServiceUnavailable:
+		return serviceUnavailableCode
+	case statusCode >= http.StatusInternalServerError:
+		return internalServerErrorCode
+	default:
+		return strings.ToUpper(strings.ReplaceAll(http.StatusText(statusCode), " ", "_"))
+	}
+}
+
+// acceptsLegacyResponse reports if the client asked for application/json and not for problem details
+func acceptsLegacyResponse(accept string) bool {
+	return strings.Contains(accept, echo.MIMEApplicationJSON) && !strings.Contains(accept, MIMEApplicationProblemJSON)
+}
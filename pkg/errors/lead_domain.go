@@ -0,0 +1,10 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrLeadNotFound is returned when a lead does not exist
+	ErrLeadNotFound = errors.New("lead not found")
+)
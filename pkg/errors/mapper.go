@@ -1,14 +1,18 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/labstack/echo/v4"
 
+	"credit-line/pkg/i18n"
+	"credit-line/pkg/pii"
 	"credit-line/pkg/validator"
 )
 
@@ -20,10 +24,48 @@ const (
 	// DomainErr identify a domain error type
 	DomainErr ErrorType = "DOMAIN_ERROR"
 
+	// requestTimeoutCode code to represent a request that was not received in time
+	requestTimeoutCode = "REQUEST_TIMEOUT"
+	// payloadTooLargeCode code to represent a request body that exceeds the limit
+	payloadTooLargeCode = "PAYLOAD_TOO_LARGE"
+	// serviceUnavailableCode code to represent a request that could not be processed in time
+	serviceUnavailableCode = "SERVICE_UNAVAILABLE"
 	// internalServerErrorCode code to represent an internal server error
 	internalServerErrorCode = "INTERNAL_SERVER_ERROR"
 	// invalidRequestCode code to represent an invalid request
 	invalidRequestCode = "INVALID_REQUEST"
+	// unauthorizedCode code to represent a request without valid credentials
+	unauthorizedCode = "UNAUTHORIZED"
+	// forbiddenCode code to represent a forbidden request
+	forbiddenCode = "FORBIDDEN"
+	// notFoundCode code to represent a resource not found
+	notFoundCode = "NOT_FOUND"
+	// methodNotAllowedCode code to represent a method not allowed
+	methodNotAllowedCode = "METHOD_NOT_ALLOWED"
+	// rateLimitExceededCode code to represent a request rejected by a rate limit
+	rateLimitExceededCode = "RATE_LIMIT_EXCEEDED"
+	// retriesExhaustedCode code to represent a client that exhausted the decline retries
+	retriesExhaustedCode = "RETRIES_EXHAUSTED"
+	// reviewResolvedCode code to represent a review that was already resolved
+	reviewResolvedCode = "REVIEW_RESOLVED"
+	// reviewNotAssignedCode code to represent a review that is not assigned to the reviewer
+	reviewNotAssignedCode = "REVIEW_NOT_ASSIGNED"
+	// decisionNotApprovedCode code to represent a decision that was not approved
+	decisionNotApprovedCode = "DECISION_NOT_APPROVED"
+	// acceptanceExpiredCode code to represent a credit line accepted after the acceptance window
+	acceptanceExpiredCode = "ACCEPTANCE_EXPIRED"
+	// accountExistsCode code to represent a credit line that was already accepted
+	accountExistsCode = "ACCOUNT_EXISTS"
+	// accountClosedCode code to represent a closed account
+	accountClosedCode = "ACCOUNT_CLOSED"
+	// accountOutstandingCode code to represent an account closed with outstanding balance
+	accountOutstandingCode = "ACCOUNT_OUTSTANDING"
+	// insufficientAvailableCode code to represent a drawdown that exceeds the available balance
+	insufficientAvailableCode = "INSUFFICIENT_AVAILABLE_BALANCE"
+	// repaymentExceedsOutstandingCode code to represent a repayment that exceeds the outstanding balance
+	repaymentExceedsOutstandingCode = "REPAYMENT_EXCEEDS_OUTSTANDING"
+	// referenceConflictCode code to represent a reference used by another movement
+	referenceConflictCode = "REFERENCE_CONFLICT"
 )
 
 // ErrorType type to specify an error type
@@ -31,31 +73,63 @@ type ErrorType string
 
 // ApiResponse struct for error responses in the API
 type ApiResponse struct {
-	Message string `json:"message"`
-	Code    string `json:"code"`
+	Message   string       `json:"message"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
 }
 
-// MapError transform an error into custom error response
-func MapError(err error, errType ErrorType) (*ApiResponse, int) {
+// FieldError struct that represents an offending field in the request
+type FieldError struct {
+	Field   string      `json:"field"`
+	Rule    string      `json:"rule"`
+	Value   interface{} `json:"value,omitempty"`
+	Message string      `json:"message"`
+}
+
+// MapError transform an error into custom error response with the messages in the translator locale
+func MapError(err error, errType ErrorType, trans ut.Translator) (*ApiResponse, int) {
 	var msg, code string
+	var fieldErrors []FieldError
 	var statusCode int
 	switch errType {
 	case UnmarshallErr:
-		msg = retrieveUnmarshalErrorMessage(err)
-		code = invalidRequestCode
+		statusCode, code = retrieveUnmarshalErrorCode(err)
+		if key, ok := codeMessageKeys[code]; ok {
+			msg = i18n.Translate(trans, key)
+			break
+		}
+		msg = retrieveUnmarshalErrorMessage(err, trans)
+		fieldErrors = retrieveUnmarshalFieldErrors(err, trans)
 	case ValidationErr:
-		msg = validator.RetrieveValidationErrorMessage(err)
-		code = invalidRequestCode
+		msg = validator.RetrieveValidationErrorMessage(err, trans)
+		fieldErrors = retrieveValidationFieldErrors(err, trans)
+		statusCode, code = http.StatusBadRequest, invalidRequestCode
 	case DomainErr:
-		msg = err.Error()
+		msg = retrieveDomainErrorMessage(err, trans)
 		statusCode, code = retrieveDomainErrorCode(err)
 	}
 
-	return &ApiResponse{Message: msg, Code: code}, statusCode
+	return &ApiResponse{Message: msg, Code: code, Errors: fieldErrors}, statusCode
+}
+
+// retrieveUnmarshalErrorCode retrieves the error code when the bind method fails, the request body
+// could exceed the body limit or could not be received before the read timeout
+func retrieveUnmarshalErrorCode(err error) (int, string) {
+	var mbe *http.MaxBytesError
+	var ne net.Error
+	switch {
+	case errors.As(err, &mbe):
+		return http.StatusRequestEntityTooLarge, payloadTooLargeCode
+	case errors.As(err, &ne) && ne.Timeout():
+		return http.StatusRequestTimeout, requestTimeoutCode
+	default:
+		return http.StatusBadRequest, invalidRequestCode
+	}
 }
 
 // retrieveUnmarshalErrorInformation retrieves the information when the bind method fails
-func retrieveUnmarshalErrorMessage(err error) string {
+func retrieveUnmarshalErrorMessage(err error, trans ut.Translator) string {
 	var field, expected, got string
 	if err, ok := err.(*echo.HTTPError); ok {
 		ierr := err.Internal
@@ -68,15 +142,138 @@ func retrieveUnmarshalErrorMessage(err error) string {
 	if strings.Contains(expected, "int") || strings.Contains(expected, "float") {
 		expected = "number"
 	}
-	return fmt.Sprint("unmarshal error data type, got: ", got, ", expected: ", expected, " in ", field, " param")
+	return i18n.Translate(trans, i18n.UnmarshalErrorKey, got, expected, field)
+}
+
+// retrieveUnmarshalFieldErrors retrieves the offending field when the bind method fails
+func retrieveUnmarshalFieldErrors(err error, trans ut.Translator) []FieldError {
+	herr, ok := err.(*echo.HTTPError)
+	if !ok {
+		return nil
+	}
+	ute, ok := herr.Internal.(*json.UnmarshalTypeError)
+	if !ok {
+		return nil
+	}
+
+	expected := ute.Type.Name()
+	if strings.Contains(expected, "int") || strings.Contains(expected, "float") {
+		expected = "number"
+	}
+	return []FieldError{{
+		Field:   ute.Field,
+		Rule:    "type",
+		Value:   ute.Value,
+		Message: i18n.Translate(trans, i18n.UnmarshalFieldErrorKey, ute.Field, expected, ute.Value),
+	}}
+}
+
+// retrieveValidationFieldErrors retrieves the offending fields when the validation fails
+func retrieveValidationFieldErrors(err error, trans ut.Translator) []FieldError {
+	violations := validator.RetrieveFieldViolations(err, trans)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fieldErrors := make([]FieldError, 0, len(violations))
+	for _, v := range violations {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   v.Field,
+			Rule:    v.Rule,
+			Value:   redactValue(v.Field, v.Value),
+			Message: v.Message,
+		})
+	}
+	return fieldErrors
+}
+
+// retrieveDomainErrorMessage retrieves the message of one domain error in the translator locale
+func retrieveDomainErrorMessage(err error, trans ut.Translator) string {
+	switch {
+	case errors.Is(err, ErrInvalidFoundingType):
+		return i18n.Translate(trans, i18n.InvalidFoundingTypeKey)
+	case errors.Is(err, ErrInvalidIP):
+		return i18n.Translate(trans, i18n.InvalidIPKey)
+	case errors.Is(err, ErrInvalidCIDR):
+		return i18n.Translate(trans, i18n.InvalidCIDRKey)
+	case errors.Is(err, ErrInvalidWebhookURL):
+		return i18n.Translate(trans, i18n.InvalidWebhookURLKey)
+	case errors.Is(err, ErrAccessEntryNotFound), errors.Is(err, ErrLeadNotFound), errors.Is(err, ErrWebhookNotFound),
+		errors.Is(err, ErrDeliveryNotFound), errors.Is(err, ErrAuditEntryNotFound), errors.Is(err, ErrRetentionReportNotFound),
+		errors.Is(err, ErrReviewNotFound), errors.Is(err, ErrAccountNotFound), errors.Is(err, ErrDecisionNotFound):
+		return i18n.Translate(trans, i18n.NotFoundKey)
+	case errors.Is(err, ErrReviewResolved):
+		return i18n.Translate(trans, i18n.ReviewResolvedKey)
+	case errors.Is(err, ErrReviewNotAssigned):
+		return i18n.Translate(trans, i18n.ReviewNotAssignedKey)
+	case errors.Is(err, ErrDecisionNotApproved):
+		return i18n.Translate(trans, i18n.DecisionNotApprovedKey)
+	case errors.Is(err, ErrAcceptanceExpired):
+		return i18n.Translate(trans, i18n.AcceptanceExpiredKey)
+	case errors.Is(err, ErrAccountExists):
+		return i18n.Translate(trans, i18n.AccountExistsKey)
+	case errors.Is(err, ErrAccountClosed):
+		return i18n.Translate(trans, i18n.AccountClosedKey)
+	case errors.Is(err, ErrAccountOutstanding):
+		return i18n.Translate(trans, i18n.AccountOutstandingKey)
+	case errors.Is(err, ErrInsufficientAvailable):
+		return i18n.Translate(trans, i18n.InsufficientAvailableKey)
+	case errors.Is(err, ErrRepaymentExceedsOutstanding):
+		return i18n.Translate(trans, i18n.RepaymentExceedsOutstandingKey)
+	case errors.Is(err, ErrReferenceConflict):
+		return i18n.Translate(trans, i18n.ReferenceConflictKey)
+	case errors.Is(err, ErrRetriesExhausted):
+		return i18n.Translate(trans, i18n.RetriesExhaustedKey)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return i18n.Translate(trans, i18n.ServiceUnavailableKey)
+	default:
+		return pii.RedactText(err.Error())
+	}
 }
 
 // retrieveDomainErrorCode retrieves the error code of one domain error
 func retrieveDomainErrorCode(err error) (int, string) {
 	switch {
-	case errors.Is(err, ErrInvalidFoundingType):
+	case errors.Is(err, ErrInvalidFoundingType), errors.Is(err, ErrInvalidIP), errors.Is(err, ErrInvalidCIDR),
+		errors.Is(err, ErrInvalidWebhookURL):
 		return http.StatusBadRequest, invalidRequestCode
+	case errors.Is(err, ErrAccessEntryNotFound), errors.Is(err, ErrLeadNotFound), errors.Is(err, ErrWebhookNotFound),
+		errors.Is(err, ErrDeliveryNotFound), errors.Is(err, ErrAuditEntryNotFound), errors.Is(err, ErrRetentionReportNotFound),
+		errors.Is(err, ErrReviewNotFound), errors.Is(err, ErrAccountNotFound), errors.Is(err, ErrDecisionNotFound):
+		return http.StatusNotFound, notFoundCode
+	case errors.Is(err, ErrReviewResolved):
+		return http.StatusConflict, reviewResolvedCode
+	case errors.Is(err, ErrReviewNotAssigned):
+		return http.StatusConflict, reviewNotAssignedCode
+	case errors.Is(err, ErrDecisionNotApproved):
+		return http.StatusConflict, decisionNotApprovedCode
+	case errors.Is(err, ErrAcceptanceExpired):
+		return http.StatusConflict, acceptanceExpiredCode
+	case errors.Is(err, ErrAccountExists):
+		return http.StatusConflict, accountExistsCode
+	case errors.Is(err, ErrAccountClosed):
+		return http.StatusConflict, accountClosedCode
+	case errors.Is(err, ErrAccountOutstanding):
+		return http.StatusConflict, accountOutstandingCode
+	case errors.Is(err, ErrInsufficientAvailable):
+		return http.StatusUnprocessableEntity, insufficientAvailableCode
+	case errors.Is(err, ErrRepaymentExceedsOutstanding):
+		return http.StatusUnprocessableEntity, repaymentExceedsOutstandingCode
+	case errors.Is(err, ErrReferenceConflict):
+		return http.StatusConflict, referenceConflictCode
+	case errors.Is(err, ErrRetriesExhausted):
+		return http.StatusTooManyRequests, retriesExhaustedCode
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, serviceUnavailableCode
 	default:
 		return http.StatusInternalServerError, internalServerErrorCode
 	}
 }
+
+// redactValue retrieves the value of an offending field, the applicant data is not echoed in the error responses
+func redactValue(field string, value interface{}) interface{} {
+	if pii.IsSensitive(field) {
+		return pii.Redacted
+	}
+	return value
+}
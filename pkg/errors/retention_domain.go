@@ -0,0 +1,10 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrRetentionReportNotFound is returned when the retention did not run yet
+	ErrRetentionReportNotFound = errors.New("retention report not found")
+)
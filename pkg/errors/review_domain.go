@@ -0,0 +1,14 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrReviewNotFound is returned when a review does not exist
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrReviewResolved is returned when a review was already approved or declined
+	ErrReviewResolved = errors.New("review already resolved")
+	// ErrReviewNotAssigned is returned when a reviewer resolves a review that is not assigned to the reviewer
+	ErrReviewNotAssigned = errors.New("review not assigned to the reviewer")
+)
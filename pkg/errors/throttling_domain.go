@@ -0,0 +1,16 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrIPBlocked is returned when the ip of a client is in a blocked network
+	ErrIPBlocked = errors.New("ip blocked")
+	// ErrInvalidIP is returned when an ip is invalid
+	ErrInvalidIP = errors.New("invalid ip")
+	// ErrInvalidCIDR is returned when an ip or a network in CIDR notation is invalid
+	ErrInvalidCIDR = errors.New("invalid ip or CIDR")
+	// ErrAccessEntryNotFound is returned when a network is not in the access list
+	ErrAccessEntryNotFound = errors.New("access list entry not found")
+)
@@ -0,0 +1,16 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	// ErrInvalidWebhookURL is returned when the URL of a webhook is not an absolute http or https URL
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+	// ErrWebhookNotFound is returned when a webhook subscription does not exist
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound is returned when a webhook delivery does not exist
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrWebhooksShutDown is returned when an event is published after the webhooks are shut down
+	ErrWebhooksShutDown = errors.New("webhooks shut down")
+)
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// statusOK status of a healthy check
+	statusOK = "ok"
+	// statusFail status of a failed check
+	statusFail = "fail"
+	// statusShuttingDown status of the application when the graceful shutdown started
+	statusShuttingDown = "shutting_down"
+
+	// checkTimeout max time to run all the readiness checks
+	checkTimeout = 2 * time.Second
+)
+
+// Checker contract for the readiness checks of a dependency
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// checkerFunc struct that implement the Checker interface with a function
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Health struct with the readiness state and the checkers of the application
+type Health struct {
+	mu       sync.RWMutex
+	checkers []Checker
+	ready    atomic.Bool
+}
+
+// Response struct that represents the health response
+type Response struct {
+	Status string                   `json:"status"`
+	Checks map[string]CheckResponse `json:"checks,omitempty"`
+}
+
+// CheckResponse struct that represents the result of one check
+type CheckResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NewChecker creates a Checker from a function
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return &checkerFunc{
+		name:  name,
+		check: check,
+	}
+}
+
+// New creates a new pointer of Health struct, the application is not ready until SetReady is invoked
+func New(checkers ...Checker) *Health {
+	return &Health{
+		checkers: checkers,
+	}
+}
+
+// Register adds a checker to the readiness checks
+func (h *Health) Register(checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checkers = append(h.checkers, checker)
+}
+
+// SetReady sets if the application can receive traffic
+func (h *Health) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// Liveness invokes the echo handler that reports if the process is alive
+func (h *Health) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, Response{Status: statusOK})
+}
+
+// Readiness invokes the echo handler that reports if the application can receive traffic,
+// it responds 503 when the shutdown started or when any check fails
+func (h *Health) Readiness(c echo.Context) error {
+	if !h.ready.Load() {
+		return c.JSON(http.StatusServiceUnavailable, Response{Status: statusShuttingDown})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), checkTimeout)
+	defer cancel()
+
+	response := h.check(ctx)
+	if response.Status != statusOK {
+		return c.JSON(http.StatusServiceUnavailable, response)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// check runs all the checkers concurrently
+func (h *Health) check(ctx context.Context) Response {
+	h.mu.RLock()
+	checkers := make([]Checker, len(h.checkers))
+	copy(checkers, h.checkers)
+	h.mu.RUnlock()
+
+	results := make([]CheckResponse, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = CheckResponse{Status: statusOK}
+			if err := checker.Check(ctx); err != nil {
+				results[i] = CheckResponse{Status: statusFail, Error: err.Error()}
+			}
+		}(i, checker)
+	}
+	wg.Wait()
+
+	response := Response{Status: statusOK, Checks: make(map[string]CheckResponse, len(checkers))}
+	for i, checker := range checkers {
+		response.Checks[checker.Name()] = results[i]
+		if results[i].Status != statusOK {
+			response.Status = statusFail
+		}
+	}
+	return response
+}
+
+// Name implement the interface Checker.Name
+func (cf *checkerFunc) Name() string { return cf.name }
+
+// Check implement the interface Checker.Check
+func (cf *checkerFunc) Check(ctx context.Context) error { return cf.check(ctx) }
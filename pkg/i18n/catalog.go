@@ -0,0 +1,128 @@
+package i18n
+
+const (
+	// UnmarshalErrorKey key of the message when the request could not be unmarshalled
+	UnmarshalErrorKey = "unmarshal_error"
+	// UnmarshalFieldErrorKey key of the message of a field with a wrong data type
+	UnmarshalFieldErrorKey = "unmarshal_field_error"
+	// MalformedRequestKey key of the message when the request validation fails
+	MalformedRequestKey = "malformed_request"
+	// InvalidFoundingTypeKey key of the message when the foundingType is invalid
+	InvalidFoundingTypeKey = "invalid_founding_type"
+	// InvalidIPKey key of the message when an ip is invalid
+	InvalidIPKey = "invalid_ip"
+	// InvalidCIDRKey key of the message when an ip or a network in CIDR notation is invalid
+	InvalidCIDRKey = "invalid_cidr"
+	// InvalidWebhookURLKey key of the message when the URL of a webhook is not an http or https URL
+	InvalidWebhookURLKey = "invalid_webhook_url"
+	// ReviewResolvedKey key of the message when a review was already approved or declined
+	ReviewResolvedKey = "review_resolved"
+	// ReviewNotAssignedKey key of the message when a reviewer resolves a review assigned to another reviewer
+	ReviewNotAssignedKey = "review_not_assigned"
+	// DecisionNotApprovedKey key of the message when a decision that was not approved is accepted
+	DecisionNotApprovedKey = "decision_not_approved"
+	// AcceptanceExpiredKey key of the message when an approved credit line is accepted after the acceptance window
+	AcceptanceExpiredKey = "acceptance_expired"
+	// AccountExistsKey key of the message when an approved credit line was already accepted
+	AccountExistsKey = "account_exists"
+	// AccountClosedKey key of the message when a movement is applied to a closed account
+	AccountClosedKey = "account_closed"
+	// AccountOutstandingKey key of the message when an account with outstanding balance is closed
+	AccountOutstandingKey = "account_outstanding"
+	// InsufficientAvailableKey key of the message when a drawdown exceeds the available balance
+	InsufficientAvailableKey = "insufficient_available"
+	// RepaymentExceedsOutstandingKey key of the message when a repayment exceeds the outstanding balance
+	RepaymentExceedsOutstandingKey = "repayment_exceeds_outstanding"
+	// ReferenceConflictKey key of the message when the reference of a movement was used by another movement
+	ReferenceConflictKey = "reference_conflict"
+	// RateLimitExceededKey key of the message when a rate limit rejects a request
+	RateLimitExceededKey = "rate_limit_exceeded"
+	// RetriesExhaustedKey key of the message when a client exhausted the decline retries
+	RetriesExhaustedKey = "retries_exhausted"
+	// UnauthorizedKey key of the message when a request has no valid credentials
+	UnauthorizedKey = "unauthorized"
+	// ForbiddenKey key of the message when a request is forbidden
+	ForbiddenKey = "forbidden"
+	// NotFoundKey key of the message when a resource is not found
+	NotFoundKey = "not_found"
+	// MethodNotAllowedKey key of the message when a method is not allowed
+	MethodNotAllowedKey = "method_not_allowed"
+	// RequestTimeoutKey key of the message when the request was not received in time
+	RequestTimeoutKey = "request_timeout"
+	// PayloadTooLargeKey key of the message when the request body exceeds the limit
+	PayloadTooLargeKey = "payload_too_large"
+	// ServiceUnavailableKey key of the message when the request could not be processed in time
+	ServiceUnavailableKey = "service_unavailable"
+	// InternalServerErrorKey key of the message of an unexpected error
+	InternalServerErrorKey = "internal_server_error"
+	// RequiredFieldKey key of the message of a required field
+	RequiredFieldKey = "required_field"
+	// InvalidTaxIDKey key of the message of a field that is not a valid RFC
+	InvalidTaxIDKey = "invalid_tax_id"
+)
+
+// catalogs variable with the messages of each supported locale
+var catalogs = map[string]map[string]string{
+	English: {
+		UnmarshalErrorKey:              "unmarshal error data type, got: {0}, expected: {1} in {2} param",
+		UnmarshalFieldErrorKey:         "{0} must be a {1}, got: {2}",
+		MalformedRequestKey:            "malformed request, please check the following parameters in the request: {0}",
+		InvalidFoundingTypeKey:         "invalid foundingType",
+		InvalidIPKey:                   "invalid ip",
+		InvalidCIDRKey:                 "invalid ip or CIDR",
+		InvalidWebhookURLKey:           "the webhook url must be an absolute http or https url",
+		ReviewResolvedKey:              "the review was already resolved",
+		ReviewNotAssignedKey:           "the review must be assigned to the reviewer before it is resolved",
+		DecisionNotApprovedKey:         "only an approved credit line can be accepted",
+		AcceptanceExpiredKey:           "the acceptance window of the credit line expired",
+		AccountExistsKey:               "the credit line was already accepted",
+		AccountClosedKey:               "the account is closed",
+		AccountOutstandingKey:          "the account can not be closed with outstanding balance",
+		InsufficientAvailableKey:       "the drawdown exceeds the available balance",
+		RepaymentExceedsOutstandingKey: "the repayment exceeds the outstanding balance",
+		ReferenceConflictKey:           "the reference was used by another movement of the account",
+		RateLimitExceededKey:           "rate limit exceeded",
+		RetriesExhaustedKey:            "A sales agent will contact you",
+		UnauthorizedKey:                "the request does not have valid credentials",
+		ForbiddenKey:                   "the request is not allowed",
+		NotFoundKey:                    "resource not found",
+		MethodNotAllowedKey:            "method not allowed",
+		RequestTimeoutKey:              "the request was not received in time",
+		PayloadTooLargeKey:             "the request body is too large",
+		ServiceUnavailableKey:          "the request could not be processed in time, please try again",
+		InternalServerErrorKey:         "internal server error",
+		RequiredFieldKey:               "{0} is required",
+		InvalidTaxIDKey:                "{0} must be a valid RFC",
+	},
+	Spanish: {
+		UnmarshalErrorKey:              "error de tipo de dato, se recibió: {0}, se esperaba: {1} en el parámetro {2}",
+		UnmarshalFieldErrorKey:         "{0} debe ser de tipo {1}, se recibió: {2}",
+		MalformedRequestKey:            "solicitud mal formada, por favor revisa los siguientes parámetros de la solicitud: {0}",
+		InvalidFoundingTypeKey:         "foundingType inválido",
+		InvalidIPKey:                   "ip inválida",
+		InvalidCIDRKey:                 "ip o CIDR inválido",
+		InvalidWebhookURLKey:           "la url del webhook debe ser una url http o https absoluta",
+		ReviewResolvedKey:              "la revisión ya fue resuelta",
+		ReviewNotAssignedKey:           "la revisión debe estar asignada al revisor antes de resolverla",
+		DecisionNotApprovedKey:         "solo se puede aceptar una línea de crédito aprobada",
+		AcceptanceExpiredKey:           "el plazo para aceptar la línea de crédito expiró",
+		AccountExistsKey:               "la línea de crédito ya fue aceptada",
+		AccountClosedKey:               "la cuenta está cerrada",
+		AccountOutstandingKey:          "la cuenta no se puede cerrar con saldo pendiente",
+		InsufficientAvailableKey:       "la disposición excede el saldo disponible",
+		RepaymentExceedsOutstandingKey: "el pago excede el saldo pendiente",
+		ReferenceConflictKey:           "la referencia fue usada por otro movimiento de la cuenta",
+		RateLimitExceededKey:           "se excedió el límite de solicitudes",
+		RetriesExhaustedKey:            "Un agente de ventas se pondrá en contacto contigo",
+		UnauthorizedKey:                "la solicitud no tiene credenciales válidas",
+		ForbiddenKey:                   "la solicitud no está permitida",
+		NotFoundKey:                    "recurso no encontrado",
+		MethodNotAllowedKey:            "método no permitido",
+		RequestTimeoutKey:              "la solicitud no se recibió a tiempo",
+		PayloadTooLargeKey:             "el cuerpo de la solicitud es demasiado grande",
+		ServiceUnavailableKey:          "la solicitud no se pudo procesar a tiempo, por favor intenta de nuevo",
+		InternalServerErrorKey:         "error interno del servidor",
+		RequiredFieldKey:               "{0} es requerido",
+		InvalidTaxIDKey:                "{0} debe ser un RFC válido",
+	},
+}
@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"golang.org/x/text/language"
+)
+
+const (
+	// English identify the english locale
+	English = "en"
+	// Spanish identify the spanish locale
+	Spanish = "es"
+
+	// HeaderAcceptLanguage header with the locales accepted by the client
+	HeaderAcceptLanguage = "Accept-Language"
+	// HeaderContentLanguage header with the locale of the response
+	HeaderContentLanguage = "Content-Language"
+)
+
+// universalTranslator variable with the translators of the supported locales
+var universalTranslator *ut.UniversalTranslator = newUniversalTranslator()
+
+// newUniversalTranslator builds the universal translator and loads the message catalogs
+func newUniversalTranslator() *ut.UniversalTranslator {
+	uni := ut.New(en.New(), en.New(), es.New())
+	for locale, messages := range catalogs {
+		trans, _ := uni.GetTranslator(locale)
+		for key, text := range messages {
+			if err := trans.Add(key, text, true); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return uni
+}
+
+// RetrieveTranslator retrieves the translator that best matches an Accept-Language header,
+// the english translator is retrieved when there is no match
+func RetrieveTranslator(acceptLanguage string) ut.Translator {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return RetrieveTranslatorByLocale(English)
+	}
+
+	locales := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		locales = append(locales, strings.ReplaceAll(tag.String(), "-", "_"), base.String())
+	}
+	trans, _ := universalTranslator.FindTranslator(locales...)
+	return trans
+}
+
+// RetrieveTranslatorByLocale retrieves the translator of a supported locale
+func RetrieveTranslatorByLocale(locale string) ut.Translator {
+	trans, _ := universalTranslator.GetTranslator(locale)
+	return trans
+}
+
+// Translate retrieves the message of a key in the translator locale, the english message
+// is retrieved when the locale does not have it and the key itself when no locale has it
+func Translate(trans ut.Translator, key string, params ...string) string {
+	if msg, err := trans.T(key, params...); err == nil {
+		return msg
+	}
+	if msg, err := RetrieveTranslatorByLocale(English).T(key, params...); err == nil {
+		return msg
+	}
+	return key
+}
+
+// RegisterMessage adds or replaces the message of a key in one locale
+func RegisterMessage(locale, key, text string) error {
+	return RetrieveTranslatorByLocale(locale).Add(key, text, true)
+}
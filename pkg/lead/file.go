@@ -0,0 +1,44 @@
+package lead
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"credit-line/internal/model"
+)
+
+// fileSink struct that appends the leads as json lines to a file
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink creates a new pointer of fileSink struct
+func NewFileSink(path string) *fileSink {
+	return &fileSink{
+		path: path,
+	}
+}
+
+// Push implement the interface Sink.Push
+func (fs *fileSink) Push(_ context.Context, lead *model.Lead) error {
+	line, err := json.Marshal(lead)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	f, err := os.OpenFile(fs.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open leads file %s, %w", fs.path, err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write leads file %s, %w", fs.path, err)
+	}
+	return f.Close()
+}
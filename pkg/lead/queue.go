@@ -0,0 +1,50 @@
+package lead
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"credit-line/internal/model"
+)
+
+// queueSink struct that writes each lead as a json file in a directory consumed by an external queue worker
+type queueSink struct {
+	dir string
+}
+
+// NewQueueSink creates a new pointer of queueSink struct, it creates the queue directory
+func NewQueueSink(dir string) (*queueSink, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create leads queue directory %s, %w", dir, err)
+	}
+	return &queueSink{
+		dir: dir,
+	}, nil
+}
+
+// Push implement the interface Sink.Push, the file is written with a temporary name and renamed
+// so the consumers never read a partial lead
+func (qs *queueSink) Push(_ context.Context, lead *model.Lead) error {
+	content, err := json.Marshal(lead)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(qs.dir, ".lead-*")
+	if err != nil {
+		return fmt.Errorf("failed to create lead file in %s, %w", qs.dir, err)
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write lead file in %s, %w", qs.dir, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(qs.dir, lead.ID+".json"))
+}
@@ -0,0 +1,41 @@
+package lead
+
+import (
+	"context"
+	"fmt"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+)
+
+const (
+	// NoneSink sink that does not send the leads, they are only available in the admin API
+	NoneSink = "none"
+	// WebhookSink sink that posts the leads to an URL
+	WebhookSink = "webhook"
+	// FileSink sink that appends the leads as json lines to a file
+	FileSink = "file"
+	// QueueSink sink that writes each lead as a json file in a queue directory
+	QueueSink = "queue"
+)
+
+// Sink contract to send the leads to the sales team
+type Sink interface {
+	Push(ctx context.Context, lead *model.Lead) error
+}
+
+// NewSink builds the sink of the leads values, nil when the leads are not sent
+func NewSink(cfg *env.Leads) (Sink, error) {
+	switch cfg.Sink {
+	case NoneSink:
+		return nil, nil
+	case WebhookSink:
+		return NewWebhookSink(cfg.WebhookURL), nil
+	case FileSink:
+		return NewFileSink(cfg.FilePath), nil
+	case QueueSink:
+		return NewQueueSink(cfg.QueueDir)
+	default:
+		return nil, fmt.Errorf("unknown leads sink %q", cfg.Sink)
+	}
+}
@@ -0,0 +1,193 @@
+package lead
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+// Store struct with the leads and the last credit line requests of each ip or applicant key
+type Store struct {
+	mu       sync.RWMutex
+	leads    map[string]*model.Lead
+	open     map[string]string
+	requests map[string][]model.LeadRequest
+}
+
+// NewStore creates a new pointer of Store struct without leads
+func NewStore() *Store {
+	return &Store{
+		leads:    make(map[string]*model.Lead),
+		open:     make(map[string]string),
+		requests: make(map[string][]model.LeadRequest),
+	}
+}
+
+// RecordRequest stores a credit line request of an ip or applicant key, only the last size requests are kept
+func (s *Store) RecordRequest(key string, request model.LeadRequest, size uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := append(s.requests[key], request)
+	if uint(len(requests)) > size {
+		requests = requests[uint(len(requests))-size:]
+	}
+	s.requests[key] = requests
+}
+
+// Requests retrieves the last credit line requests of an ip or applicant key from the oldest to the newest
+func (s *Store) Requests(key string) []model.LeadRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]model.LeadRequest(nil), s.requests[key]...)
+}
+
+// Save stores a copy of a lead, the lead is the open lead of its key until it is closed
+func (s *Store) Save(lead *model.Lead) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *lead
+	s.store(&stored)
+}
+
+// Update applies a change to the stored lead under the lock of the store, so the change compares and sets the
+// status of the lead without overwriting a concurrent change; it retrieves a copy of the updated lead, false
+// when the lead does not exist
+func (s *Store) Update(id string, update func(lead *model.Lead)) (*model.Lead, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lead, ok := s.leads[id]
+	if !ok {
+		return nil, false
+	}
+	updated := *lead
+	update(&updated)
+	s.store(&updated)
+	stored := updated
+	return &stored, true
+}
+
+// store stores a lead, the lead is the open lead of its key until it is closed
+func (s *Store) store(lead *model.Lead) {
+	s.leads[lead.ID] = lead
+	if lead.Status.Open() {
+		s.open[lead.Key()] = lead.ID
+	} else if s.open[lead.Key()] == lead.ID {
+		delete(s.open, lead.Key())
+	}
+}
+
+// Lead retrieves a copy of a lead, false when the lead does not exist
+func (s *Store) Lead(id string) (*model.Lead, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lead, ok := s.leads[id]
+	if !ok {
+		return nil, false
+	}
+	stored := *lead
+	return &stored, true
+}
+
+// ReserveOpenLead reserves the id as the open lead of an ip or applicant key until the lead is saved or released,
+// it retrieves the id of the open or reserved lead of the key and false when the key already has one
+func (s *Store) ReserveOpenLead(key, id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if open, ok := s.open[key]; ok {
+		return open, false
+	}
+	s.open[key] = id
+	return id, true
+}
+
+// ReleaseOpenLead releases the reservation of the open lead of an ip or applicant key, a saved lead is kept as
+// the open lead of its key
+func (s *Store) ReleaseOpenLead(key, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, saved := s.leads[id]; !saved && s.open[key] == id {
+		delete(s.open, key)
+	}
+}
+
+// OpenLead retrieves a copy of the open lead of an ip or applicant key, false when the key does not have an open
+// lead
+func (s *Store) OpenLead(key string) (*model.Lead, bool) {
+	s.mu.RLock()
+	id, ok := s.open[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return s.Lead(id)
+}
+
+// Leads retrieves a copy of all the leads from the newest to the oldest
+func (s *Store) Leads() []*model.Lead {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	leads := make([]*model.Lead, 0, len(s.leads))
+	for _, lead := range s.leads {
+		stored := *lead
+		leads = append(leads, &stored)
+	}
+	sort.Slice(leads, func(i, j int) bool { return leads[i].CreatedAt.After(leads[j].CreatedAt) })
+	return leads
+}
+
+// Expire purges or anonymizes the leads not updated since before and removes the requests determined before it,
+// it retrieves the counts of the leads and of the requests; the requests are always purged
+func (s *Store) Expire(before time.Time, mode model.RetentionMode, dryRun bool) (model.RetentionCounts, model.RetentionCounts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var leads model.RetentionCounts
+	for id, lead := range s.leads {
+		if !lead.UpdatedAt.Before(before) {
+			continue
+		}
+		leads.Expired++
+		switch {
+		case mode == model.RetentionPurge:
+			leads.Purged++
+		case lead.IP != "" || lead.ApplicantID != "" || lead.Contact != nil || len(lead.Requests) > 0:
+			leads.Anonymized++
+		default:
+			continue
+		}
+		if dryRun {
+			continue
+		}
+		if s.open[lead.Key()] == id {
+			delete(s.open, lead.Key())
+		}
+		if mode == model.RetentionPurge {
+			delete(s.leads, id)
+			continue
+		}
+		lead.IP, lead.ApplicantID, lead.Contact, lead.Requests = "", "", nil, nil
+	}
+
+	var requests model.RetentionCounts
+	for ip, history := range s.requests {
+		kept := make([]model.LeadRequest, 0, len(history))
+		for _, request := range history {
+			if request.DeterminedAt.Before(before) {
+				requests.Expired++
+				requests.Purged++
+				continue
+			}
+			kept = append(kept, request)
+		}
+		switch {
+		case dryRun:
+		case len(kept) == 0:
+			delete(s.requests, ip)
+		default:
+			s.requests[ip] = kept
+		}
+	}
+	return leads, requests
+}
@@ -0,0 +1,55 @@
+package lead
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+// webhookTimeout max time to wait the response of the webhook
+const webhookTimeout = 10 * time.Second
+
+// webhookSink struct that posts the leads as json to an URL
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a new pointer of webhookSink struct
+func NewWebhookSink(url string) *webhookSink {
+	return &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Push implement the interface Sink.Push, the lead is sent when the webhook responds a 2xx status code
+func (ws *webhookSink) Push(ctx context.Context, lead *model.Lead) error {
+	body, err := json.Marshal(lead)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := ws.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post the lead, %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the webhook responded %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"credit-line/pkg/env"
+	"credit-line/pkg/pii"
+)
+
+// contextKey type for the keys of the values stored in the context
+type contextKey string
+
+const (
+	// loggerKey key of the logger stored in the context
+	loggerKey contextKey = "logger"
+	// requestIDKey key of the request id stored in the context
+	requestIDKey contextKey = "request_id"
+)
+
+// contextHandler slog handler that adds the request id and the trace id of the context to the records
+type contextHandler struct {
+	slog.Handler
+}
+
+// New creates a json logger that writes in w with the configured level, the applicant data of the records is
+// redacted when RedactPII is enabled
+func New(cfg *env.Logger, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.RedactPII {
+		opts.ReplaceAttr = pii.RedactAttr
+	}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "json", "":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+	return slog.New(&contextHandler{handler}), nil
+}
+
+// WithLogger retrieves a copy of the context with the logger
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext retrieves the logger of the context, the default logger is retrieved when
+// the context does not have one
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// WithRequestID retrieves a copy of the context with the request id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID retrieves the request id of the context
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// Handle implement the interface slog.Handler.Handle adding the request id and trace id attributes
+func (ch *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return ch.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implement the interface slog.Handler.WithAttrs
+func (ch *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{ch.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implement the interface slog.Handler.WithGroup
+func (ch *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{ch.Handler.WithGroup(name)}
+}
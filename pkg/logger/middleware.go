@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPAccessLog middleware that writes a structured access log for each request
+func HTTPAccessLog() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			statusCode := c.Response().Status
+			if err != nil {
+				statusCode = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					statusCode = he.Code
+				}
+			}
+
+			level := slog.LevelInfo
+			if statusCode >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			ctx := c.Request().Context()
+			FromContext(ctx).LogAttrs(ctx, level, "http request",
+				slog.String("ip", c.RealIP()),
+				slog.String("method", c.Request().Method),
+				slog.String("uri", c.Request().RequestURI),
+				slog.Int("status", statusCode),
+				slog.Duration("latency", time.Since(start)),
+			)
+			return err
+		}
+	}
+}
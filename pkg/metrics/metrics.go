@@ -0,0 +1,253 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/cache"
+)
+
+const (
+	// namespace namespace of the metrics of the application
+	namespace = "credit_line"
+
+	// HTTPTransport identify the requests received by the HTTP server
+	HTTPTransport = "http"
+	// GRPCTransport identify the requests received by the gRPC server
+	GRPCTransport = "grpc"
+)
+
+var (
+	// registry variable with the registry of the metrics of the application
+	registry = prometheus.NewRegistry()
+
+	// httpRequests counter of the HTTP requests by route, method and status
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	// httpRequestDuration histogram of the HTTP requests latency by route, method and status
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// decisions counter of the credit decisions by founding type and credit status
+	decisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "decisions_total",
+		Help:      "Total of credit decisions by founding type and credit status.",
+	}, []string{"founding_type", "credit_status"})
+
+	// authorizedAmount histogram of the authorized credit lines by founding type
+	authorizedAmount = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "authorized_amount",
+		Help:      "Credit line amounts authorized by founding type.",
+		Buckets:   prometheus.ExponentialBuckets(100, 4, 8),
+	}, []string{"founding_type"})
+
+	// rateLimitRejections counter of the requests rejected by the rate limits and retries policies
+	rateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Total of requests rejected by the rate limits and retries policies by middleware and transport.",
+	}, []string{"middleware", "transport"})
+
+	// repeatApplications counter of the applications of an applicant from other ips in the duplicate window
+	repeatApplications = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repeat_applications_total",
+		Help:      "Total of applications of an identified applicant from other ips in the duplicate window.",
+	})
+
+	// riskAssessments counter of the risk assessments of the requests by action
+	riskAssessments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "risk_assessments_total",
+		Help:      "Total of risk assessments of the credit line requests by action.",
+	}, []string{"action"})
+
+	// riskSignals counter of the risk signals raised by the requests by signal
+	riskSignals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "risk_signals_total",
+		Help:      "Total of risk signals raised by the credit line requests by signal.",
+	}, []string{"signal"})
+
+	// reviews counter of the credit line requests routed to manual review by reason
+	reviews = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviews_total",
+		Help:      "Total of credit line requests routed to manual review by reason.",
+	}, []string{"reason"})
+
+	// reviewResolutions counter of the reviews resolved by the reviewers by action
+	reviewResolutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "review_resolutions_total",
+		Help:      "Total of reviews resolved by the reviewers by action.",
+	}, []string{"action"})
+
+	// reviewSLABreaches counter of the reviews that were not resolved before their due time
+	reviewSLABreaches = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "review_sla_breaches_total",
+		Help:      "Total of reviews that were not resolved before their due time.",
+	})
+
+	// accountsOpened counter of the credit line accounts opened by the applicants
+	accountsOpened = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accounts_opened_total",
+		Help:      "Total of credit line accounts opened by the applicants.",
+	})
+
+	// accountMovements counter of the movements of the credit line accounts by type
+	accountMovements = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "account_movements_total",
+		Help:      "Total of drawdowns and repayments of the credit line accounts by type.",
+	}, []string{"type"})
+
+	// ledgerInconsistencies counter of the ledger checks that found an inconsistency
+	ledgerInconsistencies = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ledger_inconsistencies_total",
+		Help:      "Total of ledger checks that found an inconsistency.",
+	})
+
+	// cacheEntries gauge of the clients stored in the request cache
+	cacheEntries = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "request_cache_entries",
+		Help:      "Number of clients stored in the request cache.",
+	}, func() float64 {
+		return float64(cache.RetrieveRequestCache().Size())
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		decisions,
+		authorizedAmount,
+		rateLimitRejections,
+		repeatApplications,
+		riskAssessments,
+		riskSignals,
+		reviews,
+		reviewResolutions,
+		reviewSLABreaches,
+		accountsOpened,
+		accountMovements,
+		ledgerInconsistencies,
+		cacheEntries,
+	)
+}
+
+// Handler retrieves the echo handler that exposes the metrics
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// HTTPMetrics middleware that records the count and latency of the HTTP requests
+func HTTPMetrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			statusCode := c.Response().Status
+			if err != nil {
+				statusCode = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					statusCode = he.Code
+				}
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			labels := prometheus.Labels{
+				"route":  route,
+				"method": c.Request().Method,
+				"status": strconv.Itoa(statusCode),
+			}
+			httpRequests.With(labels).Inc()
+			httpRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// ObserveDecision records a credit decision and the authorized amount when it is approved
+func ObserveDecision(foundingType string, cs model.CreditStatus, amount float64) {
+	decisions.WithLabelValues(foundingType, string(cs)).Inc()
+	if cs == model.Approved {
+		authorizedAmount.WithLabelValues(foundingType).Observe(amount)
+	}
+}
+
+// ObserveRateLimitRejection records a request rejected by a rate limit or retries policy
+func ObserveRateLimitRejection(middleware, transport string) {
+	rateLimitRejections.WithLabelValues(middleware, transport).Inc()
+}
+
+// ObserveRepeatApplication records an application of an applicant from other ips in the duplicate window
+func ObserveRepeatApplication() {
+	repeatApplications.Inc()
+}
+
+// ObserveRiskAssessment records the action and the signals of the risk assessment of a request
+func ObserveRiskAssessment(risk *model.RiskAssessment) {
+	riskAssessments.WithLabelValues(string(risk.Action)).Inc()
+	for _, signal := range risk.Signals {
+		riskSignals.WithLabelValues(string(signal)).Inc()
+	}
+}
+
+// ObserveReview records a credit line request routed to manual review
+func ObserveReview(reason model.ReviewReason) {
+	reviews.WithLabelValues(string(reason)).Inc()
+}
+
+// ObserveReviewResolution records a review resolved by a reviewer
+func ObserveReviewResolution(action model.ReviewAction) {
+	reviewResolutions.WithLabelValues(string(action)).Inc()
+}
+
+// ObserveReviewSLABreach records a review that was not resolved before its due time
+func ObserveReviewSLABreach() {
+	reviewSLABreaches.Inc()
+}
+
+// ObserveAccountOpened records a credit line account opened by an applicant
+func ObserveAccountOpened() {
+	accountsOpened.Inc()
+}
+
+// ObserveMovement records a movement of a credit line account
+func ObserveMovement(movementType model.MovementType) {
+	accountMovements.WithLabelValues(string(movementType)).Inc()
+}
+
+// ObserveLedgerInconsistency records a ledger check that found an inconsistency
+func ObserveLedgerInconsistency() {
+	ledgerInconsistencies.Inc()
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+
+	"credit-line/pkg/access"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+)
+
+// ConfigureAccess applies the allow and block lists and the trusted proxies of the config holder
+// and applies them again after each reload
+func ConfigureAccess(holder *env.Holder, l *slog.Logger) error {
+	if err := applyAccess(holder.Environment().Access); err != nil {
+		return err
+	}
+	holder.Subscribe(func(conf *env.Environment) {
+		if err := applyAccess(conf.Access); err != nil {
+			l.Error("failed to apply the access lists", "error", err)
+		}
+	})
+	return nil
+}
+
+// applyAccess replaces the config source of the access list and the trusted proxies
+func applyAccess(cfg *env.Access) error {
+	entries := append(access.ParseEntries(cfg.AllowNetworks(), access.AllowAction),
+		access.ParseEntries(cfg.BlockNetworks(), access.BlockAction)...)
+	if err := access.RetrieveList().Replace(access.ConfigSource, entries); err != nil {
+		return err
+	}
+	return access.RetrieveTrustedProxies().Replace(cfg.TrustedProxyNetworks())
+}
+
+// ExtractIP echo ip extractor that retrieves the ip of the client of an HTTP request, the X-Forwarded-For
+// header is only used when the peer is a trusted proxy
+func ExtractIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return access.RetrieveTrustedProxies().ClientIP(host, r.Header.Values(echo.HeaderXForwardedFor))
+}
+
+// IpAccessList middleware that rejects the requests of the ips in a blocked network
+func IpAccessList() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ip := c.RealIP()
+			if access.RetrieveList().IsBlocked(ip) {
+				ctx := c.Request().Context()
+				logger.FromContext(ctx).WarnContext(ctx, "blocked ip", "ip", ip, "uri", c.Request().RequestURI)
+				metrics.ObserveRateLimitRejection("IpAccessList", metrics.HTTPTransport)
+				return &echo.HTTPError{
+					Code:     http.StatusForbidden,
+					Message:  http.StatusText(http.StatusForbidden),
+					Internal: errors.ErrIPBlocked,
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// UnaryIpAccessList interceptor that rejects the requests of the ips in a blocked network
+func UnaryIpAccessList() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ip := GRPCRealIP(ctx)
+		if access.RetrieveList().IsBlocked(ip) {
+			logger.FromContext(ctx).WarnContext(ctx, "blocked ip", "ip", ip, "method", info.FullMethod)
+			metrics.ObserveRateLimitRejection("IpAccessList", metrics.GRPCTransport)
+			return nil, errors.MapGRPCPolicyError(errors.ErrIPBlocked, GRPCTranslator(ctx))
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bearerPrefix prefix of the token in the Authorization header
+const bearerPrefix = "Bearer "
+
+// AdminAuth middleware that only accepts the requests with the admin bearer token, the token is retrieved
+// on each request to support reloads and every request is rejected while the token is empty
+func AdminAuth(token func() string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			expected := token()
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			given, ok := strings.CutPrefix(authorization, bearerPrefix)
+			if expected == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(expected)) != 1 {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			}
+			return next(c)
+		}
+	}
+}
+
+// AccountAuth middleware that only accepts the requests with the bearer token of the account of the id path
+// parameter, authorize reports if the token is the token of the account
+func AccountAuth(authorize func(c echo.Context, id, token string) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			given, ok := strings.CutPrefix(authorization, bearerPrefix)
+			if !ok || !authorize(c, c.Param("id"), given) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="account"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			}
+			return next(c)
+		}
+	}
+}
+
+// reviewerKey key of the name of the authenticated reviewer in the echo context
+const reviewerKey = "reviewer"
+
+// ReviewerAuth middleware that only accepts the requests with the bearer token of a reviewer and stores the name
+// of the reviewer in the context, the names of the reviewers by token are retrieved on each request to support
+// reloads and every request is rejected while there are no reviewers
+func ReviewerAuth(reviewers func() map[string]string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			given, ok := strings.CutPrefix(authorization, bearerPrefix)
+			// every token is compared to not leak which token matched
+			var reviewer string
+			for token, name := range reviewers() {
+				if subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+					reviewer = name
+				}
+			}
+			if !ok || reviewer == "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="backoffice"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			}
+			c.Set(reviewerKey, reviewer)
+			return next(c)
+		}
+	}
+}
+
+// Reviewer retrieves the name of the reviewer authenticated by the ReviewerAuth middleware
+func Reviewer(c echo.Context) string {
+	reviewer, _ := c.Get(reviewerKey).(string)
+	return reviewer
+}
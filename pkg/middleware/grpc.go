@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/ulule/limiter/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/access"
+	"credit-line/pkg/errors"
+	"credit-line/pkg/i18n"
+	"credit-line/pkg/logger"
+	"credit-line/pkg/metrics"
+)
+
+const (
+	// metadataForwardedFor metadata key with the ip chain of the client behind proxies
+	metadataForwardedFor = "x-forwarded-for"
+	// MetadataAcceptLanguage metadata key with the locales accepted by the client
+	MetadataAcceptLanguage = "accept-language"
+)
+
+// UnaryIpRateLimitByTime interceptor that provides a rate limit validation by time
+func UnaryIpRateLimitByTime() grpc.UnaryServerInterceptor {
+	return unaryIpRateLimit("IpRateLimitByTime", approvedRateLimiter, model.Approved)
+}
+
+// UnaryIpRateLimitByFail interceptor that provides a rate limit validation by time when the request is declined
+func UnaryIpRateLimitByFail() grpc.UnaryServerInterceptor {
+	return unaryIpRateLimit("IpRateLimitByFail", declinedRateLimiter, model.Declined)
+}
+
+// UnaryValidateRetries interceptor that provides a validation retries, kept by ip like ValidateRetries
+func UnaryValidateRetries() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		cfg := retrievePolicies().cfg
+		if err := checkRetries(GRPCRealIP(ctx), cfg.DeclineRetriesAllowed, 0); err != nil {
+			metrics.ObserveRateLimitRejection("ValidateRetries", metrics.GRPCTransport)
+			return nil, errors.MapGRPCPolicyError(err, GRPCTranslator(ctx))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// GRPCRealIP retrieves the ip of the client of a gRPC request, the x-forwarded-for metadata
+// is only used when the peer is a trusted proxy
+func GRPCRealIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	return access.RetrieveTrustedProxies().ClientIP(host, md.Get(metadataForwardedFor))
+}
+
+// unaryIpRateLimit builds a rate limit interceptor applied when the current credit status is the given status
+func unaryIpRateLimit(name string, ipRateLimiter func() *limiter.Limiter, status model.CreditStatus) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ip := GRPCRealIP(ctx)
+		err := checkRateLimit(ctx, ipRateLimiter(), status, ip)
+		switch {
+		case stderrors.Is(err, errors.ErrRateLimitExceeded):
+			logger.FromContext(ctx).WarnContext(ctx, "many requests", "ip", ip, "method", info.FullMethod, "policy", name)
+			metrics.ObserveRateLimitRejection(name, metrics.GRPCTransport)
+			return nil, errors.MapGRPCPolicyError(err, GRPCTranslator(ctx))
+		case err != nil:
+			logger.FromContext(ctx).ErrorContext(ctx, "rate limit check failed", "ip", ip, "method", info.FullMethod, "policy", name, "error", err)
+			return nil, errors.MapGRPCPolicyError(err, GRPCTranslator(ctx))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// GRPCTranslator retrieves the translator of the locales accepted by the client of a gRPC request
+func GRPCTranslator(ctx context.Context) ut.Translator {
+	var acceptLanguage string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataAcceptLanguage); len(values) > 0 {
+			acceptLanguage = values[0]
+		}
+	}
+	return i18n.RetrieveTranslator(acceptLanguage)
+}
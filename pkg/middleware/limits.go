@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+)
+
+// BodyLimit middleware that rejects the requests with a body larger than the limit in bytes,
+// the bodies without content length fail when the limit is reached while they are read
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				return &echo.HTTPError{
+					Code:     http.StatusRequestEntityTooLarge,
+					Message:  http.StatusText(http.StatusRequestEntityTooLarge),
+					Internal: &http.MaxBytesError{Limit: limit},
+				}
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
+
+// RequestTimeout middleware that cancels the context of the request when the timeout is reached
+func RequestTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			if err == nil && ctx.Err() == context.DeadlineExceeded && !c.Response().Committed {
+				return &echo.HTTPError{
+					Code:     http.StatusServiceUnavailable,
+					Message:  http.StatusText(http.StatusServiceUnavailable),
+					Internal: ctx.Err(),
+				}
+			}
+			return err
+		}
+	}
+}
+
+// UnaryRequestTimeout interceptor that cancels the context of the request when the timeout is reached
+func UnaryRequestTimeout(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/access"
+	"credit-line/pkg/cache"
+	"credit-line/pkg/certificate"
+	"credit-line/pkg/env"
+	"credit-line/pkg/errors"
+)
+
+// healthCheckKey key used to check the rate limiters stores
+const healthCheckKey = "health-check"
+
+// RateLimitState struct with the state of a rate limit of a client
+type RateLimitState struct {
+	Policy    string    `json:"policy"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	Reached   bool      `json:"reached"`
+}
+
+// policies struct with the rate limiters shared by the HTTP middlewares and the gRPC interceptors
+type policies struct {
+	approvedRateLimiter *limiter.Limiter
+	declinedRateLimiter *limiter.Limiter
+	cfg                 *env.Middlewares
+}
+
+var (
+	// sharedPolicies variable with the current policies of the application
+	sharedPolicies atomic.Pointer[policies]
+	// policiesOnce variable to build the default policies only once
+	policiesOnce sync.Once
+)
+
+// ConfigurePolicies builds the policies from the config holder and rebuilds them after each reload,
+// the counters of the rate limiters are kept between reloads
+func ConfigurePolicies(holder *env.Holder) {
+	policiesOnce.Do(func() {})
+	sharedPolicies.Store(newPolicies(holder.Middlewares(), nil))
+	holder.Subscribe(func(conf *env.Environment) {
+		sharedPolicies.Store(newPolicies(conf.Middlewares, sharedPolicies.Load()))
+	})
+}
+
+// retrievePolicies retrieves the policies shared by all the transports, the default policies
+// are built from the environment variables when ConfigurePolicies was not invoked
+func retrievePolicies() *policies {
+	policiesOnce.Do(func() {
+		sharedPolicies.Store(newPolicies(env.RetrieveEnvVariables().Middlewares, nil))
+	})
+	return sharedPolicies.Load()
+}
+
+// newPolicies builds the policies with the middlewares values reusing the stores of the previous policies
+func newPolicies(cfg *env.Middlewares, previous *policies) *policies {
+	approvedStore := memory.NewStore()
+	declinedStore := memory.NewStore()
+	if previous != nil {
+		approvedStore = previous.approvedRateLimiter.Store
+		declinedStore = previous.declinedRateLimiter.Store
+	}
+	return &policies{
+		approvedRateLimiter: limiter.New(approvedStore, limiter.Rate{
+			Period: time.Duration(cfg.ApprovedRateLimitTime) * time.Second,
+			Limit:  cfg.ApprovedRateLimitRequest,
+		}),
+		declinedRateLimiter: limiter.New(declinedStore, limiter.Rate{
+			Period: time.Duration(cfg.DeclineRateLimitTime) * time.Second,
+			Limit:  cfg.DeclineRateLimitRequest,
+		}),
+		cfg: cfg,
+	}
+}
+
+// approvedRateLimiter retrieves the current rate limiter applied when the last request was approved
+func approvedRateLimiter() *limiter.Limiter {
+	return retrievePolicies().approvedRateLimiter
+}
+
+// declinedRateLimiter retrieves the current rate limiter applied when the last request was declined
+func declinedRateLimiter() *limiter.Limiter {
+	return retrievePolicies().declinedRateLimiter
+}
+
+// retrieveRateLimitKey retrieves the key of the rate limits of a client, the identity of the verified
+// client certificate has priority over the ip
+func retrieveRateLimitKey(ctx context.Context, ip string) string {
+	if identity := certificate.IdentityFromContext(ctx); identity != nil {
+		return identity.Key()
+	}
+	return ip
+}
+
+// checkRateLimit checks the rate limit of a client when the current credit status is the given status,
+// it retrieves errors.ErrRateLimitExceeded when the limit is reached; the allowed ips are not limited
+func checkRateLimit(ctx context.Context, ipRateLimiter *limiter.Limiter, status model.CreditStatus, ip string) error {
+	if access.RetrieveList().IsAllowed(ip) {
+		return nil
+	}
+	limiterCtx, err := ipRateLimiter.Get(ctx, retrieveRateLimitKey(ctx, ip))
+	if cache.RetrieveRequestCache().CreditStatus() != string(status) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if limiterCtx.Reached {
+		return errors.ErrRateLimitExceeded
+	}
+	return nil
+}
+
+// checkRetries checks the decline retries of an ip or applicant key with the pending declines that are not
+// counted yet, it retrieves errors.ErrRetriesExhausted when the retries allowed and the allowance of the key are
+// exhausted; the allowed ips are not limited
+func checkRetries(key string, retriesAllowed, pending uint) error {
+	if access.RetrieveList().IsAllowed(key) {
+		return nil
+	}
+	if allowance, ok := cache.RetrieveRequestCache().Allowance(key); ok {
+		retriesAllowed += allowance.Retries
+	}
+	if cache.RetrieveRequestCache().Failures(key)+pending >= retriesAllowed {
+		return errors.ErrRetriesExhausted
+	}
+	return nil
+}
+
+// RetriesExhausted retrieves true when an ip or applicant key exhausted the current decline retries allowed and
+// its allowance
+func RetriesExhausted(key string) bool {
+	return checkRetries(key, retrievePolicies().cfg.DeclineRetriesAllowed, 0) != nil
+}
+
+// DeclineExhaustsRetries retrieves true when a declined request that is not counted yet exhausts the decline
+// retries allowed and the allowance of an ip or applicant key
+func DeclineExhaustsRetries(key string) bool {
+	return checkRetries(key, retrievePolicies().cfg.DeclineRetriesAllowed, 1) != nil
+}
+
+// CheckLimiterStores checks that the stores of the rate limiters are reachable
+func CheckLimiterStores(ctx context.Context) error {
+	p := retrievePolicies()
+	for _, l := range []*limiter.Limiter{p.approvedRateLimiter, p.declinedRateLimiter} {
+		if _, err := l.Peek(ctx, healthCheckKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeclineRetriesAllowed retrieves the current decline retries allowed for each ip
+func DeclineRetriesAllowed() uint {
+	return retrievePolicies().cfg.DeclineRetriesAllowed
+}
+
+// RetrieveRateLimitStates retrieves the state of the rate limits of a client without consuming them
+func RetrieveRateLimitStates(ctx context.Context, key string) ([]RateLimitState, error) {
+	p := retrievePolicies()
+	var states []RateLimitState
+	for _, policy := range []struct {
+		name    string
+		limiter *limiter.Limiter
+	}{
+		{"IpRateLimitByTime", p.approvedRateLimiter},
+		{"IpRateLimitByFail", p.declinedRateLimiter},
+	} {
+		limiterCtx, err := policy.limiter.Peek(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, RateLimitState{
+			Policy:    policy.name,
+			Limit:     limiterCtx.Limit,
+			Remaining: limiterCtx.Remaining,
+			Reset:     time.Unix(limiterCtx.Reset, 0).UTC(),
+			Reached:   limiterCtx.Reached,
+		})
+	}
+	return states, nil
+}
+
+// ResetRateLimits resets the rate limits of a client
+func ResetRateLimits(ctx context.Context, key string) error {
+	p := retrievePolicies()
+	for _, l := range []*limiter.Limiter{p.approvedRateLimiter, p.declinedRateLimiter} {
+		if _, err := l.Reset(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
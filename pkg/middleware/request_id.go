@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"credit-line/pkg/logger"
+)
+
+const (
+	// HeaderRequestID header with the id of the request
+	HeaderRequestID = "X-Request-ID"
+	// metadataRequestID metadata key with the id of the request
+	metadataRequestID = "x-request-id"
+
+	// maxRequestIDLength max length of the request ids accepted from the clients
+	maxRequestIDLength = 128
+)
+
+// RequestID middleware that accepts the X-Request-ID header of the client or generates a new one,
+// the request id and the logger are stored in the request context and the id is sent in the response
+func RequestID(l *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(HeaderRequestID)
+			if !isValidRequestID(requestID) {
+				requestID = generateRequestID()
+			}
+			c.Response().Header().Set(HeaderRequestID, requestID)
+
+			ctx := logger.WithRequestID(c.Request().Context(), requestID)
+			ctx = logger.WithLogger(ctx, l)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// UnaryRequestID interceptor that accepts the x-request-id metadata of the client or generates a new one,
+// the request id and the logger are stored in the request context and the id is sent in the response header
+func UnaryRequestID(l *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var requestID string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(metadataRequestID); len(values) > 0 {
+				requestID = values[0]
+			}
+		}
+		if !isValidRequestID(requestID) {
+			requestID = generateRequestID()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID))
+
+		ctx = logger.WithRequestID(ctx, requestID)
+		ctx = logger.WithLogger(ctx, l)
+		return handler(ctx, req)
+	}
+}
+
+// isValidRequestID reports if a request id sent by a client can be accepted
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		isAlphanumeric := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlphanumeric && r != '-' && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRequestID generates a random request id
+func generateRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
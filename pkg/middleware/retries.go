@@ -4,18 +4,18 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
-	"credit-line/pkg/cache"
-	"credit-line/pkg/env"
+	"credit-line/pkg/metrics"
 )
 
-// ValidateRetries middleware that provides a validation retries
+// ValidateRetries middleware that provides a validation retries, the retries are kept by ip and not by the
+// certificate identity like the rate limits: the declines are counted by ip and by applicant, and a client
+// certificate is shared by all the applicants of a partner, so one applicant would exhaust the retries of the others
 func ValidateRetries() echo.MiddlewareFunc {
-	cfg := env.RetrieveEnvVariables().Middlewares
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		cacheRequest := cache.RetrieveRequestCache().RequestFailed
 		return func(c echo.Context) (err error) {
-			ipRetries := cacheRequest[c.RealIP()]
-			if ipRetries >= cfg.DeclineRetriesAllowed {
+			cfg := retrievePolicies().cfg
+			if err := checkRetries(c.RealIP(), cfg.DeclineRetriesAllowed, 0); err != nil {
+				metrics.ObserveRateLimitRejection("ValidateRetries", metrics.HTTPTransport)
 				return &echo.HTTPError{
 					Code:     middleware.ErrRateLimitExceeded.Code,
 					Message:  cfg.DeclineRetriesMessage,
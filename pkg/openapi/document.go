@@ -0,0 +1,114 @@
+package openapi
+
+// Version version of the OpenAPI specification of the documents
+const Version = "3.0.3"
+
+// Document struct that represents an OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components,omitempty"`
+}
+
+// Info struct with the metadata of the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server struct that represents a server of the API
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem struct with the operations of a path
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+// Operation struct that represents an API operation
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter struct that represents a parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody struct that represents the body of a request
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response struct that represents a response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType struct with the schema of a media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// SecurityRequirement map with the security schemes required by an operation and their scopes
+type SecurityRequirement map[string][]string
+
+// SecurityScheme struct that represents an authentication scheme of the API
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components struct with the reusable objects of the document
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// Operation retrieves the operation of a method in the path item
+func (pi *PathItem) Operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return pi.Get
+	case "POST":
+		return pi.Post
+	case "PUT":
+		return pi.Put
+	case "PATCH":
+		return pi.Patch
+	case "DELETE":
+		return pi.Delete
+	default:
+		return nil
+	}
+}
+
+// ResolveSchema retrieves the component schema when the schema is a reference
+func (d *Document) ResolveSchema(s *Schema) *Schema {
+	const prefix = "#/components/schemas/"
+	if s == nil || s.Ref == "" || len(s.Ref) <= len(prefix) {
+		return s
+	}
+	return d.Components.Schemas[s.Ref[len(prefix):]]
+}
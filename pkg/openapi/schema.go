@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// timeType type of the values serialized as date-time strings
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema struct that represents an OpenAPI schema object
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+}
+
+// Ref creates a reference to a component schema
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// SchemaOf builds the schema of a value from its type, the properties are named with the
+// json tags and the fields with the validate required rule are marked as required
+func SchemaOf(v interface{}) *Schema {
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+// schemaOfType builds the schema of a type
+func schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		return schemaOfStruct(t)
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	default:
+		return &Schema{}
+	}
+}
+
+// schemaOfStruct builds the object schema of a struct type
+func schemaOfStruct(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, omitempty := parseJSONTag(f)
+		if name == "-" {
+			continue
+		}
+
+		s.Properties[name] = schemaOfType(f.Type)
+		if hasValidateRule(f, "required") || (!omitempty && f.Tag.Get("validate") == "" && f.Type.Kind() != reflect.Ptr) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// parseJSONTag retrieves the json name of a field and if it is omitted when empty
+func parseJSONTag(f reflect.StructField) (string, bool) {
+	parts := strings.Split(f.Tag.Get("json"), ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+// hasValidateRule reports if a field has a validate rule
+func hasValidateRule(f reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(f.Tag.Get("validate"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
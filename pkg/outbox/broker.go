@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"credit-line/pkg/env"
+)
+
+const (
+	// NoneBroker broker that disables the external publication, the relay only delivers the events to the webhooks
+	NoneBroker = "none"
+	// MemoryBroker broker that keeps the last published messages in memory
+	MemoryBroker = "memory"
+	// FileBroker broker that appends the messages as json lines to a file
+	FileBroker = "file"
+	// NatsBroker broker that publishes the messages to a NATS server
+	NatsBroker = "nats"
+)
+
+// memoryBrokerSize max number of messages kept by the memory broker
+const memoryBrokerSize = 1000
+
+// Message struct that represents an event published to the broker, the id is the dedupe id of the event
+type Message struct {
+	ID      string          `json:"id"`
+	Subject string          `json:"subject"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Broker contract to publish the messages of the decision events
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// NewBroker builds the broker of the outbox values, nil when the events are not published to an external broker
+func NewBroker(cfg *env.Outbox) (Broker, error) {
+	switch cfg.Broker {
+	case NoneBroker:
+		return nil, nil
+	case MemoryBroker:
+		return NewMemoryBroker(), nil
+	case FileBroker:
+		return NewFileBroker(cfg.BrokerFilePath), nil
+	case NatsBroker:
+		return NewNatsBroker(cfg.NatsURL)
+	default:
+		return nil, fmt.Errorf("unknown outbox broker %q", cfg.Broker)
+	}
+}
+
+// memoryBroker struct that implement the Broker interface in memory, the messages with a known id are ignored
+type memoryBroker struct {
+	mu       sync.Mutex
+	messages []Message
+	ids      map[string]bool
+}
+
+// NewMemoryBroker creates a new pointer of memoryBroker struct
+func NewMemoryBroker() *memoryBroker {
+	return &memoryBroker{
+		ids: make(map[string]bool),
+	}
+}
+
+// Publish implement the interface Broker.Publish
+func (mb *memoryBroker) Publish(_ context.Context, msg Message) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if mb.ids[msg.ID] {
+		return nil
+	}
+	mb.ids[msg.ID] = true
+	mb.messages = append(mb.messages, msg)
+	if len(mb.messages) > memoryBrokerSize {
+		delete(mb.ids, mb.messages[0].ID)
+		mb.messages = mb.messages[1:]
+	}
+	return nil
+}
+
+// Messages retrieves the last published messages from the oldest to the newest
+func (mb *memoryBroker) Messages() []Message {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	return append([]Message(nil), mb.messages...)
+}
+
+// Close implement the interface Broker.Close
+func (mb *memoryBroker) Close() error {
+	return nil
+}
+
+// fileBroker struct that implement the Broker interface appending the messages as json lines to a file
+type fileBroker struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileBroker creates a new pointer of fileBroker struct
+func NewFileBroker(path string) *fileBroker {
+	return &fileBroker{
+		path: path,
+	}
+}
+
+// Publish implement the interface Broker.Publish, the line is synced before the message is acknowledged
+func (fb *fileBroker) Publish(_ context.Context, msg Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		PublishedAt time.Time `json:"publishedAt"`
+	}{msg, time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	f, err := os.OpenFile(fb.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open events file %s, %w", fb.path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write events file %s, %w", fb.path, err)
+	}
+	return f.Sync()
+}
+
+// Close implement the interface Broker.Close
+func (fb *fileBroker) Close() error {
+	return nil
+}
+
+// fanoutBroker struct that implement the Broker interface publishing the messages to several brokers in order
+type fanoutBroker struct {
+	brokers []Broker
+}
+
+// NewFanoutBroker creates a new pointer of fanoutBroker struct, the nil brokers are ignored
+func NewFanoutBroker(brokers ...Broker) *fanoutBroker {
+	fb := &fanoutBroker{}
+	for _, broker := range brokers {
+		if broker != nil {
+			fb.brokers = append(fb.brokers, broker)
+		}
+	}
+	return fb
+}
+
+// Publish implement the interface Broker.Publish, the message is not published to the next brokers when a broker
+// fails, so the relay retries it from the first broker and the brokers before it receive the message again; only
+// the memory broker ignores the messages with a known id, the file broker appends them again and the webhooks
+// post them again, so the consumers dedupe them by id
+func (fb *fanoutBroker) Publish(ctx context.Context, msg Message) error {
+	for _, broker := range fb.brokers {
+		if err := broker.Publish(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implement the interface Broker.Close, all the brokers are closed
+func (fb *fanoutBroker) Close() error {
+	var errs []error
+	for _, broker := range fb.brokers {
+		errs = append(errs, broker.Close())
+	}
+	return errors.Join(errs...)
+}
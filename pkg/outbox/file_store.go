@@ -0,0 +1,267 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/pii"
+)
+
+// record struct that represents a line of the journal, a decision with its events or the ids of published events;
+// the applicant data of the decision is sealed when the store has a keyring
+type record struct {
+	Decision  *model.Decision `json:"decision,omitempty"`
+	Events    []model.Event   `json:"events,omitempty"`
+	Published []string        `json:"published,omitempty"`
+	Sealed    *model.Envelope `json:"sealed,omitempty"`
+}
+
+// sealedFields struct with the applicant data of a decision encrypted in the journal
+type sealedFields struct {
+	IP                  string  `json:"ip"`
+	RequestedCreditLine float64 `json:"requestedCreditLine"`
+	ApplicantID         string  `json:"applicantId,omitempty"`
+}
+
+// fileStore struct that implement the Store interface with a journal file, each decision is written with its
+// events in a single synced line so a crash can not store a decision without its events
+type fileStore struct {
+	*memoryStore
+	path    string
+	file    *os.File
+	size    int64
+	keyring *pii.Keyring
+	// uncompacted reports that the journal still has expired decisions removed from memory
+	uncompacted bool
+}
+
+// NewFileStore opens the journal file and replays it to retrieve the decisions and the pending events, a last
+// line torn by a crash is discarded; the applicant data is encrypted with the keyring when it is not nil
+func NewFileStore(path string, keyring *pii.Keyring) (*fileStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox file %s, %w", path, err)
+	}
+	fs := &fileStore{memoryStore: NewMemoryStore(), path: path, file: f, keyring: keyring}
+	if err := fs.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Save implement the interface Store.Save, the decision is only stored in memory when the line was synced
+func (fs *fileStore) Save(decision *model.Decision, events []model.Event) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	r, err := fs.seal(record{Decision: decision, Events: events})
+	if err != nil {
+		return fmt.Errorf("failed to encrypt the decision, %w", err)
+	}
+	if err := fs.append(r); err != nil {
+		return err
+	}
+	fs.save(decision, events)
+	return nil
+}
+
+// MarkPublished implement the interface Store.MarkPublished
+func (fs *fileStore) MarkPublished(ids []string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := fs.append(record{Published: ids}); err != nil {
+		return err
+	}
+	fs.markPublished(ids)
+	return nil
+}
+
+// Expire implement the interface Store.Expire, the journal is compacted with the remaining decisions and their
+// pending events so the expired applicant data is removed from the file
+func (fs *fileStore) Expire(before time.Time, mode model.RetentionMode, dryRun bool) (model.RetentionCounts, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	counts, changed := fs.expire(before, mode, dryRun)
+	if dryRun || (!changed && !fs.uncompacted) {
+		return counts, nil
+	}
+	// the expired decisions were already removed from memory, a failed compaction is retried in the next run
+	fs.uncompacted = true
+	if err := fs.compact(); err != nil {
+		return counts, err
+	}
+	fs.uncompacted = false
+	return counts, nil
+}
+
+// Check implement the interface Store.Check, the store is reachable when it answers and its journal file is open
+// and writable
+func (fs *fileStore) Check(ctx context.Context) error {
+	if err := fs.memoryStore.Check(ctx); err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, err := fs.file.Stat(); err != nil {
+		return fmt.Errorf("outbox file %s is not open, %w", fs.path, err)
+	}
+	f, err := os.OpenFile(fs.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("outbox file %s is not writable, %w", fs.path, err)
+	}
+	return f.Close()
+}
+
+// Close implement the interface Store.Close
+func (fs *fileStore) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.file.Close()
+}
+
+// append writes and syncs a line of the journal, a partial line is truncated so the next line is not corrupted
+func (fs *fileStore) append(r record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := fs.file.WriteAt(append(line, '\n'), fs.size); err != nil {
+		_ = fs.file.Truncate(fs.size)
+		return fmt.Errorf("failed to write outbox file %s, %w", fs.path, err)
+	}
+	if err := fs.file.Sync(); err != nil {
+		_ = fs.file.Truncate(fs.size)
+		return fmt.Errorf("failed to sync outbox file %s, %w", fs.path, err)
+	}
+	fs.size += int64(len(line) + 1)
+	return nil
+}
+
+// compact replaces the journal with the records of the current decisions and reopens it, the caller must hold
+// the lock
+func (fs *fileStore) compact() error {
+	var content bytes.Buffer
+	for _, r := range fs.snapshot() {
+		sealed, err := fs.seal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt the decision, %w", err)
+		}
+		line, err := json.Marshal(sealed)
+		if err != nil {
+			return err
+		}
+		content.Write(line)
+		content.WriteByte('\n')
+	}
+	if err := pii.WriteFileAtomic(fs.path, content.Bytes()); err != nil {
+		return fmt.Errorf("failed to compact outbox file %s, %w", fs.path, err)
+	}
+
+	f, err := os.OpenFile(fs.path, os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox file %s, %w", fs.path, err)
+	}
+	fs.file.Close()
+	fs.file, fs.size = f, int64(content.Len())
+	return nil
+}
+
+// replay applies the lines of the journal, the file is truncated after the last complete line
+func (fs *fileStore) replay() error {
+	reader := bufio.NewReader(fs.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a line without the new line character was torn by a crash while it was written
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read outbox file %s, %w", fs.path, err)
+		}
+
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return fmt.Errorf("corrupted outbox file %s at offset %d, %w", fs.path, fs.size, err)
+		}
+		if err := fs.open(&r); err != nil {
+			return fmt.Errorf("failed to decrypt outbox file %s at offset %d, %w", fs.path, fs.size, err)
+		}
+		if r.Decision != nil {
+			fs.save(r.Decision, r.Events)
+		}
+		fs.markPublished(r.Published)
+		fs.size += int64(len(line))
+	}
+	return fs.file.Truncate(fs.size)
+}
+
+// seal retrieves a copy of a decision record with its applicant data encrypted, the record is not changed
+// without keyring
+func (fs *fileStore) seal(r record) (record, error) {
+	if fs.keyring == nil {
+		return r, nil
+	}
+	sealed, err := fs.keyring.Seal(sealedFields{IP: r.Decision.IP, RequestedCreditLine: r.Decision.RequestedCreditLine,
+		ApplicantID: r.Decision.ApplicantID}, r.Decision.ID)
+	if err != nil {
+		return record{}, err
+	}
+
+	decision := *r.Decision
+	decision.IP, decision.RequestedCreditLine, decision.ApplicantID = "", 0, ""
+	events := make([]model.Event, 0, len(r.Events))
+	for _, event := range r.Events {
+		event.Data.RequestedCreditLine = 0
+		events = append(events, event)
+	}
+	return record{Decision: &decision, Events: events, Sealed: sealed}, nil
+}
+
+// open restores the applicant data of a sealed record
+func (fs *fileStore) open(r *record) error {
+	if r.Sealed == nil || r.Decision == nil {
+		return nil
+	}
+	if fs.keyring == nil {
+		return errors.New("the record is encrypted and the keyring is not configured")
+	}
+	var fields sealedFields
+	if err := fs.keyring.Open(r.Sealed, &fields, r.Decision.ID); err != nil {
+		return err
+	}
+	r.Decision.IP, r.Decision.RequestedCreditLine = fields.IP, fields.RequestedCreditLine
+	r.Decision.ApplicantID = fields.ApplicantID
+	for i := range r.Events {
+		r.Events[i].Data.RequestedCreditLine = fields.RequestedCreditLine
+	}
+	return nil
+}
+
+// RewrapFile encrypts again with the primary key of the keyring the data keys of the sealed records of a journal
+// file, it retrieves the number of records encrypted again; the application must be stopped
+func RewrapFile(path string, keyring *pii.Keyring) (int, error) {
+	return pii.RewriteLines(path, func(line []byte) ([]byte, bool, error) {
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, false, err
+		}
+		if r.Sealed == nil {
+			return nil, false, nil
+		}
+		sealed, ok, err := keyring.Rewrap(r.Sealed)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		r.Sealed = sealed
+		rewritten, err := json.Marshal(r)
+		return rewritten, true, err
+	})
+}
@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// natsDialTimeout max time to connect to the NATS server
+	natsDialTimeout = 5 * time.Second
+	// natsAckTimeout max time to wait the PONG that confirms the published messages
+	natsAckTimeout = 5 * time.Second
+	// natsMsgIDHeader header used by the NATS server to dedupe the messages
+	natsMsgIDHeader = "Nats-Msg-Id"
+)
+
+// natsInfo struct with the INFO values of the NATS server used by the broker
+type natsInfo struct {
+	Headers bool `json:"headers"`
+}
+
+// natsBroker struct that implement the Broker interface with the NATS text protocol, each message is confirmed
+// with a PING so a message is only acknowledged when the server processed it
+type natsBroker struct {
+	mu       sync.Mutex
+	addr     string
+	user     *url.Userinfo
+	conn     net.Conn
+	reader   *bufio.Reader
+	headers  bool
+	dialer   net.Dialer
+	deadline time.Duration
+}
+
+// NewNatsBroker creates a new pointer of natsBroker struct from a nats://[user:password@]host:port url, the
+// connection is opened with the first message
+func NewNatsBroker(rawURL string) (*natsBroker, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("invalid nats url %q", rawURL)
+	}
+	return &natsBroker{
+		addr:     u.Host,
+		user:     u.User,
+		dialer:   net.Dialer{Timeout: natsDialTimeout},
+		deadline: natsAckTimeout,
+	}, nil
+}
+
+// Publish implement the interface Broker.Publish, the id is sent in the Nats-Msg-Id header when the server
+// supports headers; the connection is closed after an error and opened again with the next message
+func (nb *natsBroker) Publish(ctx context.Context, msg Message) error {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+	if err := nb.connect(ctx); err != nil {
+		return err
+	}
+	if err := nb.publish(msg); err != nil {
+		nb.close()
+		return err
+	}
+	return nil
+}
+
+// Close implement the interface Broker.Close
+func (nb *natsBroker) Close() error {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+	return nb.close()
+}
+
+// connect opens the connection and sends the CONNECT message when the broker is not connected
+func (nb *natsBroker) connect(ctx context.Context) error {
+	if nb.conn != nil {
+		return nil
+	}
+	conn, err := nb.dialer.DialContext(ctx, "tcp", nb.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats %s, %w", nb.addr, err)
+	}
+	nb.conn, nb.reader = conn, bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(nb.deadline))
+
+	line, err := nb.reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		nb.close()
+		return fmt.Errorf("unexpected nats greeting %q, %v", strings.TrimSpace(line), err)
+	}
+	var info natsInfo
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "INFO ")), &info)
+	nb.headers = info.Headers
+
+	options := map[string]interface{}{"verbose": false, "pedantic": false, "headers": info.Headers,
+		"name": "credit-line-api", "lang": "go"}
+	if nb.user != nil {
+		options["user"] = nb.user.Username()
+		if password, ok := nb.user.Password(); ok {
+			options["pass"] = password
+		}
+	}
+	connect, _ := json.Marshal(options)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		nb.close()
+		return fmt.Errorf("failed to connect to nats %s, %w", nb.addr, err)
+	}
+	return nil
+}
+
+// publish writes the message and waits the PONG of a PING, the server answers the PING after processing the message
+func (nb *natsBroker) publish(msg Message) error {
+	_ = nb.conn.SetDeadline(time.Now().Add(nb.deadline))
+	var err error
+	if nb.headers {
+		headers := fmt.Sprintf("NATS/1.0\r\n%s: %s\r\n\r\n", natsMsgIDHeader, msg.ID)
+		_, err = fmt.Fprintf(nb.conn, "HPUB %s %d %d\r\n%s%s\r\nPING\r\n", msg.Subject, len(headers),
+			len(headers)+len(msg.Payload), headers, msg.Payload)
+	} else {
+		_, err = fmt.Fprintf(nb.conn, "PUB %s %d\r\n%s\r\nPING\r\n", msg.Subject, len(msg.Payload), msg.Payload)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish to nats, %w", err)
+	}
+
+	for {
+		line, err := nb.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to confirm the nats message, %w", err)
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := nb.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("failed to answer the nats ping, %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// close closes the connection, the caller must hold the lock
+func (nb *natsBroker) close() error {
+	if nb.conn == nil {
+		return nil
+	}
+	err := nb.conn.Close()
+	nb.conn, nb.reader = nil, nil
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
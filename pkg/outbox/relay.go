@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Relay struct that publishes the pending events of the store to the broker in the order they were saved, an event
+// is marked as published after the broker acknowledged it, so the events are published at least once and the
+// consumers dedupe them by id
+type Relay struct {
+	store   Store
+	broker  Broker
+	subject string
+	batch   int
+}
+
+// NewRelay creates a new pointer of Relay struct, the events are published in subject.<event type>
+func NewRelay(store Store, broker Broker, subject string, batch int) *Relay {
+	return &Relay{
+		store:   store,
+		broker:  broker,
+		subject: subject,
+		batch:   batch,
+	}
+}
+
+// Flush publishes a batch of pending events, it stops at the first failure to keep the order of the events and
+// retrieves the number of published events
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := make([]string, 0, r.batch)
+	var publishErr error
+	for _, pending := range r.store.Pending(r.batch) {
+		payload, err := json.Marshal(pending.Event)
+		if err != nil {
+			publishErr = err
+			break
+		}
+		msg := Message{ID: pending.Event.ID, Subject: r.subject + "." + string(pending.Event.Type), Payload: payload}
+		if err := r.broker.Publish(ctx, msg); err != nil {
+			r.store.MarkFailed(pending.Event.ID, err)
+			publishErr = fmt.Errorf("failed to publish the event %s, %w", pending.Event.ID, err)
+			break
+		}
+		published = append(published, pending.Event.ID)
+	}
+
+	if len(published) > 0 {
+		if err := r.store.MarkPublished(published); err != nil {
+			return 0, fmt.Errorf("failed to mark the published events, %w", err)
+		}
+	}
+	return len(published), publishErr
+}
+
+// Run flushes the pending events every interval until the context is done, the pending events are flushed
+// before returning
+func (r *Relay) Run(ctx context.Context, l *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), interval+natsAckTimeout)
+			r.flushAll(flushCtx, l)
+			cancel()
+			return
+		case <-ticker.C:
+			r.flushAll(ctx, l)
+		}
+	}
+}
+
+// flushAll flushes batches until there are no pending events or a batch fails
+func (r *Relay) flushAll(ctx context.Context, l *slog.Logger) {
+	for {
+		n, err := r.Flush(ctx)
+		if err != nil {
+			l.Warn("outbox relay could not publish the events", "published", n, "error", err)
+			return
+		}
+		if n < r.batch {
+			return
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+	"credit-line/pkg/env"
+	"credit-line/pkg/pii"
+)
+
+const (
+	// MemoryStore store that keeps the decisions and the pending events in memory
+	MemoryStore = "memory"
+	// FileStore store that appends the decisions and the published events to a journal file
+	FileStore = "file"
+)
+
+// Store contract to store the decisions atomically with their events and to track the events pending to be published
+type Store interface {
+	Save(decision *model.Decision, events []model.Event) error
+	Pending(limit int) []model.OutboxEvent
+	MarkPublished(ids []string) error
+	MarkFailed(id string, err error)
+	Decision(id string) (*model.Decision, bool)
+	Expire(before time.Time, mode model.RetentionMode, dryRun bool) (model.RetentionCounts, error)
+	Check(ctx context.Context) error
+	Close() error
+}
+
+// NewStore builds the decision store of the outbox values, the keyring encrypts the applicant data of the stores
+// that write to a file and it can be nil
+func NewStore(cfg *env.Outbox, keyring *pii.Keyring) (Store, error) {
+	switch cfg.Store {
+	case MemoryStore:
+		return NewMemoryStore(), nil
+	case FileStore:
+		return NewFileStore(cfg.FilePath, keyring)
+	default:
+		return nil, fmt.Errorf("unknown outbox store %q", cfg.Store)
+	}
+}
+
+// memoryStore struct that implement the Store interface in memory
+type memoryStore struct {
+	mu        sync.Mutex
+	decisions map[string]*model.Decision
+	events    map[string]*model.OutboxEvent
+	pending   []string
+}
+
+// NewMemoryStore creates a new pointer of memoryStore struct without decisions
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		decisions: make(map[string]*model.Decision),
+		events:    make(map[string]*model.OutboxEvent),
+	}
+}
+
+// Save implement the interface Store.Save
+func (ms *memoryStore) Save(decision *model.Decision, events []model.Event) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.save(decision, events)
+	return nil
+}
+
+// Pending implement the interface Store.Pending, the events are retrieved in the order they were saved
+func (ms *memoryStore) Pending(limit int) []model.OutboxEvent {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if limit > len(ms.pending) {
+		limit = len(ms.pending)
+	}
+	pending := make([]model.OutboxEvent, 0, limit)
+	for _, id := range ms.pending[:limit] {
+		pending = append(pending, *ms.events[id])
+	}
+	return pending
+}
+
+// MarkPublished implement the interface Store.MarkPublished
+func (ms *memoryStore) MarkPublished(ids []string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.markPublished(ids)
+	return nil
+}
+
+// MarkFailed implement the interface Store.MarkFailed, the attempts are not persisted
+func (ms *memoryStore) MarkFailed(id string, err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if event, ok := ms.events[id]; ok {
+		event.Attempts++
+		event.LastError = err.Error()
+	}
+}
+
+// Decision implement the interface Store.Decision
+func (ms *memoryStore) Decision(id string) (*model.Decision, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	decision, ok := ms.decisions[id]
+	if !ok {
+		return nil, false
+	}
+	stored := *decision
+	return &stored, true
+}
+
+// Expire implement the interface Store.Expire
+func (ms *memoryStore) Expire(before time.Time, mode model.RetentionMode, dryRun bool) (model.RetentionCounts, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	counts, _ := ms.expire(before, mode, dryRun)
+	return counts, nil
+}
+
+// Check implement the interface Store.Check, the store is reachable when its lock is acquired before the context
+// is done
+func (ms *memoryStore) Check(ctx context.Context) error {
+	answered := make(chan struct{})
+	go func() {
+		ms.mu.Lock()
+		defer ms.mu.Unlock()
+		close(answered)
+	}()
+	select {
+	case <-answered:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the decision store did not answer, %w", ctx.Err())
+	}
+}
+
+// Close implement the interface Store.Close
+func (ms *memoryStore) Close() error {
+	return nil
+}
+
+// save stores a decision and its pending events, the caller must hold the lock
+func (ms *memoryStore) save(decision *model.Decision, events []model.Event) {
+	stored := *decision
+	ms.decisions[decision.ID] = &stored
+	for _, event := range events {
+		ms.events[event.ID] = &model.OutboxEvent{Event: event}
+		ms.pending = append(ms.pending, event.ID)
+	}
+}
+
+// expire purges or anonymizes the decisions determined before a time together with their pending events, the
+// retention prevails over the publication so the applicant data of an event that was not published in time is
+// not kept; it reports if a decision changed, the caller must hold the lock
+func (ms *memoryStore) expire(before time.Time, mode model.RetentionMode, dryRun bool) (model.RetentionCounts, bool) {
+	pending := make(map[string][]string, len(ms.pending))
+	for _, id := range ms.pending {
+		decisionID := ms.events[id].Event.DecisionID
+		pending[decisionID] = append(pending[decisionID], id)
+	}
+
+	var counts model.RetentionCounts
+	for id, decision := range ms.decisions {
+		if !decision.DeterminedAt.Before(before) {
+			continue
+		}
+		counts.Expired++
+		switch {
+		case mode == model.RetentionPurge:
+			counts.Purged++
+			if !dryRun {
+				delete(ms.decisions, id)
+				// the events that were not published are dropped with their decision
+				ms.markPublished(pending[id])
+			}
+		case decision.IP != "" || decision.RequestedCreditLine != 0 || decision.ApplicantID != "" ||
+			ms.identified(pending[id]):
+			counts.Anonymized++
+			if !dryRun {
+				decision.IP, decision.RequestedCreditLine, decision.ApplicantID = "", 0, ""
+				for _, eventID := range pending[id] {
+					ms.events[eventID].Event.Data.RequestedCreditLine = 0
+				}
+			}
+		}
+	}
+	return counts, !dryRun && counts.Purged+counts.Anonymized > 0
+}
+
+// identified reports if a pending event keeps the applicant data of its decision, the caller must hold the lock
+func (ms *memoryStore) identified(ids []string) bool {
+	for _, id := range ids {
+		if ms.events[id].Event.Data.RequestedCreditLine != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot retrieves the records of the current decisions with their pending events, from the oldest decision
+// to the newest; the caller must hold the lock
+func (ms *memoryStore) snapshot() []record {
+	events := make(map[string][]model.Event, len(ms.pending))
+	for _, id := range ms.pending {
+		event := ms.events[id].Event
+		events[event.DecisionID] = append(events[event.DecisionID], event)
+	}
+
+	records := make([]record, 0, len(ms.decisions))
+	for id, decision := range ms.decisions {
+		stored := *decision
+		records = append(records, record{Decision: &stored, Events: events[id]})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Decision.DeterminedAt.Equal(records[j].Decision.DeterminedAt) {
+			return records[i].Decision.ID < records[j].Decision.ID
+		}
+		return records[i].Decision.DeterminedAt.Before(records[j].Decision.DeterminedAt)
+	})
+	return records
+}
+
+// markPublished removes the published events of the pending events, the caller must hold the lock
+func (ms *memoryStore) markPublished(ids []string) {
+	published := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		published[id] = true
+		delete(ms.events, id)
+	}
+	pending := ms.pending[:0]
+	for _, id := range ms.pending {
+		if !published[id] {
+			pending = append(pending, id)
+		}
+	}
+	ms.pending = pending
+}
@@ -0,0 +1,281 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+// keySize size of the keys of the keyring and of the data keys, AES-256
+const keySize = 32
+
+var (
+	// ErrUnknownKey is returned when an envelope was encrypted with a key that is not in the keyring
+	ErrUnknownKey = errors.New("unknown keyring key")
+	// ErrShredded is returned when the data key of an envelope was removed by the retention
+	ErrShredded = errors.New("the data key of the envelope was shredded")
+)
+
+// Key struct that represents a key of the keyring file
+type Key struct {
+	ID        string    `json:"id"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// keyringFile struct that represents the keyring file, the primary key encrypts the new data keys and the other
+// keys are kept to decrypt the data keys encrypted before a rotation
+type keyringFile struct {
+	Primary string `json:"primary"`
+	Keys    []Key  `json:"keys"`
+}
+
+// Keyring struct with the keys that encrypt the data keys of the envelopes
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// LoadKeyring reads the keyring file
+func LoadKeyring(path string) (*Keyring, error) {
+	file, err := readKeyringFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyring(path, file)
+}
+
+// RotateKeyring adds a new primary key to the keyring file, the file is created when it does not exist; the
+// previous keys are kept so the data keys encrypted with them can be decrypted until they are encrypted again
+func RotateKeyring(path string) (*Keyring, error) {
+	file, err := readKeyringFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		file = &keyringFile{}
+	case err != nil:
+		return nil, err
+	}
+
+	secret := make([]byte, keySize)
+	id := make([]byte, 4)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	key := Key{ID: hex.EncodeToString(id), Secret: base64.StdEncoding.EncodeToString(secret), CreatedAt: time.Now().UTC()}
+	file.Keys = append(file.Keys, key)
+	file.Primary = key.ID
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteFileAtomic(path, append(content, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write keyring %s, %w", path, err)
+	}
+	return newKeyring(path, file)
+}
+
+// Primary retrieves the id of the key that encrypts the new data keys
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Seal encrypts the json of v with a new data key, aad binds the envelope to its record so it can not be moved
+// to another record
+func (k *Keyring) Seal(v interface{}, aad string) (*model.Envelope, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(data, plaintext, []byte(aad))
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	if err != nil {
+		return nil, err
+	}
+	return &model.Envelope{
+		KeyID:      k.primary,
+		DataKey:    base64.StdEncoding.EncodeToString(wrapped),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// Open decrypts an envelope in v, aad must be the value used to seal it
+func (k *Keyring) Open(e *model.Envelope, v interface{}, aad string) error {
+	dataKey, err := k.dataKey(e)
+	if err != nil {
+		return err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(e.Ciphertext)
+	if err != nil {
+		return fmt.Errorf("invalid envelope ciphertext, %w", err)
+	}
+	plaintext, err := open(data, ciphertext, []byte(aad))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt envelope, %w", err)
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// Rewrap encrypts again the data key of an envelope with the primary key, the ciphertext is not changed; it
+// retrieves false when the envelope already uses the primary key or its data key was shredded
+func (k *Keyring) Rewrap(e *model.Envelope) (*model.Envelope, bool, error) {
+	if e.KeyID == k.primary || Shredded(e) {
+		return e, false, nil
+	}
+	dataKey, err := k.dataKey(e)
+	if err != nil {
+		return nil, false, err
+	}
+	wrapped, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	if err != nil {
+		return nil, false, err
+	}
+	return &model.Envelope{
+		KeyID:      k.primary,
+		DataKey:    base64.StdEncoding.EncodeToString(wrapped),
+		Ciphertext: e.Ciphertext,
+	}, true, nil
+}
+
+// Shred retrieves a copy of an envelope without its data key, the ciphertext can not be decrypted anymore
+func Shred(e *model.Envelope) *model.Envelope {
+	return &model.Envelope{KeyID: e.KeyID, Ciphertext: e.Ciphertext}
+}
+
+// Shredded reports if the data key of an envelope was removed
+func Shredded(e *model.Envelope) bool {
+	return e.DataKey == ""
+}
+
+// dataKey decrypts the data key of an envelope with its keyring key
+func (k *Keyring) dataKey(e *model.Envelope) ([]byte, error) {
+	if Shredded(e) {
+		return nil, ErrShredded
+	}
+	key, ok := k.keys[e.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, e.KeyID)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(e.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid envelope data key, %w", err)
+	}
+	dataKey, err := open(key, wrapped, []byte(e.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the data key, %w", err)
+	}
+	return dataKey, nil
+}
+
+// readKeyringFile reads and decodes the keyring file
+func readKeyringFile(path string) (*keyringFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring %s, %w", path, err)
+	}
+	var file keyringFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid keyring %s, %w", path, err)
+	}
+	return &file, nil
+}
+
+// newKeyring builds the keyring of the keys of a keyring file
+func newKeyring(path string, file *keyringFile) (*Keyring, error) {
+	k := &Keyring{primary: file.Primary, keys: make(map[string]cipher.AEAD, len(file.Keys))}
+	for _, key := range file.Keys {
+		secret, err := base64.StdEncoding.DecodeString(key.Secret)
+		if err != nil || len(secret) != keySize {
+			return nil, fmt.Errorf("invalid keyring %s, the key %s must be %d base64 bytes", path, key.ID, keySize)
+		}
+		aead, err := newAEAD(secret)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[key.ID] = aead
+	}
+	if _, ok := k.keys[k.primary]; !ok {
+		return nil, fmt.Errorf("invalid keyring %s, the primary key %q is not in the keys", path, k.primary)
+	}
+	return k, nil
+}
+
+// newAEAD creates the AES-256-GCM cipher of a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext with a random nonce that prefixes the ciphertext
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decrypts a ciphertext prefixed with its nonce
+func open(aead cipher.AEAD, ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], aad)
+}
+
+// WriteFileAtomic writes a file in a synced temporary file of the same directory that replaces it, so a crash
+// does not leave a partial file
+func WriteFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
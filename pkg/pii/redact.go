@@ -0,0 +1,99 @@
+package pii
+
+import (
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Redacted value that replaces the applicant data in the logs and the error messages
+const Redacted = "[REDACTED]"
+
+// sensitiveFields names of the fields with applicant data, normalized without case, dashes and underscores
+var sensitiveFields = map[string]bool{
+	"cashbalance":            true,
+	"monthlyrevenue":         true,
+	"requestedcreditline":    true,
+	"contact":                true,
+	"name":                   true,
+	"email":                  true,
+	"phone":                  true,
+	"applicant":              true,
+	"taxid":                  true,
+	"legalname":              true,
+	"certificatesubject":     true,
+	"certificatefingerprint": true,
+}
+
+// ipFields names of the fields with ip addresses, normalized as the sensitive fields
+var ipFields = map[string]bool{
+	"ip":       true,
+	"clientip": true,
+	"realip":   true,
+}
+
+var (
+	// emailPattern matches the email addresses of a text
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// phonePattern matches the E.164 phone numbers of a text
+	phonePattern = regexp.MustCompile(`\+[1-9][0-9]{7,14}\b`)
+	// ipPattern matches the candidates to ip addresses of a text, they are checked with net.ParseIP
+	ipPattern = regexp.MustCompile(`\b[0-9]{1,3}(\.[0-9]{1,3}){3}\b|[0-9A-Fa-f]{0,4}(:[0-9A-Fa-f]{0,4}){2,7}`)
+)
+
+// IsSensitive reports if a field has applicant data, the field can be a json path such as contact.email
+func IsSensitive(field string) bool {
+	name := normalize(field)
+	return sensitiveFields[name] || ipFields[name]
+}
+
+// MaskIP retrieves the network of an ip without the host part, a /24 network for IPv4 and a /48 network for IPv6
+func MaskIP(value string) string {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return Redacted
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// RedactText replaces the email addresses and the phone numbers of a text and masks its ip addresses
+func RedactText(text string) string {
+	text = emailPattern.ReplaceAllString(text, Redacted)
+	text = phonePattern.ReplaceAllString(text, Redacted)
+	return ipPattern.ReplaceAllStringFunc(text, func(candidate string) string {
+		if net.ParseIP(candidate) == nil {
+			return candidate
+		}
+		return MaskIP(candidate)
+	})
+}
+
+// RedactAttr slog ReplaceAttr function that redacts the attributes with applicant data, the ip attributes are
+// masked and the text of the string and error attributes is redacted
+func RedactAttr(_ []string, a slog.Attr) slog.Attr {
+	name := normalize(a.Key)
+	switch {
+	case ipFields[name]:
+		return slog.String(a.Key, MaskIP(a.Value.String()))
+	case sensitiveFields[name]:
+		return slog.String(a.Key, Redacted)
+	case a.Value.Kind() == slog.KindString:
+		return slog.String(a.Key, RedactText(a.Value.String()))
+	}
+	if err, ok := a.Value.Any().(error); ok {
+		return slog.String(a.Key, RedactText(err.Error()))
+	}
+	return a
+}
+
+// normalize retrieves the last element of a field path without case, dashes and underscores
+func normalize(field string) string {
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(field))
+}
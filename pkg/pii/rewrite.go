@@ -0,0 +1,55 @@
+package pii
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RewriteLines rewrites the lines of a json lines file with rewrite, the file is replaced only when a line
+// changed; it retrieves the number of changed lines. The application must be stopped because the file is replaced
+func RewriteLines(path string, rewrite func(line []byte) ([]byte, bool, error)) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var content bytes.Buffer
+	changed := 0
+	reader := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a line without the new line character was torn by a crash and it is kept as it is
+			content.Write(line)
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		rewritten, ok, err := rewrite(bytes.TrimSuffix(line, []byte("\n")))
+		if err != nil {
+			return 0, fmt.Errorf("failed to rewrite the line %d of %s, %w", n, path, err)
+		}
+		if !ok {
+			content.Write(line)
+			continue
+		}
+		content.Write(rewritten)
+		content.WriteByte('\n')
+		changed++
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+	if err := WriteFileAtomic(path, content.Bytes()); err != nil {
+		return 0, fmt.Errorf("failed to write %s, %w", path, err)
+	}
+	return changed, nil
+}
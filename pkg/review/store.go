@@ -0,0 +1,95 @@
+package review
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+// Store struct with the reviews of the manual review queue
+type Store struct {
+	mu      sync.RWMutex
+	reviews map[string]*model.Review
+}
+
+// NewStore creates a new pointer of Store struct without reviews
+func NewStore() *Store {
+	return &Store{
+		reviews: make(map[string]*model.Review),
+	}
+}
+
+// Save stores a copy of a review
+func (s *Store) Save(review *model.Review) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reviews[review.ID] = clone(review)
+}
+
+// Update applies a change to the stored review under the lock of the store, so the change keeps the breach
+// marked since the review was read; it retrieves a copy of the updated review, false when it does not exist
+func (s *Store) Update(id string, update func(review *model.Review)) (*model.Review, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	review, ok := s.reviews[id]
+	if !ok {
+		return nil, false
+	}
+	updated := clone(review)
+	update(updated)
+	s.reviews[id] = updated
+	return clone(updated), true
+}
+
+// Review retrieves a copy of a review, false when the review does not exist
+func (s *Store) Review(id string) (*model.Review, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	review, ok := s.reviews[id]
+	if !ok {
+		return nil, false
+	}
+	return clone(review), true
+}
+
+// Reviews retrieves a copy of the reviews, the first due first
+func (s *Store) Reviews() []*model.Review {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reviews := make([]*model.Review, 0, len(s.reviews))
+	for _, review := range s.reviews {
+		reviews = append(reviews, clone(review))
+	}
+	sort.Slice(reviews, func(i, j int) bool {
+		if reviews[i].DueAt.Equal(reviews[j].DueAt) {
+			return reviews[i].ID < reviews[j].ID
+		}
+		return reviews[i].DueAt.Before(reviews[j].DueAt)
+	})
+	return reviews
+}
+
+// Breach marks the reviews that are overdue at a time and were not marked yet, it retrieves a copy of them
+func (s *Store) Breach(now time.Time) []*model.Review {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var breached []*model.Review
+	for _, review := range s.reviews {
+		if review.BreachedAt != nil || !review.Overdue(now) {
+			continue
+		}
+		at := now
+		review.BreachedAt = &at
+		breached = append(breached, clone(review))
+	}
+	return breached
+}
+
+// clone retrieves a copy of a review that does not share its notes
+func clone(review *model.Review) *model.Review {
+	cloned := *review
+	cloned.Notes = append([]model.ReviewNote(nil), review.Notes...)
+	return &cloned
+}
@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"credit-line/internal/model"
+)
+
+// weights points that each signal adds to the score of a request
+var weights = map[model.RiskSignal]uint{
+	model.VelocitySignal:    40,
+	model.ProbingSignal:     50,
+	model.ImplausibleSignal: 30,
+}
+
+// Velocity reports if the requests of an identity or ip in the velocity window exceed the limit, a limit of 0
+// disables the signal
+func Velocity(requests []model.RiskRequest, limit uint) bool {
+	return limit > 0 && uint(len(requests)) > limit
+}
+
+// Probing reports if the cash balance or the monthly revenue increased in each of the last steps requests of an
+// identity or ip, the current request included; fewer than 2 steps disable the signal
+func Probing(requests []model.RiskRequest, steps uint) bool {
+	if steps < 2 || uint(len(requests)) < steps {
+		return false
+	}
+	last := requests[uint(len(requests))-steps:]
+	return increasing(last, func(r model.RiskRequest) float64 { return r.CashBalance }) ||
+		increasing(last, func(r model.RiskRequest) float64 { return r.MonthlyRevenue })
+}
+
+// Implausible reports if the monthly revenue is more than ratio times the cash balance, the balances under 1 are
+// compared as 1 and a ratio of 0 disables the signal
+func Implausible(cashBalance, monthlyRevenue, ratio float64) bool {
+	if ratio <= 0 {
+		return false
+	}
+	if cashBalance < 1 {
+		cashBalance = 1
+	}
+	return monthlyRevenue > cashBalance*ratio
+}
+
+// Score retrieves the score of the signals of a request
+func Score(signals []model.RiskSignal) uint {
+	var score uint
+	for _, signal := range signals {
+		score += weights[signal]
+	}
+	return score
+}
+
+// increasing reports if a value of the requests is strictly increasing
+func increasing(requests []model.RiskRequest, value func(model.RiskRequest) float64) bool {
+	for i := 1; i < len(requests); i++ {
+		if value(requests[i]) <= value(requests[i-1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package risk
+
+import (
+	"sync"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+// request struct with a request of an identity or ip and the time it was received
+type request struct {
+	model.RiskRequest
+	at time.Time
+}
+
+// keyed struct with the key of a request, in the order of the requests
+type keyed struct {
+	key string
+	at  time.Time
+}
+
+// Store struct with the recent requests of the identities and ips, only the inputs scored by the signals are kept
+type Store struct {
+	mu       sync.Mutex
+	requests map[string][]request
+	order    []keyed
+}
+
+// NewStore creates a new pointer of Store struct without requests
+func NewStore() *Store {
+	return &Store{
+		requests: make(map[string][]request),
+	}
+}
+
+// Record stores a request of a key and retrieves the requests of the key since a time in the order they were
+// received, the current request included; the older requests are removed
+func (s *Store) Record(key string, current model.RiskRequest, at, since time.Time) []model.RiskRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(since)
+
+	s.requests[key] = append(s.requests[key], request{RiskRequest: current, at: at})
+	s.order = append(s.order, keyed{key: key, at: at})
+
+	requests := make([]model.RiskRequest, 0, len(s.requests[key]))
+	for _, previous := range s.requests[key] {
+		if !previous.at.Before(since) {
+			requests = append(requests, previous.RiskRequest)
+		}
+	}
+	return requests
+}
+
+// expire removes the requests before a time, the caller must hold the lock
+func (s *Store) expire(before time.Time) {
+	n := 0
+	for ; n < len(s.order) && s.order[n].at.Before(before); n++ {
+		key := s.order[n].key
+		kept := s.requests[key][:0]
+		for _, previous := range s.requests[key] {
+			if !previous.at.Before(before) {
+				kept = append(kept, previous)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.requests, key)
+			continue
+		}
+		s.requests[key] = kept
+	}
+	s.order = s.order[n:]
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HTTPTracing middleware that starts a server span for each request, the trace context
+// is extracted from the W3C traceparent and tracestate headers
+func HTTPTracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			route := c.Path()
+			if route == "" {
+				route = r.URL.Path
+			}
+
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := otel.Tracer(instrumentationName).Start(ctx, fmt.Sprintf("%s %s", r.Method, route),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("client.address", c.RealIP()),
+				))
+			defer span.End()
+			c.SetRequest(r.WithContext(ctx))
+
+			err := next(c)
+
+			statusCode := c.Response().Status
+			if err != nil {
+				statusCode = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					statusCode = he.Code
+				}
+				span.RecordError(err)
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+			if statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(statusCode))
+			}
+			return err
+		}
+	}
+}
+
+// UnaryServerTracing interceptor that starts a server span for each gRPC request, the trace
+// context is extracted from the W3C traceparent and tracestate metadata
+func UnaryServerTracing() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, info.FullMethod,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "grpc"),
+				attribute.String("rpc.method", info.FullMethod),
+			))
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		st := status.Convert(err)
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", st.Code().String()))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, st.Message())
+		}
+		return resp, err
+	}
+}
+
+// metadataCarrier adapts the gRPC metadata to the propagation.TextMapCarrier interface
+type metadataCarrier metadata.MD
+
+// Get implement the interface propagation.TextMapCarrier.Get
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set implement the interface propagation.TextMapCarrier.Set
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+// Keys implement the interface propagation.TextMapCarrier.Keys
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for k := range mc {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// otlpFileClient otlptrace client that appends the spans to a file as OTLP json lines
+type otlpFileClient struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newOTLPFileClient creates a new pointer of otlpFileClient struct
+func newOTLPFileClient(path string) *otlpFileClient {
+	return &otlpFileClient{
+		path: path,
+	}
+}
+
+// Start implement the interface otlptrace.Client.Start
+func (fc *otlpFileClient) Start(ctx context.Context) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	file, err := os.OpenFile(fc.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the traces file: %w", err)
+	}
+	fc.file = file
+	return nil
+}
+
+// Stop implement the interface otlptrace.Client.Stop
+func (fc *otlpFileClient) Stop(ctx context.Context) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.file == nil {
+		return nil
+	}
+	err := fc.file.Close()
+	fc.file = nil
+	return err
+}
+
+// UploadTraces implement the interface otlptrace.Client.UploadTraces
+func (fc *otlpFileClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	line, err := protojson.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: protoSpans})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the spans: %w", err)
+	}
+	line, err = hexEncodeIDs(line)
+	if err != nil {
+		return fmt.Errorf("failed to encode the span ids: %w", err)
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.file == nil {
+		return fmt.Errorf("the traces file %s is not open", fc.path)
+	}
+	_, err = fc.file.Write(append(line, '\n'))
+	return err
+}
+
+// idKeys variable to identify the json keys of the trace and span ids
+var idKeys = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// hexEncodeIDs replaces the base64 trace and span ids written by protojson with the
+// hex encoding required by the OTLP json format
+func hexEncodeIDs(line []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(line, &doc); err != nil {
+		return nil, err
+	}
+	if err := walkIDs(doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// walkIDs walks a json document hex encoding the trace and span ids
+func walkIDs(node interface{}) error {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			if s, ok := v.(string); ok && idKeys[k] {
+				id, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return err
+				}
+				n[k] = hex.EncodeToString(id)
+				continue
+			}
+			if err := walkIDs(v); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, v := range n {
+			if err := walkIDs(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"credit-line/pkg/env"
+)
+
+const (
+	// NoneExporter identify the configuration without exporter
+	NoneExporter = "none"
+	// StdoutExporter identify the exporter that writes the spans in the standard output
+	StdoutExporter = "stdout"
+	// OTLPFileExporter identify the exporter that writes the spans as OTLP json lines in a file
+	OTLPFileExporter = "otlp-file"
+
+	// instrumentationName name of the instrumentation of the application
+	instrumentationName = "credit-line"
+)
+
+// ShutdownFunc function that flushes and stops the tracer provider
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup configures the global tracer provider with the configured exporter and the W3C
+// trace context propagator, it retrieves the function to flush the spans on shutdown
+func Setup(cfg *env.Tracing) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case NoneExporter, "":
+		return func(context.Context) error { return nil }, nil
+	case StdoutExporter:
+		exporter, err = stdouttrace.New()
+	case OTLPFileExporter:
+		exporter, err = otlptrace.New(context.Background(), newOTLPFileClient(cfg.FilePath))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s tracing exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Start starts a span with the tracer of the application
+func Start(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// AmountBucket retrieves the bucket of an amount, the amounts are bucketed to avoid
+// exporting the exact financial data of the applicants
+func AmountBucket(amount float64) string {
+	switch {
+	case amount <= 0:
+		return "0"
+	case amount < 1000:
+		return "0-1k"
+	case amount < 10000:
+		return "1k-10k"
+	case amount < 100000:
+		return "10k-100k"
+	case amount < 1000000:
+		return "100k-1m"
+	default:
+		return "1m+"
+	}
+}
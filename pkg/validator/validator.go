@@ -2,8 +2,18 @@ package validator
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+
+	"credit-line/pkg/applicant"
+	"credit-line/pkg/i18n"
 )
 
 // validatorHandler struct for the custom request validator handler
@@ -11,19 +21,61 @@ type validatorHandler struct {
 	validator *validator.Validate
 }
 
-// creditLineFields variable to identify the product attribute with the json tag
-var creditLineFields = map[string]string{
-	"FoundingType":        "foundingType",
-	"CashBalance":         "cashBalance",
-	"MonthlyRevenue":      "monthlyRevenue",
-	"RequestedCreditLine": "requestedCreditLine",
-	"RequestedDate":       "requestedDate",
+// FieldViolation struct that represents a field that failed the validation
+type FieldViolation struct {
+	Field   string
+	Rule    string
+	Value   interface{}
+	Message string
+}
+
+// overridingTranslator translator that replaces the messages that already exist, it allows
+// to register the default translations in more than one validator
+type overridingTranslator struct {
+	ut.Translator
 }
 
-// New creates a new instance of validatorHandler struct
-func New(validator *validator.Validate) *validatorHandler {
+// defaultTranslations variable with the validator translations registers of each locale
+var defaultTranslations = map[string]func(v *validator.Validate, trans ut.Translator) error{
+	i18n.English: en_translations.RegisterDefaultTranslations,
+	i18n.Spanish: es_translations.RegisterDefaultTranslations,
+}
+
+// registerMu serializes the registration of the translations, the translators of each locale are shared by the
+// validators and the i18n catalogs
+var registerMu sync.Mutex
+
+// validationTranslators variable with the translators of the validation messages of each locale, the messages
+// are registered again by each New so more than one validator can be localized
+var validationTranslators = map[string]ut.Translator{
+	i18n.English: overridingTranslator{i18n.RetrieveTranslatorByLocale(i18n.English)},
+	i18n.Spanish: overridingTranslator{i18n.RetrieveTranslatorByLocale(i18n.Spanish)},
+}
+
+// New creates a new instance of validatorHandler struct with the localized messages, it can be called more
+// than once in the same process
+func New(v *validator.Validate) *validatorHandler {
+	v.RegisterTagNameFunc(retrieveJSONName)
+	if err := v.RegisterValidation("rfc", validateTaxID); err != nil {
+		panic(err)
+	}
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	for locale, register := range defaultTranslations {
+		trans := validationTranslators[locale]
+		if err := register(v, trans); err != nil {
+			panic(err)
+		}
+		if err := v.RegisterTranslation("required", trans, registerNoop, translateRequired); err != nil {
+			panic(err)
+		}
+		if err := v.RegisterTranslation("rfc", trans, registerNoop, translateTaxID); err != nil {
+			panic(err)
+		}
+	}
+
 	return &validatorHandler{
-		validator: validator,
+		validator: v,
 	}
 }
 
@@ -33,14 +85,97 @@ func (vh *validatorHandler) Validate(i interface{}) error {
 }
 
 // RetrieveValidationErrorMessage retrieves a custom error message when the validaton fails
-func RetrieveValidationErrorMessage(err error) string {
+func RetrieveValidationErrorMessage(err error, trans ut.Translator) string {
 	fields := make([]string, 0, 5)
 	if err, ok := err.(validator.ValidationErrors); ok {
 		for _, v := range err {
-			fields = append(fields, fmt.Sprint(creditLineFields[v.Field()], ","))
+			fields = append(fields, fmt.Sprint(v.Field(), ","))
 		}
 		lastField := fields[len(fields)-1]
 		fields[len(fields)-1] = lastField[:len(lastField)-1]
 	}
-	return fmt.Sprintf("malformed request, please check the following parameters in the request: %v", fields)
+	return i18n.Translate(trans, i18n.MalformedRequestKey, fmt.Sprint(fields))
+}
+
+// RetrieveFieldViolations retrieves the detail of each field that failed the validation
+func RetrieveFieldViolations(err error, trans ut.Translator) []FieldViolation {
+	verr, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil
+	}
+
+	violations := make([]FieldViolation, 0, len(verr))
+	for _, v := range verr {
+		violations = append(violations, FieldViolation{
+			Field:   retrieveJSONPath(v),
+			Rule:    v.Tag(),
+			Value:   v.Value(),
+			Message: v.Translate(retrieveValidationTranslator(trans)),
+		})
+	}
+	return violations
+}
+
+// retrieveValidationTranslator retrieves the translator of the validation messages of a locale
+func retrieveValidationTranslator(trans ut.Translator) ut.Translator {
+	if vt, ok := validationTranslators[trans.Locale()]; ok {
+		return vt
+	}
+	return validationTranslators[i18n.English]
+}
+
+// retrieveJSONName retrieves the json tag name of a struct field
+func retrieveJSONName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// retrieveJSONPath retrieves the json path of a field error without the root struct
+func retrieveJSONPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// registerNoop keeps the translation registered in the i18n catalogs
+func registerNoop(ut.Translator) error { return nil }
+
+// translateRequired translates the error of a required field
+func translateRequired(trans ut.Translator, fe validator.FieldError) string {
+	return i18n.Translate(trans, i18n.RequiredFieldKey, retrieveJSONPath(fe))
+}
+
+// validateTaxID validates that a field is a valid RFC, the spaces, dashes and dots are ignored
+func validateTaxID(fl validator.FieldLevel) bool {
+	return applicant.ValidTaxID(fl.Field().String())
+}
+
+// translateTaxID translates the error of a field that is not a valid RFC
+func translateTaxID(trans ut.Translator, fe validator.FieldError) string {
+	return i18n.Translate(trans, i18n.InvalidTaxIDKey, retrieveJSONPath(fe))
+}
+
+// Add implement the interface ut.Translator.Add replacing the existing message
+func (ot overridingTranslator) Add(key interface{}, text string, override bool) error {
+	return ot.Translator.Add(key, text, true)
+}
+
+// AddCardinal implement the interface ut.Translator.AddCardinal replacing the existing message
+func (ot overridingTranslator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ot.Translator.AddCardinal(key, text, rule, true)
+}
+
+// AddOrdinal implement the interface ut.Translator.AddOrdinal replacing the existing message
+func (ot overridingTranslator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ot.Translator.AddOrdinal(key, text, rule, true)
+}
+
+// AddRange implement the interface ut.Translator.AddRange replacing the existing message
+func (ot overridingTranslator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ot.Translator.AddRange(key, text, rule, true)
 }
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"credit-line/internal/model"
+)
+
+const (
+	// EventHeader header with the type of the event
+	EventHeader = "X-Webhook-Event"
+	// EventIDHeader header with the id of the event, it is the same in the redeliveries and can be used to dedupe
+	EventIDHeader = "X-Webhook-Event-ID"
+	// DeliveryHeader header with the id of the delivery
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// Sender struct that posts the signed events to the subscribers
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a new pointer of Sender struct, each post is cancelled after timeout
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send posts the payload of a delivery signed with the secret of the subscription, it retrieves the status
+// code of the response and an error when the subscriber does not respond a 2xx status code
+func (s *Sender) Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(EventIDHeader, delivery.EventID)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), payload))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post the event, %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("the subscriber responded %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader header with the timestamp and the HMAC-SHA256 signature of the payload
+const SignatureHeader = "X-Webhook-Signature"
+
+// ErrInvalidSignature is returned when the signature header does not match the payload
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign signs a payload with the secret of a subscription, the signed content is the unix timestamp and
+// the payload joined with a dot; it retrieves the value of the signature header: t=<timestamp>,v1=<hex>
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeSignature(secret, ts, payload))
+}
+
+// Verify checks the signature header of a payload, the signature is rejected when its timestamp differs
+// from now in more than tolerance
+func Verify(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var ts, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	if diff := now.Sub(time.Unix(unix, 0)); diff > tolerance || diff < -tolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(signature), []byte(computeSignature(secret, ts, payload))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// computeSignature computes the hex HMAC-SHA256 of the timestamp and the payload
+func computeSignature(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+
+	"credit-line/internal/model"
+)
+
+// Store struct with the webhook subscriptions and the log of the last deliveries
+type Store struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*model.WebhookSubscription
+	deliveries    map[string]*model.WebhookDelivery
+	payloads      map[string][]byte
+	order         []string
+	size          uint
+}
+
+// NewStore creates a new pointer of Store struct, only the last size deliveries are kept in the log
+func NewStore(size uint) *Store {
+	return &Store{
+		subscriptions: make(map[string]*model.WebhookSubscription),
+		deliveries:    make(map[string]*model.WebhookDelivery),
+		payloads:      make(map[string][]byte),
+		size:          size,
+	}
+}
+
+// SaveSubscription stores a copy of a subscription
+func (s *Store) SaveSubscription(subscription *model.WebhookSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *subscription
+	stored.Events = append([]model.EventType(nil), subscription.Events...)
+	s.subscriptions[subscription.ID] = &stored
+}
+
+// DeleteSubscription removes a subscription, false when the subscription does not exist
+func (s *Store) DeleteSubscription(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[id]; !ok {
+		return false
+	}
+	delete(s.subscriptions, id)
+	return true
+}
+
+// Subscription retrieves a copy of a subscription, false when the subscription does not exist
+func (s *Store) Subscription(id string) (*model.WebhookSubscription, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return nil, false
+	}
+	stored := *subscription
+	return &stored, true
+}
+
+// Subscriptions retrieves a copy of all the subscriptions from the oldest to the newest
+func (s *Store) Subscriptions() []*model.WebhookSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscriptions := make([]*model.WebhookSubscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		stored := *subscription
+		subscriptions = append(subscriptions, &stored)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions
+}
+
+// AddDelivery stores a copy of a new delivery with its payload, the oldest delivery is removed when the log is full
+func (s *Store) AddDelivery(delivery *model.WebhookDelivery, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *delivery
+	s.deliveries[delivery.ID] = &stored
+	s.payloads[delivery.ID] = payload
+	s.order = append(s.order, delivery.ID)
+	for uint(len(s.order)) > s.size {
+		delete(s.deliveries, s.order[0])
+		delete(s.payloads, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// UpdateDelivery stores a copy of a delivery of the log, it is ignored when the delivery was removed from the log
+func (s *Store) UpdateDelivery(delivery *model.WebhookDelivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deliveries[delivery.ID]; !ok {
+		return
+	}
+	stored := *delivery
+	s.deliveries[delivery.ID] = &stored
+}
+
+// Delivery retrieves a copy of a delivery and its payload, false when the delivery is not in the log
+func (s *Store) Delivery(id string) (*model.WebhookDelivery, []byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return nil, nil, false
+	}
+	stored := *delivery
+	return &stored, s.payloads[id], true
+}
+
+// Deliveries retrieves a copy of the deliveries of a subscription from the newest to the oldest
+func (s *Store) Deliveries(subscriptionID string) []*model.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for i := len(s.order) - 1; i >= 0; i-- {
+		if delivery := s.deliveries[s.order[i]]; delivery.SubscriptionID == subscriptionID {
+			stored := *delivery
+			deliveries = append(deliveries, &stored)
+		}
+	}
+	return deliveries
+}